@@ -0,0 +1,161 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/notifier"
+	"gorm.io/gorm"
+)
+
+// NotificationTypeBudgetAlert adalah Notification.Type untuk alert budget
+const NotificationTypeBudgetAlert = "budget_alert"
+
+// EvaluateAsync menjalankan Evaluate di background agar tidak memperlambat
+// response handler yang membuat atau mengubah transaction
+func EvaluateAsync(userID, categoryID uint, at time.Time) {
+	go func() {
+		if err := Evaluate(context.Background(), userID, categoryID, at); err != nil {
+			log.Printf("Failed to evaluate budget alerts for user %d category %d: %v", userID, categoryID, err)
+		}
+	}()
+}
+
+// Evaluate mengecek spending category pada periode budget yang memuat `at`
+// terhadap budget-nya. Event budget.exceeded dikirim sekali per periode saat
+// spending melewati budget. Alert rule yang threshold-nya tercapai dan belum
+// pernah terpicu periode ini akan membuat Notification in-app dan dikirim
+// lewat channel rule.
+func Evaluate(ctx context.Context, userID, categoryID uint, at time.Time) error {
+	var rules []models.AlertRule
+	if err := database.DB.Where("user_id = ? AND category_id = ? AND active = ?", userID, categoryID, true).Order("threshold ASC").Find(&rules).Error; err != nil {
+		return err
+	}
+
+	// Periode budget yang memuat `at`, mengikuti tanggal awal periode dan
+	// zona waktu user
+	period := models.GetUserCalendar(userID).PeriodOf(at)
+	year, month := period.Year, period.Month
+
+	// Hanya budget periode tersebut yang dipakai. Periode tanpa budget tidak
+	// dievaluasi agar alert tidak memakai batas dari bulan lain.
+	var budgets []models.Budget
+	if err := database.DB.Where("category_id = ? AND user_id = ? AND year = ? AND month = ?", categoryID, userID, year, month).Order("id DESC").Limit(1).Find(&budgets).Error; err != nil {
+		return err
+	}
+	if len(budgets) == 0 {
+		return nil
+	}
+	budget := budgets[0]
+	if budget.Amount <= 0 {
+		return nil
+	}
+
+	spent, err := models.SumInBaseCurrency(models.NewRateBook(userID), models.SpendingMeasure, func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND category_id = ? AND transaction_date >= ? AND transaction_date < ?", userID, categoryID, period.Start.UTC(), period.End.UTC())
+	})
+	if err != nil {
+		return err
+	}
+
+	// Refund bisa membuat spending negatif, anggap 0%
+	percentage := uint(0)
+	if spent > 0 {
+		percentage = uint(spent * 100 / budget.Amount)
+	}
+
+	var category models.Category
+	database.DB.First(&category, categoryID)
+
+	now := time.Now()
+	if spent > budget.Amount && markFired(0, categoryID, year, month, spent, budget.Amount, now) {
+		events.Publish(userID, events.BudgetExceeded, map[string]interface{}{
+			"category_id":   categoryID,
+			"category_name": category.Name,
+			"year":          year,
+			"month":         month,
+			"budget":        budget.Amount,
+			"spent":         spent,
+			"percentage":    percentage,
+		})
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if percentage < rule.Threshold || !markFired(rule.ID, categoryID, year, month, spent, budget.Amount, now) {
+			continue
+		}
+
+		msg := notifier.Message{
+			UserID:  userID,
+			Email:   user.Email,
+			Name:    user.Name,
+			Subject: fmt.Sprintf("Budget alert: %s reached %d%%", category.Name, rule.Threshold),
+			Body:    fmt.Sprintf("Spending for %s in %02d/%d is %s of %s %s (%d%% of budget).", category.Name, month, year, spent.Format(user.BaseCurrency), budget.Amount.Format(user.BaseCurrency), user.BaseCurrency, percentage),
+		}
+
+		if err := database.DB.Create(&models.Notification{
+			UserID: userID,
+			Type:   NotificationTypeBudgetAlert,
+			Title:  msg.Subject,
+			Body:   msg.Body,
+		}).Error; err != nil {
+			log.Printf("Failed to store notification for rule %d: %v", rule.ID, err)
+		}
+
+		if err := deliver(ctx, rule, msg); err != nil {
+			log.Printf("Failed to deliver budget alert for rule %d via %s: %v", rule.ID, rule.Channel, err)
+		}
+	}
+
+	return nil
+}
+
+// markFired mencatat rule yang terpicu pada bulan tersebut. Mengembalikan
+// false jika rule sudah pernah terpicu, unique index pada BudgetAlertLog
+// mencegah alert ganda jika dua transaction dievaluasi bersamaan.
+func markFired(ruleID, categoryID, year, month uint, spent, budget money.Amount, now time.Time) bool {
+	var fired int64
+	database.DB.Model(&models.BudgetAlertLog{}).Where("rule_id = ? AND category_id = ? AND year = ? AND month = ?", ruleID, categoryID, year, month).Count(&fired)
+	if fired > 0 {
+		return false
+	}
+
+	return database.DB.Create(&models.BudgetAlertLog{
+		RuleID:     ruleID,
+		CategoryID: categoryID,
+		Year:       year,
+		Month:      month,
+		Spent:      spent,
+		Budget:     budget,
+		FiredAt:    now,
+	}).Error == nil
+}
+
+// deliver mengirim alert lewat channel tambahan rule (webhook atau email)
+func deliver(ctx context.Context, rule models.AlertRule, msg notifier.Message) error {
+	switch rule.Channel {
+	case models.AlertChannelWebhook:
+		return notifier.NewUserWebhookNotifier(rule.Target).Notify(ctx, msg)
+	case models.AlertChannelEmail:
+		if rule.Target != "" {
+			msg.Email = rule.Target
+		}
+		return notifier.NewEmailNotifierFromEnv().Notify(ctx, msg)
+	}
+	return nil
+}
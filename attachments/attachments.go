@@ -0,0 +1,210 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/storage"
+)
+
+// DefaultMaxSize adalah ukuran maksimal file jika ATTACHMENT_MAX_SIZE tidak diisi
+const DefaultMaxSize int64 = 10 << 20
+
+var (
+	// ErrTooLarge dikembalikan jika file melebihi MaxSize
+	ErrTooLarge = errors.New("file is too large")
+
+	// ErrUnsupportedType dikembalikan jika isi file bukan gambar atau PDF
+	ErrUnsupportedType = errors.New("file type is not supported")
+)
+
+// allowedTypes memetakan content type hasil sniffing ke extension file
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// Storage adalah storage yang dipakai untuk menyimpan attachment, diisi dari
+// storage.FromEnv() saat startup
+var Storage storage.Storage = &storage.LocalStorage{Root: "./uploads"}
+
+// MaxSize membaca batas ukuran file dari env ATTACHMENT_MAX_SIZE (byte)
+func MaxSize() int64 {
+	if value, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_SIZE"), 10, 64); err == nil && value > 0 {
+		return value
+	}
+	return DefaultMaxSize
+}
+
+// SniffContentType menentukan content type dari isi file (bukan dari nama
+// file atau header dari client) dan mengecek apakah jenisnya diizinkan
+func SniffContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if _, ok := allowedTypes[contentType]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	return contentType, nil
+}
+
+// Save menyimpan file upload sebagai attachment transaction. Thumbnail dibuat
+// untuk gambar; kegagalan membuat thumbnail tidak menggagalkan upload.
+func Save(ctx context.Context, userID, transactionID uint, fileHeader *multipart.FileHeader) (models.Attachment, error) {
+	maxSize := MaxSize()
+	if fileHeader.Size > maxSize {
+		return models.Attachment{}, ErrTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	if int64(len(data)) > maxSize {
+		return models.Attachment{}, ErrTooLarge
+	}
+
+	contentType, err := SniffContentType(data)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	checksum := sha256.Sum256(data)
+
+	attachment := models.Attachment{
+		UserID:        userID,
+		TransactionID: transactionID,
+		FileName:      cleanFileName(fileHeader.Filename, allowedTypes[contentType]),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		Checksum:      hex.EncodeToString(checksum[:]),
+		StorageKey:    fmt.Sprintf("attachments/%d/%s%s", userID, name, allowedTypes[contentType]),
+	}
+
+	if err := Storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return models.Attachment{}, err
+	}
+
+	if thumbnailTypes[contentType] {
+		if thumbnail, err := Thumbnail(data); err != nil {
+			log.Printf("Warning: thumbnail for %s failed: %v", attachment.StorageKey, err)
+		} else {
+			key := fmt.Sprintf("attachments/%d/%s_thumb.jpg", userID, name)
+			if err := Storage.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+				log.Printf("Warning: storing thumbnail %s failed: %v", key, err)
+			} else {
+				attachment.ThumbnailKey = key
+			}
+		}
+	}
+
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		removeObjects(ctx, attachment)
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// Open membuka isi attachment, atau thumbnail-nya jika thumbnail true
+func Open(ctx context.Context, attachment models.Attachment, thumbnail bool) (io.ReadCloser, error) {
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, storage.ErrNotFound
+		}
+		return Storage.Get(ctx, attachment.ThumbnailKey)
+	}
+	return Storage.Get(ctx, attachment.StorageKey)
+}
+
+// Delete menghapus attachment beserta file-nya di storage
+func Delete(ctx context.Context, attachment models.Attachment) error {
+	if err := database.DB.Delete(&attachment).Error; err != nil {
+		return err
+	}
+	removeObjects(ctx, attachment)
+	return nil
+}
+
+// DeleteForTransaction menghapus semua attachment milik transaction
+func DeleteForTransaction(ctx context.Context, userID, transactionID uint) error {
+	var list []models.Attachment
+	if err := database.DB.Where("user_id = ? AND transaction_id = ?", userID, transactionID).Find(&list).Error; err != nil {
+		return err
+	}
+	for _, attachment := range list {
+		if err := Delete(ctx, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeObjects menghapus file di storage, error hanya di-log karena baris
+// database sudah tidak mereferensikan file tersebut
+func removeObjects(ctx context.Context, attachment models.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := Storage.Delete(ctx, key); err != nil {
+			log.Printf("Warning: deleting %s failed: %v", key, err)
+		}
+	}
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// cleanFileName membuang path dan karakter kontrol dari nama file client,
+// dan memakai nama default jika kosong
+func cleanFileName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "attachment" + ext
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
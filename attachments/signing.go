@@ -0,0 +1,90 @@
+package attachments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/storage"
+)
+
+// DefaultURLTTL adalah masa berlaku URL download jika ATTACHMENT_URL_TTL
+// tidak diisi
+const DefaultURLTTL = 15 * time.Minute
+
+// VariantThumbnail adalah nilai query `variant` untuk thumbnail
+const VariantThumbnail = "thumbnail"
+
+// URLTTL membaca masa berlaku URL dari env ATTACHMENT_URL_TTL (contoh: 30m)
+func URLTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("ATTACHMENT_URL_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return DefaultURLTTL
+}
+
+// urlSecret memakai ATTACHMENT_URL_SECRET, fallback ke JWT_SECRET
+func urlSecret() string {
+	if secret := os.Getenv("ATTACHMENT_URL_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET")
+}
+
+// sign menghitung HMAC-SHA256 dari "<id>.<variant>.<expires>"
+func sign(id uint, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(urlSecret()))
+	fmt.Fprintf(mac, "%d.%s.%d", id, variant, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedPath membuat path download yang bisa dibuka tanpa header
+// Authorization (misalnya dari tag <img>) sampai expires
+func SignedPath(id uint, variant string, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", sign(id, variant, expires.Unix()))
+	if variant != "" {
+		query.Set("variant", variant)
+	}
+	return fmt.Sprintf("/api/v1/files/%d?%s", id, query.Encode())
+}
+
+// VerifySignature mengecek signature dan masa berlaku URL download
+func VerifySignature(id uint, variant, expires, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(sign(id, variant, unix)), []byte(signature))
+}
+
+// Public mengubah attachment ke PublicAttachment beserta URL download
+// sementara. Storage yang mendukung presigned URL (S3) dipakai langsung.
+func Public(attachment models.Attachment, now time.Time) models.PublicAttachment {
+	ttl := URLTTL()
+	expires := now.Add(ttl)
+
+	public := attachment.ToPublicAttachment()
+	public.URLExpiresAt = &expires
+	public.URL = downloadURL(attachment.ID, attachment.StorageKey, "", expires, ttl)
+	if attachment.ThumbnailKey != "" {
+		public.ThumbnailURL = downloadURL(attachment.ID, attachment.ThumbnailKey, VariantThumbnail, expires, ttl)
+	}
+	return public
+}
+
+func downloadURL(id uint, key, variant string, expires time.Time, ttl time.Duration) string {
+	if presigner, ok := Storage.(storage.Presigner); ok {
+		if signed, err := presigner.PresignGet(key, ttl); err == nil {
+			return signed
+		}
+	}
+	return SignedPath(id, variant, expires)
+}
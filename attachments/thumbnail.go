@@ -0,0 +1,97 @@
+package attachments
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Decoder untuk image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// ThumbnailSize adalah sisi terpanjang thumbnail dalam pixel
+	ThumbnailSize = 320
+
+	// maxImagePixels membatasi ukuran gambar yang di-decode agar file kecil
+	// dengan dimensi besar (decompression bomb) tidak menghabiskan memory
+	maxImagePixels = 50_000_000
+)
+
+// thumbnailTypes adalah content type yang bisa dibuatkan thumbnail
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Thumbnail membuat thumbnail JPEG dengan sisi terpanjang ThumbnailSize.
+// Gambar yang lebih kecil tidak diperbesar.
+func Thumbnail(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("image dimensions %dx%d not supported", config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, resize(src, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// resize mengecilkan gambar dengan rata-rata area (box filter) sehingga hasil
+// tetap halus tanpa dependency image processing tambahan
+func resize(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			dstWidth, dstHeight = maxSide, max(1, height*maxSide/width)
+		} else {
+			dstWidth, dstHeight = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			// JPEG tidak punya alpha, area transparan dijadikan putih
+			alpha := a / n
+			dst.Set(x, y, color.RGBA{
+				R: uint8((r/n + (0xffff - alpha)) >> 8),
+				G: uint8((g/n + (0xffff - alpha)) >> 8),
+				B: uint8((b/n + (0xffff - alpha)) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
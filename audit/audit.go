@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+)
+
+// Actor adalah pelaku perubahan data, diambil dari request
+type Actor struct {
+	UserID    uint
+	IP        string
+	UserAgent string
+}
+
+type actorKey struct{}
+
+// WithActor menyimpan actor di context. Query GORM yang memakai context ini
+// (db.WithContext(ctx)) akan tercatat atas nama actor tersebut.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext mengambil actor dari context
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Entities memetakan tabel yang diaudit ke nama entity di audit log
+var Entities = map[string]string{
+	"users":        "user",
+	"categories":   "category",
+	"budgets":      "budget",
+	"transactions": "transaction",
+}
+
+// TableForEntity mengembalikan nama tabel untuk nama entity
+func TableForEntity(entity string) (string, bool) {
+	for table, name := range Entities {
+		if name == entity {
+			return table, true
+		}
+	}
+	return "", false
+}
+
+// redactedColumns tidak pernah disimpan isinya di audit log
+var redactedColumns = map[string]bool{
+	"password": true,
+}
+
+// ignoredColumns tidak dianggap sebagai perubahan
+var ignoredColumns = map[string]bool{
+	"updated_at": true,
+}
@@ -0,0 +1,468 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// maxSnapshotRows membatasi jumlah baris yang dicatat per statement
+const maxSnapshotRows = 1000
+
+// snapshotKey adalah key InstanceSet untuk snapshot sebelum perubahan
+const snapshotKey = "audit:before"
+
+// upsertKey adalah key InstanceSet untuk baris yang sudah ada sebelum upsert
+const upsertKey = "audit:upsert"
+
+type row = map[string]interface{}
+
+// Register memasang callback GORM yang mencatat setiap create, update dan
+// delete pada tabel di Entities ke audit_logs. Update dan delete membaca
+// baris yang terdampak sebelum dan sesudah statement, sehingga update massal
+// (Where(...).Update(...)) juga tercatat per baris. Audit log ditulis di
+// koneksi yang sama, sehingga ikut rollback jika transaction gagal. Create
+// dengan ON CONFLICT yang mengenai baris lama dicatat sebagai update (atau
+// restore), dan tidak dicatat jika tidak ada yang berubah.
+func Register(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("audit:before_create", beforeCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", beforeChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", afterChange(models.AuditActionUpdate)); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", beforeChange); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", afterChange(models.AuditActionDelete))
+}
+
+// audited mengecek apakah statement mengenai tabel yang diaudit
+func audited(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil || db.Statement.Schema.Table == "" {
+		return "", false
+	}
+	entity, ok := Entities[db.Statement.Schema.Table]
+	return entity, ok
+}
+
+// beforeCreate menyimpan baris yang sudah ada untuk create dengan ON CONFLICT
+func beforeCreate(db *gorm.DB) {
+	if _, ok := audited(db); !ok || db.Error != nil {
+		return
+	}
+	columns, ok := conflictColumns(db.Statement)
+	if !ok {
+		return
+	}
+	rows := createdRows(db)
+	if len(rows) == 0 {
+		return
+	}
+
+	// Baris di trash juga bisa terkena konflik
+	var before []row
+	if err := snapshotQuery(db).Unscoped().Clauses(conflictWhere(columns, rows)).Find(&before).Error; err != nil {
+		log.Printf("Warning: audit snapshot of %s failed: %v", db.Statement.Schema.Table, err)
+		return
+	}
+	db.InstanceSet(upsertKey, before)
+}
+
+func afterCreate(db *gorm.DB) {
+	entity, ok := audited(db)
+	if !ok || db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	if value, ok := db.InstanceGet(upsertKey); ok {
+		before, _ := value.([]row)
+		afterUpsert(db, entity, before)
+		return
+	}
+
+	for _, after := range createdRows(db) {
+		write(db, entity, models.AuditActionCreate, nil, after)
+	}
+}
+
+// afterUpsert membaca ulang baris hasil upsert berdasarkan kolom konflik,
+// karena ID di model tidak bisa dipercaya saat konflik di MySQL. Baris yang
+// sebelumnya belum ada dicatat sebagai create, sisanya sebagai update.
+func afterUpsert(db *gorm.DB, entity string, before []row) {
+	columns, _ := conflictColumns(db.Statement)
+
+	var after []row
+	if err := snapshotQuery(db).Unscoped().Clauses(conflictWhere(columns, createdRows(db))).Find(&after).Error; err != nil {
+		log.Printf("Warning: audit snapshot of %s failed: %v", db.Statement.Schema.Table, err)
+		return
+	}
+
+	beforeByKey := make(map[string]row, len(before))
+	for _, r := range before {
+		beforeByKey[conflictKey(columns, r)] = r
+	}
+	for _, current := range after {
+		old, existed := beforeByKey[conflictKey(columns, current)]
+		if !existed {
+			write(db, entity, models.AuditActionCreate, nil, current)
+			continue
+		}
+		write(db, entity, changeAction(db.Statement.Schema, models.AuditActionUpdate, old, current), old, current)
+	}
+}
+
+// createdRows membaca baris yang dibuat statement create dari model
+func createdRows(db *gorm.DB) []row {
+	var rows []row
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Struct:
+		rows = append(rows, structRow(db, value))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, structRow(db, reflect.Indirect(value.Index(i))))
+		}
+	}
+	return rows
+}
+
+// conflictColumns mengembalikan kolom ON CONFLICT statement, jika ada
+func conflictColumns(stmt *gorm.Statement) ([]string, bool) {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok {
+		return nil, false
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok || len(onConflict.Columns) == 0 {
+		return nil, false
+	}
+	columns := make([]string, 0, len(onConflict.Columns))
+	for _, column := range onConflict.Columns {
+		columns = append(columns, column.Name)
+	}
+	return columns, true
+}
+
+// conflictWhere membuat kondisi untuk membaca baris dengan nilai kolom
+// konflik yang sama dengan rows
+func conflictWhere(columns []string, rows []row) clause.Where {
+	exprs := make([]clause.Expression, 0, len(rows))
+	for _, r := range rows {
+		eqs := make([]clause.Expression, 0, len(columns))
+		for _, column := range columns {
+			eqs = append(eqs, clause.Eq{Column: clause.Column{Name: column}, Value: r[column]})
+		}
+		exprs = append(exprs, clause.And(eqs...))
+	}
+	return clause.Where{Exprs: []clause.Expression{clause.Or(exprs...)}}
+}
+
+// conflictKey menggabungkan nilai kolom konflik sebuah baris. Nilai
+// di-lowercase karena collation MySQL membandingkan string tanpa case.
+func conflictKey(columns []string, r row) string {
+	var key strings.Builder
+	for _, column := range columns {
+		v := r[column]
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		key.WriteString(strings.ToLower(fmt.Sprint(v)))
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+// beforeChange menyimpan baris yang akan terdampak update/delete
+func beforeChange(db *gorm.DB) {
+	if _, ok := audited(db); !ok || db.Error != nil {
+		return
+	}
+
+	conditions := conditions(db.Statement)
+	if len(conditions) == 0 {
+		return
+	}
+
+	// Model dipakai agar kondisi primary key dan soft delete ikut di-resolve
+	query := snapshotQuery(db).Clauses(clause.Where{Exprs: conditions})
+	if db.Statement.Unscoped {
+		query = query.Unscoped()
+	}
+
+	var before []row
+	if err := query.Limit(maxSnapshotRows + 1).Find(&before).Error; err != nil {
+		log.Printf("Warning: audit snapshot of %s failed: %v", db.Statement.Schema.Table, err)
+		return
+	}
+	if len(before) > maxSnapshotRows {
+		log.Printf("Warning: audit of %s limited to %d rows", db.Statement.Schema.Table, maxSnapshotRows)
+		before = before[:maxSnapshotRows]
+	}
+	db.InstanceSet(snapshotKey, before)
+}
+
+// afterChange membandingkan snapshot dengan kondisi baris setelah statement
+func afterChange(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		entity, ok := audited(db)
+		if !ok || db.Error != nil || db.Statement.RowsAffected == 0 {
+			return
+		}
+		value, ok := db.InstanceGet(snapshotKey)
+		if !ok {
+			return
+		}
+		before, _ := value.([]row)
+		if len(before) == 0 {
+			return
+		}
+
+		primary := db.Statement.Schema.PrioritizedPrimaryField
+		if primary == nil {
+			return
+		}
+		ids := make([]interface{}, 0, len(before))
+		for _, r := range before {
+			ids = append(ids, r[primary.DBName])
+		}
+
+		var after []row
+		if err := snapshotQuery(db).Unscoped().Where(primary.DBName+" IN ?", ids).Find(&after).Error; err != nil {
+			log.Printf("Warning: audit snapshot of %s failed: %v", db.Statement.Schema.Table, err)
+			return
+		}
+		afterByID := make(map[string]row, len(after))
+		for _, r := range after {
+			afterByID[fmt.Sprint(r[primary.DBName])] = r
+		}
+
+		for _, old := range before {
+			current := afterByID[fmt.Sprint(old[primary.DBName])]
+			write(db, entity, changeAction(db.Statement.Schema, action, old, current), old, current)
+		}
+	}
+}
+
+// changeAction mencatat perubahan deleted_at sebagai delete atau restore
+func changeAction(s *schema.Schema, action string, before, after row) string {
+	softDelete := softDeleteColumn(s)
+	if softDelete == "" || after == nil {
+		return action
+	}
+	wasDeleted, isDeleted := before[softDelete] != nil, after[softDelete] != nil
+	switch {
+	case wasDeleted && !isDeleted:
+		return models.AuditActionRestore
+	case !wasDeleted && isDeleted:
+		return models.AuditActionDelete
+	}
+	return action
+}
+
+// write menyimpan satu baris audit log. Update tanpa perubahan tidak dicatat.
+func write(db *gorm.DB, entity, action string, before, after row) {
+	changes := diff(before, after)
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	source := after
+	if source == nil {
+		source = before
+	}
+
+	entry := models.AuditLog{
+		Action:     action,
+		EntityType: entity,
+		EntityID:   toUint(source["id"]),
+		UserID:     toUint(source["user_id"]),
+		Before:     encode(before),
+		After:      encode(after),
+		Changes:    encode(changes),
+		CreatedAt:  time.Now(),
+	}
+	if entity == "user" {
+		entry.UserID = entry.EntityID
+	}
+
+	if actor, ok := ActorFromContext(db.Statement.Context); ok {
+		if actor.UserID != 0 {
+			actorID := actor.UserID
+			entry.ActorID = &actorID
+		}
+		entry.IP = actor.IP
+		entry.UserAgent = truncate(actor.UserAgent, 255)
+	}
+
+	if err := session(db).Create(&entry).Error; err != nil {
+		log.Printf("Warning: writing audit log for %s %d failed: %v", entity, entry.EntityID, err)
+	}
+}
+
+// session membuat query baru di koneksi/transaction yang sama tanpa hooks
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context})
+}
+
+// snapshotQuery membuat query untuk membaca baris model statement sebagai map
+func snapshotQuery(db *gorm.DB) *gorm.DB {
+	return session(db).Model(reflect.New(db.Statement.Schema.ModelType).Interface())
+}
+
+// conditions mengambil WHERE statement ditambah primary key dari model,
+// karena GORM baru menambahkan kondisi primary key di dalam callback
+// gorm:update/gorm:delete
+func conditions(stmt *gorm.Statement) []clause.Expression {
+	var exprs []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+
+	value := reflect.Indirect(stmt.ReflectValue)
+	if value.Kind() == reflect.Struct {
+		for _, field := range stmt.Schema.PrimaryFields {
+			if v, zero := field.ValueOf(stmt.Context, value); !zero {
+				exprs = append(exprs, clause.Eq{Column: clause.Column{Name: field.DBName}, Value: v})
+			}
+		}
+	}
+	return exprs
+}
+
+// softDeleteColumn mengembalikan kolom gorm.DeletedAt milik schema, jika ada
+func softDeleteColumn(s *schema.Schema) string {
+	deletedAtType := reflect.TypeOf(gorm.DeletedAt{})
+	for _, field := range s.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field.DBName
+		}
+	}
+	return ""
+}
+
+// structRow membaca nilai field model sebagai row berdasarkan nama kolom
+func structRow(db *gorm.DB, value reflect.Value) row {
+	r := row{}
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		v, _ := field.ValueOf(db.Statement.Context, value)
+		if deletedAt, ok := v.(gorm.DeletedAt); ok {
+			if !deletedAt.Valid {
+				v = nil
+			} else {
+				v = deletedAt.Time
+			}
+		}
+		r[field.DBName] = v
+	}
+	return r
+}
+
+// diff mengembalikan kolom yang berubah dalam bentuk {kolom: {before, after}}
+func diff(before, after row) map[string]interface{} {
+	changes := map[string]interface{}{}
+	columns := map[string]bool{}
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	for column := range columns {
+		if ignoredColumns[column] {
+			continue
+		}
+		old, current := before[column], after[column]
+		if before != nil && after != nil && encode(old) == encode(current) {
+			continue
+		}
+		if redactedColumns[column] {
+			changes[column] = "[redacted]"
+			continue
+		}
+		changes[column] = map[string]interface{}{"before": old, "after": current}
+	}
+	return changes
+}
+
+// encode mengubah nilai ke JSON, kolom yang dirahasiakan disensor
+func encode(value interface{}) string {
+	if r, ok := value.(row); ok {
+		if r == nil {
+			return ""
+		}
+		redacted := make(row, len(r))
+		for column, v := range r {
+			if redactedColumns[column] {
+				v = "[redacted]"
+			}
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			redacted[column] = v
+		}
+		value = redacted
+	}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func toUint(value interface{}) uint {
+	switch v := value.(type) {
+	case uint:
+		return v
+	case uint32:
+		return uint(v)
+	case uint64:
+		return uint(v)
+	case int:
+		return uint(v)
+	case int32:
+		return uint(v)
+	case int64:
+		return uint(v)
+	case []byte:
+		var id uint
+		fmt.Sscan(string(v), &id)
+		return id
+	case string:
+		var id uint
+		fmt.Sscan(v, &id)
+		return id
+	}
+	return 0
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
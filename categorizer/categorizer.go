@@ -0,0 +1,363 @@
+package categorizer
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/models"
+)
+
+// MinAutoAssignConfidence adalah confidence minimum agar saran teratas
+// langsung dipakai sebagai category transaction baru. Di bawah nilai ini
+// transaction masuk ke category "uncategorized" dan saran hanya tersedia
+// lewat endpoint suggest.
+const MinAutoAssignConfidence = 0.6
+
+// Suggestion adalah category yang disarankan beserta tingkat keyakinannya (0-1)
+type Suggestion struct {
+	CategoryID   uint    `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// document adalah kontribusi satu transaction ke model, disimpan agar bisa
+// dikurangi lagi saat transaction dikoreksi atau dihapus
+type document struct {
+	categoryID uint
+	tokens     []string
+}
+
+// Model adalah multinomial naive Bayes per user atas token Remarks dan Type
+type Model struct {
+	mu          sync.RWMutex
+	docs        map[uint]document
+	docCount    map[uint]int
+	tokenCount  map[uint]map[string]int
+	totalTokens map[uint]int
+	vocabulary  map[string]int
+}
+
+func newModel() *Model {
+	return &Model{
+		docs:        map[uint]document{},
+		docCount:    map[uint]int{},
+		tokenCount:  map[uint]map[string]int{},
+		totalTokens: map[uint]int{},
+		vocabulary:  map[string]int{},
+	}
+}
+
+// Tokenize memecah remarks menjadi token lowercase. Angka murni dan token
+// satu huruf dibuang karena biasanya nomor referensi atau noise.
+func Tokenize(remarks, transactionType string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(remarks), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		if len([]rune(field)) < 2 || strings.IndexFunc(field, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	if transactionType != "" {
+		tokens = append(tokens, "type:"+strings.ToLower(transactionType))
+	}
+	return tokens
+}
+
+// Observe menambahkan atau memperbarui transaction di model
+func (m *Model) Observe(transactionID, categoryID uint, remarks, transactionType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.forget(transactionID)
+	if categoryID == 0 {
+		return
+	}
+
+	doc := document{categoryID: categoryID, tokens: Tokenize(remarks, transactionType)}
+	m.docs[transactionID] = doc
+	m.docCount[categoryID]++
+	if m.tokenCount[categoryID] == nil {
+		m.tokenCount[categoryID] = map[string]int{}
+	}
+	for _, token := range doc.tokens {
+		m.tokenCount[categoryID][token]++
+		m.totalTokens[categoryID]++
+		m.vocabulary[token]++
+	}
+}
+
+// Forget menghapus transaction dari model
+func (m *Model) Forget(transactionID uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.forget(transactionID)
+}
+
+func (m *Model) forget(transactionID uint) {
+	doc, ok := m.docs[transactionID]
+	if !ok {
+		return
+	}
+	delete(m.docs, transactionID)
+
+	m.docCount[doc.categoryID]--
+	if m.docCount[doc.categoryID] <= 0 {
+		delete(m.docCount, doc.categoryID)
+	}
+	for _, token := range doc.tokens {
+		m.tokenCount[doc.categoryID][token]--
+		if m.tokenCount[doc.categoryID][token] <= 0 {
+			delete(m.tokenCount[doc.categoryID], token)
+		}
+		m.totalTokens[doc.categoryID]--
+		m.vocabulary[token]--
+		if m.vocabulary[token] <= 0 {
+			delete(m.vocabulary, token)
+		}
+	}
+	if len(m.tokenCount[doc.categoryID]) == 0 {
+		delete(m.tokenCount, doc.categoryID)
+		delete(m.totalTokens, doc.categoryID)
+	}
+}
+
+// Suggest mengembalikan maksimal limit category dengan probabilitas
+// posterior tertinggi. Confidence dinormalisasi sehingga totalnya 1 atas
+// semua category yang dikenal model.
+func (m *Model) Suggest(remarks, transactionType string, limit int) []Suggestion {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := Tokenize(remarks, transactionType)
+	if len(m.docs) == 0 || len(tokens) == 0 {
+		return []Suggestion{}
+	}
+
+	vocabularySize := float64(len(m.vocabulary) + 1)
+	totalDocs := float64(len(m.docs))
+
+	scores := make(map[uint]float64, len(m.docCount))
+	maxScore := math.Inf(-1)
+	for categoryID, count := range m.docCount {
+		score := math.Log(float64(count) / totalDocs)
+		denominator := float64(m.totalTokens[categoryID]) + vocabularySize
+		for _, token := range tokens {
+			score += math.Log((float64(m.tokenCount[categoryID][token]) + 1) / denominator)
+		}
+		scores[categoryID] = score
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+
+	// Softmax atas log-probability agar tidak underflow
+	var sum float64
+	for categoryID, score := range scores {
+		scores[categoryID] = math.Exp(score - maxScore)
+		sum += scores[categoryID]
+	}
+
+	suggestions := make([]Suggestion, 0, len(scores))
+	for categoryID, score := range scores {
+		suggestions = append(suggestions, Suggestion{
+			CategoryID: categoryID,
+			Confidence: math.Round(score/sum*10000) / 10000,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryID < suggestions[j].CategoryID
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// hasType mengecek apakah category pernah dipakai transaction dengan type tersebut
+func (m *Model) hasType(categoryID uint, transactionType string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tokenCount[categoryID]["type:"+strings.ToLower(transactionType)] > 0
+}
+
+// Registry menyimpan model setiap user di memory. Model dilatih dari
+// history transaction saat pertama kali dipakai, lalu diperbarui secara
+// incremental dari event bus.
+type Registry struct {
+	mu     sync.Mutex
+	models map[uint]*Model
+}
+
+// Default adalah registry yang dipakai oleh seluruh aplikasi
+var Default = &Registry{models: map[uint]*Model{}}
+
+// ForUser mengambil model user, melatihnya dari database jika belum ada
+func (r *Registry) ForUser(userID uint) (*Model, error) {
+	r.mu.Lock()
+	model, ok := r.models[userID]
+	r.mu.Unlock()
+	if ok {
+		return model, nil
+	}
+
+	model = newModel()
+	var transactions []models.Transaction
+	err := database.DB.
+		Select("id", "category_id", "type", "remarks").
+		Where("user_id = ? AND category_id > 0", userID).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		model.Observe(transaction.ID, transaction.CategoryID, transaction.Remarks, transaction.Type)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.models[userID]; ok {
+		return existing, nil
+	}
+	r.models[userID] = model
+	return model, nil
+}
+
+// unload membuang model user dari memory
+func (r *Registry) unload(userID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.models, userID)
+}
+
+// loaded mengambil model user hanya jika sudah ada di memory
+func (r *Registry) loaded(userID uint) *Model {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.models[userID]
+}
+
+// Suggest mengembalikan top-N category untuk remarks, lengkap dengan nama
+// category. Category yang sudah dihapus tidak disarankan.
+func (r *Registry) Suggest(userID uint, remarks, transactionType string, limit int) ([]Suggestion, error) {
+	model, err := r.ForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := model.Suggest(remarks, transactionType, 0)
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, suggestion := range candidates {
+		ids = append(ids, suggestion.CategoryID)
+	}
+	var categories []models.Category
+	if err := database.DB.Where("user_id = ? AND id IN ?", userID, ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	suggestions := make([]Suggestion, 0, limit)
+	for _, suggestion := range candidates {
+		name, ok := names[suggestion.CategoryID]
+		if !ok {
+			continue
+		}
+		suggestion.CategoryName = name
+		suggestions = append(suggestions, suggestion)
+		if limit > 0 && len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+// AutoAssign mengembalikan category untuk transaction baru tanpa category,
+// atau 0 jika model belum cukup yakin. Saran teratas hanya dipakai jika
+// confidence-nya mencapai MinAutoAssignConfidence dan category tersebut
+// pernah dipakai transaction dengan type yang sama, sehingga category
+// income tidak dipasang ke expense.
+func (r *Registry) AutoAssign(userID uint, remarks, transactionType string) (uint, error) {
+	if transactionType == "" {
+		return 0, nil
+	}
+
+	suggestions, err := r.Suggest(userID, remarks, transactionType, 1)
+	if err != nil || len(suggestions) == 0 {
+		return 0, err
+	}
+
+	best := suggestions[0]
+	if best.Confidence < MinAutoAssignConfidence {
+		return 0, nil
+	}
+
+	model, err := r.ForUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	if !model.hasType(best.CategoryID, transactionType) {
+		return 0, nil
+	}
+	return best.CategoryID, nil
+}
+
+// Listen memperbarui model user yang sudah dimuat setiap ada transaction
+// dibuat, dikoreksi, dihapus atau di-restore. Model yang belum dimuat akan
+// membaca data terbaru dari database saat pertama kali dipakai.
+func (r *Registry) Listen(bus *events.Bus) {
+	bus.AddListener(func(event events.Event) {
+		switch event.Type {
+		case events.TransactionCreated, events.TransactionUpdated, events.TransactionDeleted, events.TransactionRestored:
+		case events.CategoryDeleted, events.CategoryRestored:
+			// Transaction category bisa ikut dipindah atau dihapus, muat ulang
+			// model dari database saat dipakai berikutnya
+			r.unload(event.UserID)
+			return
+		default:
+			return
+		}
+
+		model := r.loaded(event.UserID)
+		if model == nil {
+			return
+		}
+
+		// Payload event berbeda-beda tipe, ambil field yang dibutuhkan lewat JSON
+		var payload struct {
+			ID         uint   `json:"id"`
+			CategoryID uint   `json:"category_id"`
+			Type       string `json:"type"`
+			Remarks    string `json:"remarks"`
+		}
+		raw, err := json.Marshal(event.Data)
+		if err != nil || json.Unmarshal(raw, &payload) != nil || payload.ID == 0 {
+			return
+		}
+
+		if event.Type == events.TransactionDeleted {
+			model.Forget(payload.ID)
+			return
+		}
+		model.Observe(payload.ID, payload.CategoryID, payload.Remarks, payload.Type)
+	})
+}
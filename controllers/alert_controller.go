@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/safehttp"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AlertRuleRequest struct {
+	CategoryID uint   `json:"category_id" binding:"required"`
+	Threshold  uint   `json:"threshold" binding:"required,min=1,max=1000"`
+	Channel    string `json:"channel" binding:"omitempty,oneof=in_app webhook email"`
+	Target     string `json:"target" binding:"max=255"`
+	Active     *bool  `json:"active"`
+}
+
+type AlertRuleDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type AlertRuleIndexResponse struct {
+	Error   bool               `json:"error"`
+	Message string             `json:"message"`
+	Data    []models.AlertRule `json:"data"`
+}
+
+type AlertRuleFetchResponse struct {
+	Error   bool             `json:"error"`
+	Message string           `json:"message"`
+	Data    models.AlertRule `json:"data"`
+}
+
+// findUserAlertRule mengambil alert rule milik user, menulis response error jika gagal
+func findUserAlertRule(c *gin.Context, userID uint) (models.AlertRule, bool) {
+	var rule models.AlertRule
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Alert Rule ID!",
+		})
+		return rule, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Alert rule not found",
+				"message": "Alert rule no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch alert rule",
+			})
+		}
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// bindAlertRuleRequest memvalidasi request dan mengisi field rule dari request
+func bindAlertRuleRequest(c *gin.Context, userID uint, rule *models.AlertRule) bool {
+	var req AlertRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	if req.Channel == "" {
+		req.Channel = models.AlertChannelInApp
+	}
+	req.Target = strings.TrimSpace(req.Target)
+
+	if req.Channel == models.AlertChannelWebhook {
+		if err := safehttp.ValidateURL(req.Target); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "target: " + err.Error(),
+			})
+			return false
+		}
+	}
+
+	if !userOwnsCategory(userID, req.CategoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Category not found",
+		})
+		return false
+	}
+
+	rule.UserID = userID
+	rule.CategoryID = req.CategoryID
+	rule.Threshold = req.Threshold
+	rule.Channel = req.Channel
+	rule.Target = req.Target
+	rule.Active = true
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	return true
+}
+
+// IndexAlertRule handler untuk list alert rule milik user
+func IndexAlertRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	query := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID)
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var rules []models.AlertRule
+	if err := query.Order("category_id ASC, threshold ASC").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch alert rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertRuleIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    rules,
+	})
+}
+
+// CreateAlertRule handler untuk membuat alert rule baru pada sebuah category
+func CreateAlertRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var rule models.AlertRule
+	if !bindAlertRuleRequest(c, userID, &rule) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Alert rule creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AlertRuleFetchResponse{
+		Error:   false,
+		Message: "Alert rule creation successful",
+		Data:    rule,
+	})
+}
+
+// UpdateAlertRule handler untuk mengubah alert rule
+func UpdateAlertRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rule, ok := findUserAlertRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if !bindAlertRuleRequest(c, userID, &rule) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating alert rule!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertRuleFetchResponse{
+		Error:   false,
+		Message: "Alert rule successfully updated",
+		Data:    rule,
+	})
+}
+
+// DeleteAlertRuleByID handler untuk menghapus alert rule
+func DeleteAlertRuleByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rule, ok := findUserAlertRule(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.BudgetAlertLog{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&rule).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete alert rule!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertRuleDefaultResponse{
+		Error:   false,
+		Message: "Alert rule deletion successful",
+	})
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/attachments"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// multipartOverhead adalah ruang untuk header multipart di atas ukuran file
+const multipartOverhead = 1 << 20
+
+type AttachmentDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type AttachmentIndexResponse struct {
+	Error   bool                      `json:"error"`
+	Message string                    `json:"message"`
+	Data    []models.PublicAttachment `json:"data"`
+}
+
+type AttachmentFetchResponse struct {
+	Error   bool                    `json:"error"`
+	Message string                  `json:"message"`
+	Data    models.PublicAttachment `json:"data"`
+}
+
+// findUserAttachment mengambil attachment milik user, menulis response error jika gagal
+func findUserAttachment(c *gin.Context, userID uint) (models.Attachment, bool) {
+	var attachment models.Attachment
+
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Attachment ID!",
+		})
+		return attachment, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", attachmentID, userID).First(&attachment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Attachment not found",
+				"message": "Attachment no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch attachment",
+			})
+		}
+		return attachment, false
+	}
+
+	return attachment, true
+}
+
+// findUserTransaction mengambil transaction milik user dari param id,
+// menulis response error jika gagal
+func findUserTransaction(c *gin.Context, userID uint) (models.Transaction, bool) {
+	var transaction models.Transaction
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Transaction ID!",
+		})
+		return transaction, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", transactionID, userID).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Transaction not found",
+				"message": "Transaction no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch transaction",
+			})
+		}
+		return transaction, false
+	}
+
+	return transaction, true
+}
+
+// IndexTransactionAttachment handler untuk daftar attachment sebuah transaction
+func IndexTransactionAttachment(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	transaction, ok := findUserTransaction(c, userID)
+	if !ok {
+		return
+	}
+
+	var list []models.Attachment
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ? AND transaction_id = ?", userID, transaction.ID).Order("id ASC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch attachments",
+		})
+		return
+	}
+
+	now := time.Now()
+	data := make([]models.PublicAttachment, 0, len(list))
+	for _, attachment := range list {
+		data = append(data, attachments.Public(attachment, now))
+	}
+
+	c.JSON(http.StatusOK, AttachmentIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    data,
+	})
+}
+
+// UploadAttachment handler untuk upload attachment ke transaction
+// (multipart field `file`). Jenis file ditentukan dari isinya: gambar
+// (JPEG, PNG, GIF, WebP) atau PDF.
+func UploadAttachment(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	transaction, ok := findUserTransaction(c, userID)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, attachments.MaxSize()+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Upload failed",
+				"message": attachments.ErrTooLarge.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "file is required",
+		})
+		return
+	}
+
+	attachment, err := attachments.Save(c.Request.Context(), userID, transaction.ID, fileHeader)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachments.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Upload failed",
+				"message": err.Error(),
+			})
+		case errors.Is(err, attachments.ErrUnsupportedType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"error":   "Upload failed",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Upload failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, AttachmentFetchResponse{
+		Error:   false,
+		Message: "Attachment upload successful",
+		Data:    attachments.Public(attachment, time.Now()),
+	})
+}
+
+// GetAttachmentByID handler untuk detail attachment beserta URL download baru
+func GetAttachmentByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	attachment, ok := findUserAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, AttachmentFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    attachments.Public(attachment, time.Now()),
+	})
+}
+
+// DownloadAttachment handler untuk download attachment dengan header
+// Authorization. Query variant=thumbnail mengambil thumbnail.
+func DownloadAttachment(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	attachment, ok := findUserAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	serveAttachment(c, attachment, c.Query("variant") == attachments.VariantThumbnail)
+}
+
+// ServeSignedAttachment handler public untuk URL download bertanda tangan
+// (expires & signature), dipakai misalnya oleh tag <img> di client
+func ServeSignedAttachment(c *gin.Context) {
+	attachmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Attachment ID!",
+		})
+		return
+	}
+
+	variant := c.Query("variant")
+	if !attachments.VerifySignature(uint(attachmentID), variant, c.Query("expires"), c.Query("signature"), time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Forbidden",
+			"message": "Download link is invalid or has expired",
+		})
+		return
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.WithContext(c.Request.Context()).First(&attachment, attachmentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Attachment not found",
+			"message": "Attachment no longer exists",
+		})
+		return
+	}
+
+	serveAttachment(c, attachment, variant == attachments.VariantThumbnail)
+}
+
+// serveAttachment men-stream isi attachment atau thumbnail dari storage
+func serveAttachment(c *gin.Context, attachment models.Attachment, thumbnail bool) {
+	reader, err := attachments.Open(c.Request.Context(), attachment, thumbnail)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "File not found",
+				"message": "File is not available",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Storage error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	defer reader.Close()
+
+	contentType, size := attachment.ContentType, attachment.Size
+	if thumbnail {
+		contentType, size = "image/jpeg", -1
+	}
+
+	c.DataFromReader(http.StatusOK, size, contentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=300",
+	})
+}
+
+// DeleteAttachmentByID handler untuk menghapus attachment beserta file-nya
+func DeleteAttachmentByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	attachment, ok := findUserAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := attachments.Delete(c.Request.Context(), attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete attachment!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AttachmentDefaultResponse{
+		Error:   false,
+		Message: "Attachment deletion successful",
+	})
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ashborn.id/moniplan/audit"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditLogList membatasi jumlah audit log yang dikembalikan per request
+const maxAuditLogList = 500
+
+type AuditLogIndexResponse struct {
+	Error   bool                    `json:"error"`
+	Message string                  `json:"message"`
+	Data    []models.PublicAuditLog `json:"data"`
+}
+
+// IndexAuditLog handler untuk riwayat perubahan data milik user. Bisa
+// difilter dengan query entity_type, entity_id, action, start, end dan
+// before_id (untuk halaman berikutnya), jumlah data diatur dengan limit.
+func IndexAuditLog(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	location := models.GetUserCalendar(userID).Location
+	query := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID)
+	if entity := c.Query("entity_type"); entity != "" {
+		if _, ok := audit.TableForEntity(entity); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "entity_type must be one of user, category, budget, transaction",
+			})
+			return
+		}
+		query = query.Where("entity_type = ?", entity)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "entity_id must be a number",
+			})
+			return
+		}
+		query = query.Where("entity_id = ?", id)
+	}
+	if action := c.Query("action"); action != "" {
+		switch action {
+		case models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete, models.AuditActionRestore:
+			query = query.Where("action = ?", action)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "action must be one of create, update, delete, restore",
+			})
+			return
+		}
+	}
+	if start := c.Query("start"); start != "" {
+		date, err := parseDate(start, location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start " + err.Error(),
+			})
+			return
+		}
+		query = query.Where("created_at >= ?", date.UTC())
+	}
+	if end := c.Query("end"); end != "" {
+		date, err := parseDate(end, location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "end " + err.Error(),
+			})
+			return
+		}
+		query = query.Where("created_at < ?", date.AddDate(0, 0, 1).UTC())
+	}
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		id, err := strconv.ParseUint(beforeID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "before_id must be a number",
+			})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > maxAuditLogList {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "limit must be between 1 and " + strconv.Itoa(maxAuditLogList),
+		})
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditLogIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    publicAuditLogs(logs),
+	})
+}
+
+// GetEntityAuditLog handler untuk riwayat lengkap satu data, misalnya
+// /audit/transaction/12, diurutkan dari perubahan pertama
+func GetEntityAuditLog(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	entity := c.Param("entity")
+	if _, ok := audit.TableForEntity(entity); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid entity type!",
+		})
+		return
+	}
+
+	entityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Entity ID!",
+		})
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := database.DB.WithContext(c.Request.Context()).
+		Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entity, entityID).
+		Order("id ASC").
+		Limit(maxAuditLogList).
+		Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditLogIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    publicAuditLogs(logs),
+	})
+}
+
+func publicAuditLogs(logs []models.AuditLog) []models.PublicAuditLog {
+	data := make([]models.PublicAuditLog, 0, len(logs))
+	for i := range logs {
+		data = append(data, logs[i].ToPublicAuditLog())
+	}
+	return data
+}
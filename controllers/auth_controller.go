@@ -1,14 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"strings"
 
-	"ashborn.id/moniplan/database"
 	"ashborn.id/moniplan/middlewares"
 	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/repository"
+	"ashborn.id/moniplan/services"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // RegisterRequest struktur untuk validasi input register
@@ -24,6 +24,18 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// PeriodStartDayRequest struktur untuk mengganti tanggal awal periode budget
+type PeriodStartDayRequest struct {
+	PeriodStartDay int `json:"period_start_day" binding:"required,min=1,max=28"`
+}
+
+// TimeZoneRequest struktur untuk mengganti zona waktu dan locale user.
+// Locale boleh kosong jika hanya zona waktu yang diganti.
+type TimeZoneRequest struct {
+	TimeZone string `json:"time_zone" binding:"required"`
+	Locale   string `json:"locale"`
+}
+
 // AuthResponse struktur untuk response authentication
 type AuthResponse struct {
 	Message string            `json:"message"`
@@ -44,43 +56,21 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Normalize email ke lowercase
-	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
-	req.Name = strings.TrimSpace(req.Name)
-
-	// Cek apakah email sudah terdaftar
-	var existingUser models.User
-	result := database.DB.Where("email = ?", req.Email).First(&existingUser)
-
-	if result.Error == nil {
-		// User dengan email ini sudah ada
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "Email already registered",
-			"message": "An account with this email already exists",
-		})
-		return
-	} else if result.Error != gorm.ErrRecordNotFound {
-		// Error database lainnya
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Database error",
-			"message": "Failed to check existing user",
-		})
-		return
-	}
-
-	// Buat user baru
-	user := models.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password, // Akan di-hash oleh BeforeCreate hook
-	}
-
-	// Simpan ke database
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Registration failed",
-			"message": "Failed to create user account",
-		})
+	// Buat user baru, password akan di-hash oleh BeforeCreate hook
+	user, err := Services.Users.Register(c.Request.Context(), req.Name, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			// User dengan email ini sudah ada
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Email already registered",
+				"message": "An account with this email already exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Registration failed",
+				"message": "Failed to create user account",
+			})
+		}
 		return
 	}
 
@@ -115,14 +105,10 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Normalize email
-	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
-
-	// Cari user berdasarkan email
-	var user models.User
-	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// User tidak ditemukan
+	// Cari user berdasarkan email dan verifikasi password
+	user, err := Services.Users.Authenticate(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Authentication failed",
 				"message": "Invalid email or password",
@@ -137,15 +123,6 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Verifikasi password
-	if err := user.CheckPassword(req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Authentication failed",
-			"message": "Invalid email or password",
-		})
-		return
-	}
-
 	// Generate JWT token
 	token, err := middlewares.GenerateToken(user.ID, user.Email, user.Name)
 	if err != nil {
@@ -177,9 +154,9 @@ func GetProfile(c *gin.Context) {
 	}
 
 	// Fetch user data dari database
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	user, err := Services.Users.Get(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "User not found",
 				"message": "User account no longer exists",
@@ -200,6 +177,110 @@ func GetProfile(c *gin.Context) {
 	})
 }
 
+// UpdatePeriodStartDay handler untuk mengganti tanggal awal periode budget
+// user (misalnya 25 jika gajian tanggal 25). Transaction, budget dan report
+// per bulan setelahnya memakai rentang periode ini.
+func UpdatePeriodStartDay(c *gin.Context) {
+	var req PeriodStartDayRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	user, err := Services.Users.SetPeriodStartDay(c.Request.Context(), userID, req.PeriodStartDay)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "User not found",
+				"message": "User account no longer exists",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to update budget period",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budget period updated",
+		"user":    user.ToPublicUser(),
+	})
+}
+
+// UpdateTimeZone handler untuk mengganti zona waktu (nama IANA, misalnya
+// Asia/Makassar) dan locale user. Input tanggal tanpa offset dibaca di zona
+// ini dan tanggal di response serta notifikasi ditampilkan dalam zona ini.
+func UpdateTimeZone(c *gin.Context) {
+	var req TimeZoneRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	user, err := Services.Users.SetTimeZone(c.Request.Context(), userID, req.TimeZone, req.Locale)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "User not found",
+				"message": "User account no longer exists",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to update time zone",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Time zone updated",
+		"user":    user.ToPublicUser(),
+	})
+}
+
 // RefreshToken handler untuk refresh JWT token
 func RefreshToken(c *gin.Context) {
 	// Ambil user info dari context
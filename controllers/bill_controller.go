@@ -0,0 +1,452 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/alerts"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BillRequest struct {
+	CategoryID uint         `json:"category_id" binding:"required"`
+	Payee      string       `json:"payee" binding:"required,max=100"`
+	Amount     money.Amount `json:"amount" binding:"required,gt=0"`
+	IsEstimate bool         `json:"is_estimate"`
+	DueRule    string       `json:"due_rule" binding:"required,max=50"`
+	Autopay    bool         `json:"autopay"`
+	StartDate  string       `json:"start_date"`
+	Active     *bool        `json:"active"`
+}
+
+type BillPaymentRequest struct {
+	DueDate         string       `json:"due_date" binding:"required"`
+	Amount          money.Amount `json:"amount" binding:"gte=0"`
+	TransactionDate string       `json:"transaction_date"`
+}
+
+type BillDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type BillIndexResponse struct {
+	Error   bool          `json:"error"`
+	Message string        `json:"message"`
+	Data    []models.Bill `json:"data"`
+}
+
+type BillFetchResponse struct {
+	Error   bool        `json:"error"`
+	Message string      `json:"message"`
+	Data    models.Bill `json:"data"`
+}
+
+type BillCalendarResponse struct {
+	Error   bool                    `json:"error"`
+	Message string                  `json:"message"`
+	Data    []models.BillOccurrence `json:"data"`
+}
+
+type BillPaymentResponse struct {
+	Error   bool               `json:"error"`
+	Message string             `json:"message"`
+	Data    models.BillPayment `json:"data"`
+}
+
+// Rentang default calendar jika query start/end tidak diisi
+const (
+	defaultBillCalendarPastDays   = 30
+	defaultBillCalendarFutureDays = 30
+)
+
+// findUserBill mengambil bill milik user, menulis response error jika gagal
+func findUserBill(c *gin.Context, userID uint) (models.Bill, bool) {
+	var bill models.Bill
+
+	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Bill ID!",
+		})
+		return bill, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Bill not found",
+				"message": "Bill no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch bill",
+			})
+		}
+		return bill, false
+	}
+
+	return bill, true
+}
+
+// bindBillRequest memvalidasi request dan mengisi field bill dari request
+func bindBillRequest(c *gin.Context, userID uint, bill *models.Bill) bool {
+	var req BillRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	if _, err := models.ParseDueRule(req.DueRule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	calendar := models.GetUserCalendar(userID)
+	startDate := calendar.Today(time.Now())
+	if req.StartDate != "" {
+		parsedDate, err := parseDate(req.StartDate, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start_date " + err.Error(),
+			})
+			return false
+		}
+		startDate = parsedDate
+	} else if !bill.StartDate.IsZero() {
+		startDate = calendar.In(bill.StartDate)
+	}
+
+	if !userOwnsCategory(userID, req.CategoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Category not found",
+		})
+		return false
+	}
+
+	bill.UserID = userID
+	bill.CategoryID = req.CategoryID
+	bill.Payee = strings.TrimSpace(req.Payee)
+	bill.Amount = req.Amount
+	bill.IsEstimate = req.IsEstimate
+	bill.DueRule = strings.ToLower(strings.TrimSpace(req.DueRule))
+	bill.Autopay = req.Autopay
+	bill.StartDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location()).UTC()
+	bill.Active = true
+	if req.Active != nil {
+		bill.Active = *req.Active
+	}
+	return true
+}
+
+// IndexBill handler untuk list semua bill milik user
+func IndexBill(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var bills []models.Bill
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("payee ASC").Find(&bills).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch bills",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BillIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    bills,
+	})
+}
+
+// CreateBill handler untuk membuat bill baru
+func CreateBill(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var bill models.Bill
+	if !bindBillRequest(c, userID, &bill) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&bill).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Bill creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, BillFetchResponse{
+		Error:   false,
+		Message: "Bill creation successful",
+		Data:    bill,
+	})
+}
+
+// GetBillByID handler untuk mengambil detail bill
+func GetBillByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	bill, ok := findUserBill(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, BillFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    bill,
+	})
+}
+
+// UpdateBill handler untuk mengubah bill
+func UpdateBill(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	bill, ok := findUserBill(c, userID)
+	if !ok {
+		return
+	}
+
+	if !bindBillRequest(c, userID, &bill) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&bill).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating bill!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BillFetchResponse{
+		Error:   false,
+		Message: "Bill successfully updated",
+		Data:    bill,
+	})
+}
+
+// DeleteBillByID handler untuk menghapus bill. Transaction pembayaran yang
+// sudah tercatat tidak ikut terhapus.
+func DeleteBillByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	bill, ok := findUserBill(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Where("bill_id = ?", bill.ID).Delete(&models.BillPayment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bill_id = ?", bill.ID).Delete(&models.BillReminder{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bill).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete bill!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BillDefaultResponse{
+		Error:   false,
+		Message: "Bill deletion successful",
+	})
+}
+
+// GetBillCalendar handler untuk calendar tagihan upcoming dan overdue di
+// rentang tanggal `start` sampai `end` (format YYYY-MM-DD, zona waktu user)
+func GetBillCalendar(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	// Hari ini dan rentang tanggal mengikuti zona waktu user
+	calendar := models.GetUserCalendar(userID)
+	today := calendar.Today(time.Now())
+
+	start, errStart := parseDate(c.DefaultQuery("start", today.AddDate(0, 0, -defaultBillCalendarPastDays).Format(dateLayout)), calendar.Location)
+	end, errEnd := parseDate(c.DefaultQuery("end", today.AddDate(0, 0, defaultBillCalendarFutureDays).Format(dateLayout)), calendar.Location)
+	if errStart != nil || errEnd != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "start and end must be dates (YYYY-MM-DD) and start must not be after end",
+		})
+		return
+	}
+
+	occurrences, err := models.GetBillCalendar(userID, start, end, today)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch bill calendar",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BillCalendarResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    occurrences,
+	})
+}
+
+// PayBill handler untuk menandai tagihan pada due date tertentu sebagai paid.
+// Transaction pembayaran dibuat otomatis di category bill.
+func PayBill(c *gin.Context) {
+	var req BillPaymentRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	bill, ok := findUserBill(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	calendar := models.GetUserCalendar(userID)
+	dueDate, err := parseDate(req.DueDate, calendar.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "due_date " + err.Error(),
+		})
+		return
+	}
+
+	dates, err := bill.Occurrences(dueDate, dueDate)
+	if err != nil || len(dates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Bill is not due on the given due_date",
+		})
+		return
+	}
+
+	paidAt := time.Now().UTC()
+	if req.TransactionDate != "" {
+		parsedTime, err := parseDateTime(req.TransactionDate, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "transaction_date " + err.Error(),
+			})
+			return
+		}
+		paidAt = parsedTime
+	}
+
+	amount := bill.Amount
+	if req.Amount > 0 {
+		amount = req.Amount
+	}
+
+	payment, err := bill.MarkPaid(c.Request.Context(), dueDate, amount, paidAt)
+	if err != nil {
+		if err == models.ErrBillAlreadyPaid {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Already paid",
+				"message": err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Bill payment failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	alerts.EvaluateAsync(userID, bill.CategoryID, paidAt)
+
+	var transaction models.Transaction
+	if err := database.DB.WithContext(c.Request.Context()).First(&transaction, payment.TransactionID).Error; err == nil {
+		events.Publish(userID, events.TransactionCreated, transaction.ToPublicTransaction())
+	}
+
+	c.JSON(http.StatusCreated, BillPaymentResponse{
+		Error:   false,
+		Message: "Bill marked as paid",
+		Data:    payment,
+	})
+}
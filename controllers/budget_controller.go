@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+	"ashborn.id/moniplan/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetRequest struct {
+	CategoryID uint         `json:"category_id" binding:"required"`
+	Month      uint         `json:"month" binding:"required"`
+	Year       uint         `json:"year" binding:"required"`
+	Amount     money.Amount `json:"amount" binding:"required,gt=0"`
+}
+
+type BudgetCopyRequest struct {
+	Month     uint `json:"month" binding:"required"`
+	Year      uint `json:"year" binding:"required"`
+	Overwrite bool `json:"overwrite"`
+}
+
+type BudgetYearItemRequest struct {
+	CategoryID uint         `json:"category_id" binding:"required"`
+	Amount     money.Amount `json:"amount" binding:"required,gt=0"`
+	Months     []uint       `json:"months"`
+}
+
+type BudgetYearRequest struct {
+	Year  uint                    `json:"year" binding:"required"`
+	Items []BudgetYearItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type BudgetDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type BudgetIndexResponse struct {
+	Error   bool                    `json:"error"`
+	Message string                  `json:"message"`
+	Period  models.BudgetPeriod     `json:"period"`
+	Data    []models.BudgetCategory `json:"data"`
+}
+
+type BudgetFetchResponse struct {
+	Error   bool                `json:"error"`
+	Message string              `json:"message"`
+	Data    models.PublicBudget `json:"data"`
+}
+
+type BudgetListResponse struct {
+	Error   bool                  `json:"error"`
+	Message string                `json:"message"`
+	Data    []models.PublicBudget `json:"data"`
+}
+
+// IndexBudget handler untuk budget semua category pada satu periode. Query
+// `year` dan `month` default ke periode berjalan user.
+func IndexBudget(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	period, ok := queryPeriod(c, userID)
+	if !ok {
+		return
+	}
+
+	budgets, err := Services.Budgets.List(c.Request.Context(), userID, period.Year, period.Month)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to fetch budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, BudgetIndexResponse{
+		Error:   false,
+		Message: "Budget fetch successful",
+		Period:  period,
+		Data:    budgets,
+	})
+}
+
+// SetBudget handler untuk mengisi atau mengganti nominal budget category
+// pada satu bulan
+func SetBudget(c *gin.Context) {
+	var req BudgetRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := Services.Budgets.Set(c.Request.Context(), userID, services.BudgetInput{
+		CategoryID: req.CategoryID,
+		Month:      req.Month,
+		Year:       req.Year,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		respondBudgetError(c, err, "Failed to save budget")
+		return
+	}
+
+	publishBudgetResults(userID, []services.BudgetSaveResult{result})
+
+	c.JSON(http.StatusOK, BudgetFetchResponse{
+		Error:   false,
+		Message: "Budget successfully saved",
+		Data:    result.Budget.ToPublicBudget(),
+	})
+}
+
+// CopyBudget handler untuk menyalin budget bulan sebelumnya ke bulan di
+// request. Budget yang sudah ada hanya diganti jika overwrite bernilai true.
+func CopyBudget(c *gin.Context) {
+	var req BudgetCopyRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	results, err := Services.Budgets.CopyPreviousMonth(c.Request.Context(), userID, req.Year, req.Month, req.Overwrite)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to copy budget")
+		return
+	}
+
+	publishBudgetResults(userID, results)
+
+	c.JSON(http.StatusOK, BudgetListResponse{
+		Error:   false,
+		Message: "Budget successfully copied",
+		Data:    publicBudgetResults(results),
+	})
+}
+
+// UpdateBudgetYear handler untuk mengisi budget beberapa category sekaligus
+// dalam satu tahun. Item tanpa months diterapkan ke semua bulan.
+func UpdateBudgetYear(c *gin.Context) {
+	var req BudgetYearRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	items := make([]services.BudgetYearItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, services.BudgetYearItem{
+			CategoryID: item.CategoryID,
+			Amount:     item.Amount,
+			Months:     item.Months,
+		})
+	}
+
+	results, err := Services.Budgets.SetYear(c.Request.Context(), userID, req.Year, items)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to save budget")
+		return
+	}
+
+	publishBudgetResults(userID, results)
+
+	c.JSON(http.StatusOK, BudgetListResponse{
+		Error:   false,
+		Message: "Budget successfully saved",
+		Data:    publicBudgetResults(results),
+	})
+}
+
+// GetBudgetHistory handler untuk riwayat budget sebuah category dari bulan
+// terlama
+func GetBudgetHistory(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Category ID!",
+		})
+		return
+	}
+
+	budgets, err := Services.Budgets.History(c.Request.Context(), userID, uint(categoryID))
+	if err != nil {
+		respondBudgetError(c, err, "Failed to fetch budget history")
+		return
+	}
+
+	data := make([]models.PublicBudget, 0, len(budgets))
+	for _, budget := range budgets {
+		data = append(data, budget.ToPublicBudget())
+	}
+
+	c.JSON(http.StatusOK, BudgetListResponse{
+		Error:   false,
+		Message: "Budget history fetch successful",
+		Data:    data,
+	})
+}
+
+// DeleteBudgetByID handler untuk memindahkan budget satu bulan ke trash
+func DeleteBudgetByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Budget ID!",
+		})
+		return
+	}
+
+	budget, err := Services.Budgets.Delete(c.Request.Context(), userID, uint(budgetID))
+	if err != nil {
+		respondBudgetError(c, err, "Unable to delete budget!")
+		return
+	}
+
+	events.Publish(userID, events.BudgetDeleted, budget.ToPublicBudget())
+
+	c.JSON(http.StatusOK, BudgetDefaultResponse{
+		Error:   false,
+		Message: "Budget deletion successful",
+	})
+}
+
+// respondBudgetError menulis response error dari BudgetService
+func respondBudgetError(c *gin.Context, err error, message string) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"message": "Budget or category no longer exists",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": message,
+		})
+	}
+}
+
+// publishBudgetResults mengirim event budget.created atau budget.updated
+// untuk setiap budget yang disimpan
+func publishBudgetResults(userID uint, results []services.BudgetSaveResult) {
+	for _, result := range results {
+		event := events.BudgetUpdated
+		if result.Created {
+			event = events.BudgetCreated
+		}
+		events.Publish(userID, event, result.Budget.ToPublicBudget())
+	}
+}
+
+func publicBudgetResults(results []services.BudgetSaveResult) []models.PublicBudget {
+	data := make([]models.PublicBudget, 0, len(results))
+	for _, result := range results {
+		data = append(data, result.Budget.ToPublicBudget())
+	}
+	return data
+}
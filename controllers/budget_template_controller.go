@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+	"ashborn.id/moniplan/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Format bulan untuk rentang apply template, misalnya 2025-03
+const monthLayout = "2006-01"
+
+type BudgetTemplateItemRequest struct {
+	CategoryID uint                    `json:"category_id" binding:"required"`
+	Amount     money.Amount            `json:"amount" binding:"required,gt=0"`
+	Overrides  []models.BudgetOverride `json:"overrides"`
+}
+
+type BudgetTemplateRequest struct {
+	Name  string                      `json:"name" binding:"required"`
+	Items []BudgetTemplateItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type BudgetTemplateApplyRequest struct {
+	Start     string `json:"start" binding:"required"`
+	End       string `json:"end" binding:"required"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+type BudgetTemplateIndexResponse struct {
+	Error   bool                          `json:"error"`
+	Message string                        `json:"message"`
+	Data    []models.PublicBudgetTemplate `json:"data"`
+}
+
+type BudgetTemplateFetchResponse struct {
+	Error   bool                        `json:"error"`
+	Message string                      `json:"message"`
+	Data    models.PublicBudgetTemplate `json:"data"`
+}
+
+type BudgetPlanResponse struct {
+	Error   bool              `json:"error"`
+	Message string            `json:"message"`
+	Data    models.AnnualPlan `json:"data"`
+}
+
+// IndexBudgetTemplate handler untuk daftar template budget user
+func IndexBudgetTemplate(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	templates, err := Services.Templates.List(c.Request.Context(), userID)
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to fetch budget template")
+		return
+	}
+
+	data := make([]models.PublicBudgetTemplate, 0, len(templates))
+	for _, template := range templates {
+		data = append(data, template.ToPublicBudgetTemplate())
+	}
+
+	c.JSON(http.StatusOK, BudgetTemplateIndexResponse{
+		Error:   false,
+		Message: "Budget template fetch successful",
+		Data:    data,
+	})
+}
+
+// CreateBudgetTemplate handler untuk membuat template budget baru
+func CreateBudgetTemplate(c *gin.Context) {
+	var req BudgetTemplateRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	template, err := Services.Templates.Create(c.Request.Context(), userID, req.toInput())
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to create budget template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, BudgetTemplateFetchResponse{
+		Error:   false,
+		Message: "Budget template successfully created",
+		Data:    template.ToPublicBudgetTemplate(),
+	})
+}
+
+// GetBudgetTemplateByID handler untuk detail template budget
+func GetBudgetTemplateByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Template ID!",
+		})
+		return
+	}
+
+	template, err := Services.Templates.Get(c.Request.Context(), userID, uint(templateID))
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to fetch budget template")
+		return
+	}
+
+	c.JSON(http.StatusOK, BudgetTemplateFetchResponse{
+		Error:   false,
+		Message: "Budget template fetch successful",
+		Data:    template.ToPublicBudgetTemplate(),
+	})
+}
+
+// UpdateBudgetTemplate handler untuk mengganti nama dan item template budget
+func UpdateBudgetTemplate(c *gin.Context) {
+	var req BudgetTemplateRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Template ID!",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	template, err := Services.Templates.Update(c.Request.Context(), userID, uint(templateID), req.toInput())
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to update budget template")
+		return
+	}
+
+	c.JSON(http.StatusOK, BudgetTemplateFetchResponse{
+		Error:   false,
+		Message: "Budget template successfully updated",
+		Data:    template.ToPublicBudgetTemplate(),
+	})
+}
+
+// DeleteBudgetTemplateByID handler untuk menghapus template budget. Budget
+// yang sudah dibuat dari template tidak ikut terhapus.
+func DeleteBudgetTemplateByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Template ID!",
+		})
+		return
+	}
+
+	if err := Services.Templates.Delete(c.Request.Context(), userID, uint(templateID)); err != nil {
+		respondBudgetTemplateError(c, err, "Unable to delete budget template!")
+		return
+	}
+
+	c.JSON(http.StatusOK, BudgetDefaultResponse{
+		Error:   false,
+		Message: "Budget template deletion successful",
+	})
+}
+
+// ApplyBudgetTemplate handler untuk mengisi budget dari template untuk
+// rentang bulan start sampai end (format YYYY-MM). Budget yang sudah ada
+// hanya diganti jika overwrite bernilai true.
+func ApplyBudgetTemplate(c *gin.Context) {
+	var req BudgetTemplateApplyRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Template ID!",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	start, err := time.Parse(monthLayout, req.Start)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "start must use YYYY-MM format",
+		})
+		return
+	}
+	end, err := time.Parse(monthLayout, req.End)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "end must use YYYY-MM format",
+		})
+		return
+	}
+
+	results, err := Services.Templates.Apply(c.Request.Context(), userID, uint(templateID), start, end, req.Overwrite)
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to apply budget template")
+		return
+	}
+
+	publishBudgetResults(userID, results)
+
+	c.JSON(http.StatusOK, BudgetListResponse{
+		Error:   false,
+		Message: "Budget template successfully applied",
+		Data:    publicBudgetResults(results),
+	})
+}
+
+// GetBudgetPlan handler untuk rencana budget tahunan per category dan bulan.
+// Query `year` default ke tahun periode berjalan user; jika `template_id` diisi, rencana
+// dihitung dari template tanpa menyimpan budget.
+func GetBudgetPlan(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	current, err := Services.Users.CurrentPeriod(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch budget period",
+		})
+		return
+	}
+
+	year := uint64(current.Year)
+	var templateID uint64
+
+	if value := c.Query("year"); value != "" {
+		year, err = strconv.ParseUint(value, 10, 32)
+	}
+	if value := c.Query("template_id"); value != "" && err == nil {
+		templateID, err = strconv.ParseUint(value, 10, 32)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "year and template_id must be numbers",
+		})
+		return
+	}
+
+	plan, err := Services.Templates.Plan(c.Request.Context(), userID, uint(year), uint(templateID))
+	if err != nil {
+		respondBudgetTemplateError(c, err, "Failed to fetch budget plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, BudgetPlanResponse{
+		Error:   false,
+		Message: "Budget plan fetch successful",
+		Data:    plan,
+	})
+}
+
+func (req BudgetTemplateRequest) toInput() services.BudgetTemplateInput {
+	input := services.BudgetTemplateInput{Name: req.Name}
+	for _, item := range req.Items {
+		input.Items = append(input.Items, services.BudgetTemplateItemInput{
+			CategoryID: item.CategoryID,
+			Amount:     item.Amount,
+			Overrides:  item.Overrides,
+		})
+	}
+	return input
+}
+
+// respondBudgetTemplateError menulis response error dari
+// BudgetTemplateService
+func respondBudgetTemplateError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"message": "Budget template no longer exists",
+		})
+		return
+	}
+	respondBudgetError(c, err, message)
+}
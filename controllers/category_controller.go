@@ -1,24 +1,36 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
-	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
 	"ashborn.id/moniplan/middlewares"
 	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+	"ashborn.id/moniplan/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type CategoryRequest struct {
-	CategoryID uint   `json:"category_id"`
-	Name       string `json:"name" binding:"required,max=100"`
-	Month      uint   `json:"month" binding:"required"`
-	Year       uint   `json:"year" binding:"required"`
-	Amount     uint   `json:"amount" binding:"required"`
+	CategoryID uint         `json:"category_id"`
+	Name       string       `json:"name" binding:"required,max=100"`
+	Month      uint         `json:"month" binding:"required"`
+	Year       uint         `json:"year" binding:"required"`
+	Amount     money.Amount `json:"amount" binding:"required,gt=0"`
+}
+
+func (r CategoryRequest) toInput() services.CategoryInput {
+	return services.CategoryInput{
+		Name:   r.Name,
+		Month:  r.Month,
+		Year:   r.Year,
+		Amount: r.Amount,
+	}
 }
 
 type CategoryDefaultResponse struct {
@@ -50,42 +62,13 @@ func IndexCategory(c *gin.Context) {
 		return
 	}
 
-	// now := time.Now() // current local time
-	// currentYear, currentMonth, _ := now.Date()
-	// current_year := currentYear
-	// current_month := int(currentMonth)
-
-	// param_month := c.DefaultQuery("month", strconv.Itoa(current_month))
-	// param_year := c.DefaultQuery("year", strconv.Itoa(current_year))
-
-	// Fetch user data dari database
-	subQuery := database.DB.
-		Table("budgets").
-		Select("category_id, MAX(id) as latest_id").
-		Group("category_id")
-
-	var categories []models.CategoryAndBudget
-	err := database.DB.
-		Table("categories c").
-		Select("c.id, b.category_id, c.user_id, c.name, b.month, b.year, b.amount").
-		Joins("JOIN budgets b ON b.category_id = c.id").
-		Joins("JOIN (?) latest ON b.category_id = latest.category_id AND b.id = latest.latest_id", subQuery).
-		Where("c.user_id = ?", userID).
-		Order("c.name ASC").
-		Scan(&categories).Error
-
+	// Fetch category beserta budget terakhirnya
+	categories, err := Services.Categories.List(c.Request.Context(), userID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "Data Not found",
-				"message": "Data category and budget no longer exists",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Database error",
-				"message": "Failed to fetch category data",
-			})
-		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch category data",
+		})
 		return
 	}
 
@@ -118,52 +101,22 @@ func CreateCategory(c *gin.Context) {
 		return
 	}
 
-	req.Name = strings.ToLower(req.Name)
-
-	now := time.Now()
-
-	// Cek apakah email sudah terdaftar
-	var existingCategory models.Category
-	result := database.DB.Where("name = ?", req.Name).Where("user_id = ?", userID).First(&existingCategory)
-
-	if result.Error == nil {
-		req.CategoryID = existingCategory.ID
-	} else {
-		// Buat user baru
-		newCategory := models.Category{
-			UserID:    userID,
-			Name:      req.Name,
-			CreatedAt: now,
-			UpdatedAt: now,
-		}
-
-		if err := database.DB.Create(&newCategory).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Category creation failed",
-				"message": err,
-			})
-			return
-		}
-
-		req.CategoryID = newCategory.ID
-	}
-
-	newBudget := models.Budget{
-		UserID:     userID,
-		CategoryID: req.CategoryID,
-		Month:      req.Month,
-		Year:       req.Year,
-		Amount:     req.Amount,
-		CreatedAt:  now,
-		UpdatedAt:  now,
-	}
-
-	if err := database.DB.Create(&newBudget).Error; err != nil {
+	// Category dengan nama yang sama dipakai ulang, budget selalu ditambahkan
+	result, err := Services.Categories.Create(c.Request.Context(), userID, req.toInput())
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Budget creation failed",
-			"message": err,
+			"error":   "Category creation failed",
+			"message": err.Error(),
 		})
+		return
+	}
+
+	event := events.CategoryUpdated
+	if result.Created {
+		event = events.CategoryCreated
 	}
+	events.Publish(userID, event, gin.H{"id": result.Category.ID, "name": result.Category.Name})
+	events.Publish(userID, events.BudgetCreated, result.Budget.ToPublicBudget())
 
 	// Success response
 	c.JSON(http.StatusCreated, CategoryDefaultResponse{
@@ -173,11 +126,6 @@ func CreateCategory(c *gin.Context) {
 }
 
 func GetCategoryByID(c *gin.Context) {
-	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	var category models.Category
-	var budget models.Budget
-	year, month, _ := time.Now().Date()
-
 	userID, exists := middlewares.GetUserIDFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -187,39 +135,35 @@ func GetCategoryByID(c *gin.Context) {
 		return
 	}
 
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Unauthorized",
 			"message": "Invalid Category ID!",
 		})
 		return
-	} else {
-		if errCategory := database.DB.First(&category, categoryID).Error; errCategory != nil {
-			if errCategory == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{
-					"error":   "Category not found",
-					"message": "Category no longer exists",
-				})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "Database error",
-					"message": "Failed to fetch category",
-				})
-			}
-			return
-		}
+	}
 
-		if errBudget := database.DB.Where("category_id = ? AND user_id = ? AND year = ? AND month = ?", categoryID, userID, year, month).Last(&budget).Error; errBudget != nil {
-			if errBudget == gorm.ErrRecordNotFound {
-				if errBudget = database.DB.Where("category_id = ? AND user_id = ?", categoryID, userID).Last(&budget).Error; errBudget != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"error":   "Database error",
-						"message": "Failed to fetch budget",
-					})
-					return
-				}
-			}
+	category, budget, err := Services.Categories.Get(c.Request.Context(), userID, uint(categoryID), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Category not found",
+				"message": "Category no longer exists",
+			})
+		case errors.Is(err, services.ErrNoBudget):
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch budget",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch category",
+			})
 		}
+		return
 	}
 
 	// Success response
@@ -247,6 +191,7 @@ func UpdateCategory(c *gin.Context) {
 			"error":   "Required Param",
 			"message": err.Error(),
 		})
+		return
 	}
 
 	var req CategoryRequest
@@ -260,49 +205,18 @@ func UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	req.Name = strings.ToLower(req.Name)
-
-	now := time.Now()
-
-	var existingCategory models.Category
-	result := database.DB.First(&existingCategory, categoryID)
-
-	if result.Error == nil {
-		req.CategoryID = existingCategory.ID
-	} else {
-		newCategory := models.Category{
-			UserID:    userID,
-			Name:      req.Name,
-			CreatedAt: now,
-			UpdatedAt: now,
-		}
-
-		if err := database.DB.Create(&newCategory).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Category creation failed",
-				"message": err,
-			})
-			return
-		}
-	}
-
-	newBudget := models.Budget{
-		UserID:     userID,
-		CategoryID: req.CategoryID,
-		Month:      req.Month,
-		Year:       req.Year,
-		Amount:     req.Amount,
-		CreatedAt:  now,
-		UpdatedAt:  now,
-	}
-
-	if err := database.DB.Create(&newBudget).Error; err != nil {
+	result, err := Services.Categories.Update(c.Request.Context(), userID, uint(categoryID), req.toInput())
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Budget creation failed",
-			"message": err,
+			"error":   "Category update failed",
+			"message": err.Error(),
 		})
+		return
 	}
 
+	events.Publish(userID, events.CategoryUpdated, gin.H{"id": result.Category.ID, "name": result.Category.Name})
+	events.Publish(userID, events.BudgetCreated, result.Budget.ToPublicBudget())
+
 	// Success response
 	c.JSON(http.StatusCreated, CategoryDefaultResponse{
 		Error:   false,
@@ -310,7 +224,20 @@ func UpdateCategory(c *gin.Context) {
 	})
 }
 
+// DeleteCategoryByID handler untuk memindahkan category ke trash. Query
+// mode menentukan perlakuan data yang memakai category: block (default)
+// menolak jika masih dipakai, reassign memindahkan ke category target_id,
+// cascade ikut memindahkan transaction dan budget ke trash.
 func DeleteCategoryByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
 	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 
 	if err != nil {
@@ -321,17 +248,104 @@ func DeleteCategoryByID(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&models.Category{}, categoryID).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"error":   "Failed",
-			"message": "Unable to delete category!",
+	targetID, err := strconv.ParseUint(c.DefaultQuery("target_id", "0"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "target_id must be a category ID",
 		})
 		return
 	}
 
+	mode := c.DefaultQuery("mode", models.CategoryDeleteBlock)
+	switch mode {
+	case models.CategoryDeleteBlock, models.CategoryDeleteReassign, models.CategoryDeleteCascade:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "mode must be block, reassign or cascade",
+		})
+		return
+	}
+
+	if _, err := models.DeleteCategory(c.Request.Context(), userID, uint(categoryID), mode, uint(targetID), time.Now()); err != nil {
+		var inUse *models.CategoryInUseError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Category not found",
+				"message": "Category no longer exists",
+			})
+		case errors.As(err, &inUse):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":      "Category in use",
+				"message":    inUse.Error(),
+				"references": inUse.References,
+			})
+		case errors.Is(err, models.ErrInvalidReassignTarget):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed",
+				"message": "Unable to delete category!",
+			})
+		}
+		return
+	}
+
+	events.Publish(userID, events.CategoryDeleted, gin.H{"id": categoryID, "mode": mode})
+
 	// Success response
 	c.JSON(http.StatusCreated, CategoryDefaultResponse{
 		Error:   false,
 		Message: "Category deletion successful",
 	})
 }
+
+// RestoreCategory handler untuk mengembalikan category dari trash, termasuk
+// transaction dan budget yang ikut terhapus secara cascade
+func RestoreCategory(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Category ID!",
+		})
+		return
+	}
+
+	category, err := models.RestoreCategory(c.Request.Context(), userID, uint(categoryID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Category not found",
+				"message": "Category is not in the trash",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Restore failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	events.Publish(userID, events.CategoryRestored, category.ToPublicCategory())
+
+	c.JSON(http.StatusOK, CategoryDefaultResponse{
+		Error:   false,
+		Message: "Category restored",
+	})
+}
@@ -1,14 +1,135 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/services"
 	"github.com/gin-gonic/gin"
 )
 
+// Services dipakai handler user, category, budget dan transaction untuk
+// akses data, diisi di main dengan repository GORM. Test bisa mengisinya
+// dengan services.New(repository.NewMemory(), nil).
+var Services *services.Services
+
+// Format tanggal yang diterima dari request body. Selain format ini,
+// RFC 3339 dengan offset (misalnya 2026-05-03T10:00:00+07:00) juga diterima.
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// localDateTimeLayouts adalah format waktu tanpa offset yang dibaca di zona
+// waktu user
+var localDateTimeLayouts = []string{
+	dateTimeLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	dateLayout,
+}
+
+// errInvalidDate dipakai parseDateTime dan parseDate untuk format yang tidak dikenal
+var errInvalidDate = errors.New("must be RFC 3339 or YYYY-MM-DD[ HH:MM[:SS]]")
+
+// parseDateTime membaca waktu dari request. RFC 3339 memakai offset yang
+// diberikan, format lokal dibaca di zona waktu user. Hasilnya dalam UTC.
+func parseDateTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC(), nil
+	}
+	for _, layout := range localDateTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, errInvalidDate
+}
+
+// parseDate membaca tanggal dari request dan mengembalikan tengah malam
+// tanggal tersebut di zona waktu user. Waktu RFC 3339 dikonversi ke zona
+// waktu user lebih dulu.
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	parsed, err := parseDateTime(value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := parsed.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc), nil
+}
+
+// calendarDate menyimpan tanggal kalender tanpa jam (start date loan, target
+// date goal, tanggal kurs) sebagai tengah malam UTC, sehingga tanggalnya
+// tidak bergeser saat dibaca dari database
+func calendarDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
 func EmptyController(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"error":   "No error",
 		"message": "Still In Progress",
 	})
 }
+
+// userOwnsCategory memastikan category yang di-link memang milik user
+func userOwnsCategory(userID, categoryID uint) bool {
+	var count int64
+	database.DB.Model(&models.Category{}).Where("id = ? AND user_id = ?", categoryID, userID).Count(&count)
+	return count > 0
+}
+
+// queryPeriod membaca periode budget dari query `year` dan `month`, default
+// ke periode berjalan user. Rentang tanggalnya mengikuti tanggal awal periode
+// user. Jika gagal, response error sudah ditulis dan ok bernilai false.
+func queryPeriod(c *gin.Context, userID uint) (period models.BudgetPeriod, ok bool) {
+	current, err := Services.Users.CurrentPeriod(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch budget period",
+		})
+		return models.BudgetPeriod{}, false
+	}
+
+	year, month := uint64(current.Year), uint64(current.Month)
+	if value := c.Query("year"); value != "" {
+		year, err = strconv.ParseUint(value, 10, 32)
+	}
+	if value := c.Query("month"); value != "" && err == nil {
+		month, err = strconv.ParseUint(value, 10, 32)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "year and month must be numbers",
+		})
+		return models.BudgetPeriod{}, false
+	}
+
+	period, err = Services.Users.Period(c.Request.Context(), userID, uint(year), uint(month))
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch budget period",
+			})
+		}
+		return models.BudgetPeriod{}, false
+	}
+	return period, true
+}
@@ -0,0 +1,436 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateRequest struct {
+	Base  string  `json:"base" binding:"required,len=3"`
+	Quote string  `json:"quote" binding:"required,len=3"`
+	Date  string  `json:"date" binding:"required"`
+	Rate  float64 `json:"rate" binding:"required,gt=0"`
+}
+
+type BaseCurrencyRequest struct {
+	BaseCurrency string `json:"base_currency" binding:"required,len=3"`
+}
+
+type ExchangeRateDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type ExchangeRateIndexResponse struct {
+	Error   bool                  `json:"error"`
+	Message string                `json:"message"`
+	Data    []models.ExchangeRate `json:"data"`
+}
+
+type ExchangeRateFetchResponse struct {
+	Error   bool                `json:"error"`
+	Message string              `json:"message"`
+	Data    models.ExchangeRate `json:"data"`
+}
+
+type ExchangeRateImportResponse struct {
+	Error    bool   `json:"error"`
+	Message  string `json:"message"`
+	Imported int    `json:"imported"`
+}
+
+// maxExchangeRateList membatasi jumlah rate yang dikembalikan index
+const maxExchangeRateList = 1000
+
+// ecbDateLayouts adalah format tanggal pada file ECB: histori
+// (eurofxref-hist.csv) dan harian (eurofxref.csv)
+var ecbDateLayouts = []string{dateLayout, "02 January 2006", "2 January 2006"}
+
+// IndexExchangeRate handler untuk list exchange rate user, bisa difilter
+// dengan query base, quote, start dan end
+func IndexExchangeRate(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	query := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID)
+	if base := c.Query("base"); base != "" {
+		query = query.Where("base = ?", strings.ToUpper(base))
+	}
+	if quote := c.Query("quote"); quote != "" {
+		query = query.Where("quote = ?", strings.ToUpper(quote))
+	}
+	if start := c.Query("start"); start != "" {
+		query = query.Where("date >= ?", start)
+	}
+	if end := c.Query("end"); end != "" {
+		query = query.Where("date <= ?", end)
+	}
+
+	var rates []models.ExchangeRate
+	if err := query.Order("date DESC, base ASC, quote ASC").Limit(maxExchangeRateList).Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch exchange rates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRateIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    rates,
+	})
+}
+
+// CreateExchangeRate handler untuk input rate manual. Rate yang sudah ada
+// untuk pasangan dan tanggal yang sama akan ditimpa.
+func CreateExchangeRate(c *gin.Context) {
+	var req ExchangeRateRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	date, err := time.Parse(dateLayout, req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "date must be in format YYYY-MM-DD",
+		})
+		return
+	}
+
+	rate := models.ExchangeRate{
+		UserID: userID,
+		Base:   models.NormalizeCurrency(req.Base, ""),
+		Quote:  models.NormalizeCurrency(req.Quote, ""),
+		Date:   date,
+		Rate:   req.Rate,
+		Source: models.RateSourceManual,
+	}
+
+	if !models.IsValidCurrency(rate.Base) || !models.IsValidCurrency(rate.Quote) || rate.Base == rate.Quote {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "base and quote must be different 3-letter currency codes",
+		})
+		return
+	}
+
+	if err := models.UpsertExchangeRates([]models.ExchangeRate{rate}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Exchange rate creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ExchangeRateFetchResponse{
+		Error:   false,
+		Message: "Exchange rate saved",
+		Data:    rate,
+	})
+}
+
+// DeleteExchangeRateByID handler untuk menghapus exchange rate
+func DeleteExchangeRateByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Exchange Rate ID!",
+		})
+		return
+	}
+
+	result := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", rateID, userID).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete exchange rate!",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Exchange rate not found",
+			"message": "Exchange rate no longer exists",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRateDefaultResponse{
+		Error:   false,
+		Message: "Exchange rate deletion successful",
+	})
+}
+
+// ImportExchangeRate handler untuk import rate dari file (multipart field
+// `file`). Query format=csv (default) membaca kolom date,base,quote,rate;
+// format=ecb membaca file referensi ECB (Date,USD,JPY,... berbasis EUR).
+func ImportExchangeRate(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "file is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Unable to read file",
+		})
+		return
+	}
+	defer file.Close()
+
+	var rates []models.ExchangeRate
+	switch c.DefaultQuery("format", models.RateSourceCSV) {
+	case models.RateSourceCSV:
+		rates, err = parseExchangeRateCSV(userID, file)
+	case models.RateSourceECB:
+		rates, err = parseExchangeRateECB(userID, file)
+	default:
+		err = errors.New("format must be csv or ecb")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Import failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := models.UpsertExchangeRates(rates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Import failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRateImportResponse{
+		Error:    false,
+		Message:  "Exchange rates imported",
+		Imported: len(rates),
+	})
+}
+
+// parseExchangeRateCSV membaca CSV dengan header date,base,quote,rate
+func parseExchangeRateCSV(userID uint, r io.Reader) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("file is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("missing column " + name)
+		}
+	}
+
+	var rates []models.ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse(dateLayout, strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, errors.New("invalid date on line " + strconv.Itoa(line))
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[columns["rate"]]), 64)
+		if err != nil || value <= 0 {
+			return nil, errors.New("invalid rate on line " + strconv.Itoa(line))
+		}
+
+		rate := models.ExchangeRate{
+			UserID: userID,
+			Base:   models.NormalizeCurrency(record[columns["base"]], ""),
+			Quote:  models.NormalizeCurrency(record[columns["quote"]], ""),
+			Date:   date,
+			Rate:   value,
+			Source: models.RateSourceCSV,
+		}
+		if !models.IsValidCurrency(rate.Base) || !models.IsValidCurrency(rate.Quote) || rate.Base == rate.Quote {
+			return nil, errors.New("invalid currency on line " + strconv.Itoa(line))
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// parseExchangeRateECB membaca format ECB: kolom pertama Date, kolom
+// berikutnya kode currency dengan nilai 1 EUR dalam currency tersebut.
+// Nilai "N/A" dan kolom kosong dilewati.
+func parseExchangeRateECB(userID uint, r io.Reader) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("file is empty")
+	}
+	if len(header) < 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "date") {
+		return nil, errors.New("first column must be Date")
+	}
+
+	var rates []models.ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var date time.Time
+		for _, layout := range ecbDateLayouts {
+			if date, err = time.Parse(layout, strings.TrimSpace(record[0])); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, errors.New("invalid date on line " + strconv.Itoa(line))
+		}
+
+		for i := 1; i < len(record) && i < len(header); i++ {
+			quote := models.NormalizeCurrency(header[i], "")
+			value, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if !models.IsValidCurrency(quote) || quote == "EUR" || err != nil || value <= 0 {
+				continue
+			}
+			rates = append(rates, models.ExchangeRate{
+				UserID: userID,
+				Base:   "EUR",
+				Quote:  quote,
+				Date:   date,
+				Rate:   value,
+				Source: models.RateSourceECB,
+			})
+		}
+	}
+
+	return rates, nil
+}
+
+// UpdateBaseCurrency handler untuk mengganti base currency user. Budget dan
+// report setelahnya dihitung dalam currency ini. Ditolak dengan 409 selama
+// user masih punya budget, template, goal, loan, bill atau rule bernominal,
+// karena nominalnya disimpan tanpa currency.
+func UpdateBaseCurrency(c *gin.Context) {
+	var req BaseCurrencyRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	currency := models.NormalizeCurrency(req.BaseCurrency, "")
+	if !models.IsValidCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "base_currency must be a 3-letter currency code",
+		})
+		return
+	}
+
+	if err := models.ChangeBaseCurrency(c.Request.Context(), userID, currency); err != nil {
+		var inUse *models.BaseCurrencyInUseError
+		if errors.As(err, &inUse) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Base currency in use",
+				"message": inUse.Error(),
+				"amounts": inUse.Amounts,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to update base currency",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRateDefaultResponse{
+		Error:   false,
+		Message: "Base currency updated to " + currency,
+	})
+}
@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GoalRequest struct {
+	CategoryID   uint         `json:"category_id" binding:"required"`
+	Name         string       `json:"name" binding:"required,max=100"`
+	TargetAmount money.Amount `json:"target_amount" binding:"required,gt=0"`
+	TargetDate   string       `json:"target_date" binding:"required"`
+}
+
+type GoalContributionRequest struct {
+	Amount          money.Amount `json:"amount" binding:"required,gt=0"`
+	Remarks         string       `json:"remarks" binding:"max=255"`
+	TransactionDate string       `json:"transaction_date"`
+}
+
+type GoalDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type GoalIndexResponse struct {
+	Error   bool                `json:"error"`
+	Message string              `json:"message"`
+	Data    []models.PublicGoal `json:"data"`
+}
+
+type GoalFetchResponse struct {
+	Error   bool              `json:"error"`
+	Message string            `json:"message"`
+	Data    models.PublicGoal `json:"data"`
+}
+
+type GoalProgressResponse struct {
+	Error   bool                `json:"error"`
+	Message string              `json:"message"`
+	Data    models.GoalProgress `json:"data"`
+}
+
+// defaultGoalProjectionMonths adalah jumlah bulan kontribusi terakhir yang
+// dipakai untuk proyeksi jika query `months` tidak diisi
+const defaultGoalProjectionMonths = 3
+
+// findUserGoal mengambil goal milik user, menulis response error jika gagal
+func findUserGoal(c *gin.Context, userID uint) (models.Goal, bool) {
+	var goal models.Goal
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Goal ID!",
+		})
+		return goal, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Goal not found",
+				"message": "Goal no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch goal",
+			})
+		}
+		return goal, false
+	}
+
+	return goal, true
+}
+
+// IndexGoal handler untuk list semua goal milik user
+func IndexGoal(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var goals []models.Goal
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("target_date ASC").Find(&goals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch goals",
+		})
+		return
+	}
+
+	data := make([]models.PublicGoal, 0, len(goals))
+	for _, goal := range goals {
+		data = append(data, goal.ToPublicGoal())
+	}
+
+	c.JSON(http.StatusOK, GoalIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    data,
+	})
+}
+
+// CreateGoal handler untuk membuat goal tabungan baru
+func CreateGoal(c *gin.Context) {
+	var req GoalRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	targetDate, err := parseDate(req.TargetDate, models.GetUserCalendar(userID).Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "target_date " + err.Error(),
+		})
+		return
+	}
+	targetDate = calendarDate(targetDate)
+
+	if !userOwnsCategory(userID, req.CategoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Category not found",
+		})
+		return
+	}
+
+	goal := models.Goal{
+		UserID:       userID,
+		CategoryID:   req.CategoryID,
+		Name:         strings.TrimSpace(req.Name),
+		TargetAmount: req.TargetAmount,
+		TargetDate:   targetDate,
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Goal creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, GoalFetchResponse{
+		Error:   false,
+		Message: "Goal creation successful",
+		Data:    goal.ToPublicGoal(),
+	})
+}
+
+// GetGoalByID handler untuk mengambil detail goal
+func GetGoalByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	goal, ok := findUserGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, GoalFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    goal.ToPublicGoal(),
+	})
+}
+
+// UpdateGoal handler untuk mengubah target goal
+func UpdateGoal(c *gin.Context) {
+	var req GoalRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	goal, ok := findUserGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	targetDate, err := parseDate(req.TargetDate, models.GetUserCalendar(userID).Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "target_date " + err.Error(),
+		})
+		return
+	}
+	targetDate = calendarDate(targetDate)
+
+	if !userOwnsCategory(userID, req.CategoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Category not found",
+		})
+		return
+	}
+
+	goal.CategoryID = req.CategoryID
+	goal.Name = strings.TrimSpace(req.Name)
+	goal.TargetAmount = req.TargetAmount
+	goal.TargetDate = targetDate
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating goal!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GoalFetchResponse{
+		Error:   false,
+		Message: "Goal successfully updated",
+		Data:    goal.ToPublicGoal(),
+	})
+}
+
+// DeleteGoalByID handler untuk menghapus goal. Transaction kontribusi tidak
+// ikut terhapus karena tetap tercatat di category-nya.
+func DeleteGoalByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	goal, ok := findUserGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Delete(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete goal!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GoalDefaultResponse{
+		Error:   false,
+		Message: "Goal deletion successful",
+	})
+}
+
+// ContributeGoal handler untuk mencatat kontribusi ke goal sebagai transaction
+// di category goal tersebut
+func ContributeGoal(c *gin.Context) {
+	var req GoalContributionRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	goal, ok := findUserGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	transactionDate := now
+	if req.TransactionDate != "" {
+		parsedTime, err := parseDateTime(req.TransactionDate, models.GetUserCalendar(userID).Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "transaction_date " + err.Error(),
+			})
+			return
+		}
+		transactionDate = parsedTime
+	}
+
+	remarks := strings.TrimSpace(req.Remarks)
+	if remarks == "" {
+		remarks = "Contribution to " + goal.Name
+	}
+
+	contribution := models.Transaction{
+		UserID:          userID,
+		CategoryID:      goal.CategoryID,
+		Amount:          req.Amount,
+		Currency:        models.GetBaseCurrency(userID),
+		Type:            models.TransactionTypeTransfer,
+		Remarks:         remarks,
+		TransactionDate: transactionDate,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&contribution).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Transaction creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	events.Publish(userID, events.TransactionCreated, contribution.ToPublicTransaction())
+
+	c.JSON(http.StatusCreated, GoalDefaultResponse{
+		Error:   false,
+		Message: "Goal contribution successful",
+	})
+}
+
+// GetGoalProgress handler untuk melihat progress, kontribusi bulanan yang
+// dibutuhkan dan proyeksi tanggal tercapainya goal
+func GetGoalProgress(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	goal, ok := findUserGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	months, err := strconv.Atoi(c.DefaultQuery("months", strconv.Itoa(defaultGoalProjectionMonths)))
+	if err != nil || months < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "months must be a positive number",
+		})
+		return
+	}
+
+	progress, err := goal.GetProgress(months, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to calculate goal progress",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GoalProgressResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    progress,
+	})
+}
@@ -0,0 +1,524 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/alerts"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LoanRequest struct {
+	CategoryID   uint         `json:"category_id" binding:"required"`
+	Name         string       `json:"name" binding:"required,max=100"`
+	Principal    money.Amount `json:"principal" binding:"required,gt=0"`
+	InterestRate float64      `json:"interest_rate" binding:"min=0,max=100"`
+	TermMonths   uint         `json:"term_months" binding:"required,min=1,max=600"`
+	PaymentDay   uint         `json:"payment_day" binding:"required,min=1,max=31"`
+	StartDate    string       `json:"start_date" binding:"required"`
+}
+
+type LoanPaymentRequest struct {
+	Period          uint   `json:"period" binding:"required"`
+	TransactionID   uint   `json:"transaction_id"`
+	Remarks         string `json:"remarks" binding:"max=255"`
+	TransactionDate string `json:"transaction_date"`
+}
+
+type LoanDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type LoanIndexResponse struct {
+	Error   bool          `json:"error"`
+	Message string        `json:"message"`
+	Data    []models.Loan `json:"data"`
+}
+
+type LoanFetchResponse struct {
+	Error   bool        `json:"error"`
+	Message string      `json:"message"`
+	Data    models.Loan `json:"data"`
+}
+
+type LoanScheduleResponse struct {
+	Error   bool                  `json:"error"`
+	Message string                `json:"message"`
+	Data    []models.LoanSchedule `json:"data"`
+}
+
+type LoanSummaryResponse struct {
+	Error   bool               `json:"error"`
+	Message string             `json:"message"`
+	Data    models.LoanSummary `json:"data"`
+}
+
+// findUserLoan mengambil loan milik user, menulis response error jika gagal
+func findUserLoan(c *gin.Context, userID uint) (models.Loan, bool) {
+	var loan models.Loan
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Loan ID!",
+		})
+		return loan, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", loanID, userID).First(&loan).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Loan not found",
+				"message": "Loan no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch loan",
+			})
+		}
+		return loan, false
+	}
+
+	return loan, true
+}
+
+// bindLoanRequest memvalidasi request dan mengisi field loan dari request
+func bindLoanRequest(c *gin.Context, userID uint, loan *models.Loan) bool {
+	var req LoanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	startDate, err := parseDate(req.StartDate, models.GetUserCalendar(userID).Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "start_date " + err.Error(),
+		})
+		return false
+	}
+
+	if !userOwnsCategory(userID, req.CategoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "Category not found",
+		})
+		return false
+	}
+
+	loan.UserID = userID
+	loan.CategoryID = req.CategoryID
+	loan.Name = strings.TrimSpace(req.Name)
+	loan.Principal = req.Principal
+	loan.InterestRate = req.InterestRate
+	loan.TermMonths = req.TermMonths
+	loan.PaymentDay = req.PaymentDay
+	loan.StartDate = calendarDate(startDate)
+	return true
+}
+
+// IndexLoan handler untuk list semua loan milik user
+func IndexLoan(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var loans []models.Loan
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("start_date ASC").Find(&loans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch loans",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    loans,
+	})
+}
+
+// CreateLoan handler untuk membuat loan baru beserta jadwal amortisasinya
+func CreateLoan(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var loan models.Loan
+	if !bindLoanRequest(c, userID, &loan) {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		loan.ID = 0
+		if err := tx.Create(&loan).Error; err != nil {
+			return err
+		}
+
+		schedule := loan.BuildSchedule()
+		return tx.Create(&schedule).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Loan creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, LoanFetchResponse{
+		Error:   false,
+		Message: "Loan creation successful",
+		Data:    loan,
+	})
+}
+
+// GetLoanByID handler untuk mengambil detail loan
+func GetLoanByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    loan,
+	})
+}
+
+// UpdateLoan handler untuk mengubah term loan. Jadwal amortisasi dibuat ulang,
+// sehingga hanya boleh dilakukan sebelum ada pembayaran yang di-link.
+func UpdateLoan(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	var paid int64
+	database.DB.WithContext(c.Request.Context()).Model(&models.LoanSchedule{}).Where("loan_id = ? AND transaction_id IS NOT NULL", loan.ID).Count(&paid)
+	if paid > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Loan has payments",
+			"message": "Loan terms cannot be changed after payments have been recorded",
+		})
+		return
+	}
+
+	if !bindLoanRequest(c, userID, &loan) {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Save(&loan).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("loan_id = ?", loan.ID).Delete(&models.LoanSchedule{}).Error; err != nil {
+			return err
+		}
+
+		schedule := loan.BuildSchedule()
+		return tx.Create(&schedule).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating loan!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanFetchResponse{
+		Error:   false,
+		Message: "Loan successfully updated",
+		Data:    loan,
+	})
+}
+
+// DeleteLoanByID handler untuk menghapus loan beserta jadwalnya. Transaction
+// pembayaran tetap tersimpan.
+func DeleteLoanByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Where("loan_id = ?", loan.ID).Delete(&models.LoanSchedule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&loan).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete loan!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanDefaultResponse{
+		Error:   false,
+		Message: "Loan deletion successful",
+	})
+}
+
+// GetLoanSchedule handler untuk mengambil jadwal amortisasi lengkap
+func GetLoanSchedule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	var schedule []models.LoanSchedule
+	if err := database.DB.WithContext(c.Request.Context()).Where("loan_id = ?", loan.ID).Order("period ASC").Find(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch loan schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanScheduleResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    schedule,
+	})
+}
+
+// PayLoan handler untuk me-link pembayaran cicilan ke baris jadwal. Jika
+// transaction_id tidak diisi, transaction baru dibuat sebesar cicilan periode
+// tersebut di category loan.
+func PayLoan(c *gin.Context) {
+	var req LoanPaymentRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var row models.LoanSchedule
+	if err := database.DB.WithContext(c.Request.Context()).Where("loan_id = ? AND period = ?", loan.ID, req.Period).First(&row).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Schedule not found",
+			"message": "Loan schedule period does not exist",
+		})
+		return
+	}
+
+	if row.TransactionID != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Already paid",
+			"message": "This period is already linked to a transaction",
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	var payment models.Transaction
+
+	if req.TransactionID > 0 {
+		if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", req.TransactionID, userID).First(&payment).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Transaction not found",
+				"message": "Transaction no longer exists",
+			})
+			return
+		}
+	} else {
+		transactionDate := now
+		if req.TransactionDate != "" {
+			parsedTime, err := parseDateTime(req.TransactionDate, models.GetUserCalendar(userID).Location)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Validation failed",
+					"message": "transaction_date " + err.Error(),
+				})
+				return
+			}
+			transactionDate = parsedTime
+		}
+
+		remarks := strings.TrimSpace(req.Remarks)
+		if remarks == "" {
+			remarks = loan.Name + " installment " + strconv.Itoa(int(row.Period)) + "/" + strconv.Itoa(int(loan.TermMonths))
+		}
+
+		payment = models.Transaction{
+			UserID:          userID,
+			CategoryID:      loan.CategoryID,
+			Amount:          row.Payment,
+			Currency:        models.GetBaseCurrency(userID),
+			Type:            models.TransactionTypeExpense,
+			Remarks:         remarks,
+			TransactionDate: transactionDate,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+
+	createPayment := payment.ID == 0
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if createPayment {
+			payment.ID = 0
+			if err := tx.Create(&payment).Error; err != nil {
+				return err
+			}
+		}
+
+		row.TransactionID = &payment.ID
+		row.PaidAt = &payment.TransactionDate
+		return tx.Save(&row).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Loan payment failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	alerts.EvaluateAsync(userID, payment.CategoryID, payment.TransactionDate)
+	if req.TransactionID == 0 {
+		events.Publish(userID, events.TransactionCreated, payment.ToPublicTransaction())
+	}
+
+	c.JSON(http.StatusCreated, LoanDefaultResponse{
+		Error:   false,
+		Message: "Loan payment recorded",
+	})
+}
+
+// GetLoanSummary handler untuk sisa pokok, bunga yang sudah dibayar dan
+// tanggal lunas. Query `extra` berisi daftar extra payment bulanan yang
+// dipisahkan koma, contoh: ?extra=500000,1000000
+func GetLoanSummary(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	loan, ok := findUserLoan(c, userID)
+	if !ok {
+		return
+	}
+
+	var extras []money.Amount
+	if param := c.Query("extra"); param != "" {
+		for _, raw := range strings.Split(param, ",") {
+			extra, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Validation failed",
+					"message": "extra must be a comma separated list of amounts",
+				})
+				return
+			}
+			extras = append(extras, money.Amount(extra))
+		}
+	}
+
+	var schedule []models.LoanSchedule
+	if err := database.DB.WithContext(c.Request.Context()).Where("loan_id = ?", loan.ID).Order("period ASC").Find(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch loan schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoanSummaryResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    loan.GetSummary(schedule, extras),
+	})
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type NotificationIndexResponse struct {
+	Error       bool                  `json:"error"`
+	Message     string                `json:"message"`
+	UnreadCount int64                 `json:"unread_count"`
+	Data        []models.Notification `json:"data"`
+}
+
+// IndexNotification handler untuk inbox notifikasi user. Query `status`
+// bisa berisi unread, read atau all (default).
+func IndexNotification(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	query := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID)
+	switch c.DefaultQuery("status", "all") {
+	case "unread":
+		query = query.Where("read_at IS NULL")
+	case "read":
+		query = query.Where("read_at IS NOT NULL")
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "status must be one of unread, read, all",
+		})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("id DESC").Limit(100).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch notifications",
+		})
+		return
+	}
+
+	var unread int64
+	database.DB.WithContext(c.Request.Context()).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread)
+
+	c.JSON(http.StatusOK, NotificationIndexResponse{
+		Error:       false,
+		Message:     "Data loaded!",
+		UnreadCount: unread,
+		Data:        notifications,
+	})
+}
+
+// ReadNotification handler untuk menandai satu notifikasi sebagai read
+func ReadNotification(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Notification ID!",
+		})
+		return
+	}
+
+	result := database.DB.WithContext(c.Request.Context()).Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to update notification",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationDefaultResponse{
+		Error:   false,
+		Message: "Notification marked as read",
+	})
+}
+
+// ReadAllNotification handler untuk menandai semua notifikasi sebagai read
+func ReadAllNotification(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to update notifications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationDefaultResponse{
+		Error:   false,
+		Message: "All notifications marked as read",
+	})
+}
@@ -0,0 +1,676 @@
+package controllers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PayeeRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type PayeeAliasRequest struct {
+	Pattern   string `json:"pattern" binding:"required,max=255"`
+	MatchType string `json:"match_type" binding:"omitempty,oneof=exact contains prefix regex"`
+}
+
+type PayeeMergeRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+}
+
+// PayeeSplitRequest memindahkan transaction/alias ke payee lain. Isi
+// target_payee_id untuk payee yang sudah ada atau name untuk payee baru.
+type PayeeSplitRequest struct {
+	TargetPayeeID  uint   `json:"target_payee_id"`
+	Name           string `json:"name" binding:"max=100"`
+	TransactionIDs []uint `json:"transaction_ids"`
+	AliasIDs       []uint `json:"alias_ids"`
+}
+
+type PayeeDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type PayeeIndexResponse struct {
+	Error   bool                 `json:"error"`
+	Message string               `json:"message"`
+	Data    []models.PublicPayee `json:"data"`
+}
+
+type PayeeFetchResponse struct {
+	Error   bool               `json:"error"`
+	Message string             `json:"message"`
+	Data    models.PublicPayee `json:"data"`
+}
+
+type PayeeAliasFetchResponse struct {
+	Error   bool              `json:"error"`
+	Message string            `json:"message"`
+	Data    models.PayeeAlias `json:"data"`
+}
+
+type PayeeReportResponse struct {
+	Error   bool                   `json:"error"`
+	Message string                 `json:"message"`
+	Data    []models.PayeeSpending `json:"data"`
+}
+
+type PayeeNormalizeResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Scanned int    `json:"scanned"`
+	Updated int    `json:"updated"`
+}
+
+// findUserPayee mengambil payee milik user, menulis response error jika gagal
+func findUserPayee(c *gin.Context, userID uint) (models.Payee, bool) {
+	var payee models.Payee
+
+	payeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Payee ID!",
+		})
+		return payee, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", payeeID, userID).First(&payee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Payee not found",
+				"message": "Payee no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch payee",
+			})
+		}
+		return payee, false
+	}
+
+	return payee, true
+}
+
+// payeeNameTaken mengecek apakah user sudah punya payee lain dengan nama yang sama
+func payeeNameTaken(userID uint, name string, exceptID uint) bool {
+	var count int64
+	database.DB.Model(&models.Payee{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).Count(&count)
+	return count > 0
+}
+
+// IndexPayee handler untuk list payee milik user beserta aliasnya
+func IndexPayee(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var payees []models.Payee
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("name ASC").Find(&payees).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch payees",
+		})
+		return
+	}
+
+	var aliases []models.PayeeAlias
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("id ASC").Find(&aliases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch payee aliases",
+		})
+		return
+	}
+
+	aliasesByPayee := map[uint][]models.PayeeAlias{}
+	for _, alias := range aliases {
+		aliasesByPayee[alias.PayeeID] = append(aliasesByPayee[alias.PayeeID], alias)
+	}
+
+	data := make([]models.PublicPayee, 0, len(payees))
+	for _, payee := range payees {
+		data = append(data, payee.ToPublicPayee(aliasesByPayee[payee.ID]))
+	}
+
+	c.JSON(http.StatusOK, PayeeIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    data,
+	})
+}
+
+// CreatePayee handler untuk membuat payee baru
+func CreatePayee(c *gin.Context) {
+	var req PayeeRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	payee := models.Payee{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+	}
+
+	if payeeNameTaken(userID, payee.Name, 0) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Payee already exists",
+			"message": "Payee with this name already exists",
+		})
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&payee).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Payee creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, PayeeFetchResponse{
+		Error:   false,
+		Message: "Payee creation successful",
+		Data:    payee.ToPublicPayee(nil),
+	})
+}
+
+// GetPayeeByID handler untuk mengambil detail payee beserta aliasnya
+func GetPayeeByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	var aliases []models.PayeeAlias
+	if err := database.DB.WithContext(c.Request.Context()).Where("payee_id = ?", payee.ID).Order("id ASC").Find(&aliases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch payee aliases",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    payee.ToPublicPayee(aliases),
+	})
+}
+
+// UpdatePayee handler untuk mengganti nama payee
+func UpdatePayee(c *gin.Context) {
+	var req PayeeRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	payee.Name = strings.TrimSpace(req.Name)
+	if payeeNameTaken(userID, payee.Name, payee.ID) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Payee already exists",
+			"message": "Payee with this name already exists, merge them instead",
+		})
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&payee).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating payee!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeFetchResponse{
+		Error:   false,
+		Message: "Payee successfully updated",
+		Data:    payee.ToPublicPayee(nil),
+	})
+}
+
+// DeletePayeeByID handler untuk menghapus payee. Transaction yang terkait
+// tetap ada tanpa payee.
+func DeletePayeeByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Transaction{}).Where("user_id = ? AND payee_id = ?", userID, payee.ID).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ?", payee.ID).Delete(&models.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&payee).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete payee!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeDefaultResponse{
+		Error:   false,
+		Message: "Payee deletion successful",
+	})
+}
+
+// CreatePayeeAlias handler untuk menambah alias ke payee
+func CreatePayeeAlias(c *gin.Context) {
+	var req PayeeAliasRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if req.MatchType == "" {
+		req.MatchType = models.PayeeMatchContains
+	}
+	req.Pattern = strings.TrimSpace(req.Pattern)
+
+	if req.MatchType == models.PayeeMatchRegex {
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "pattern is not a valid regular expression",
+			})
+			return
+		}
+	} else if models.NormalizeDescription(req.Pattern) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "pattern must contain at least one word without digits",
+		})
+		return
+	}
+
+	alias := models.PayeeAlias{
+		UserID:    userID,
+		PayeeID:   payee.ID,
+		Pattern:   req.Pattern,
+		MatchType: req.MatchType,
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&alias).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Alias creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, PayeeAliasFetchResponse{
+		Error:   false,
+		Message: "Alias creation successful",
+		Data:    alias,
+	})
+}
+
+// DeletePayeeAliasByID handler untuk menghapus alias
+func DeletePayeeAliasByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	aliasID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Alias ID!",
+		})
+		return
+	}
+
+	result := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", aliasID, userID).Delete(&models.PayeeAlias{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete alias!",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Alias not found",
+			"message": "Alias no longer exists",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeDefaultResponse{
+		Error:   false,
+		Message: "Alias deletion successful",
+	})
+}
+
+// MergePayee handler untuk menggabungkan payee lain ke payee ini
+func MergePayee(c *gin.Context) {
+	var req PayeeMergeRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := models.MergePayees(c.Request.Context(), payee, req.SourceIDs); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Payee not found",
+				"message": "No payee to merge",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed",
+				"message": "Unable to merge payees!",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeDefaultResponse{
+		Error:   false,
+		Message: "Payee merge successful",
+	})
+}
+
+// SplitPayee handler untuk memindahkan sebagian transaction dan alias payee
+// ini ke payee lain atau payee baru
+func SplitPayee(c *gin.Context) {
+	var req PayeeSplitRequest
+
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	payee, ok := findUserPayee(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if len(req.TransactionIDs) == 0 && len(req.AliasIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "transaction_ids or alias_ids is required",
+		})
+		return
+	}
+
+	var target models.Payee
+	req.Name = strings.TrimSpace(req.Name)
+	switch {
+	case req.TargetPayeeID != 0:
+		if req.TargetPayeeID == payee.ID || database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", req.TargetPayeeID, userID).First(&target).Error != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "Target payee not found",
+			})
+			return
+		}
+	case req.Name != "":
+		if payeeNameTaken(userID, req.Name, 0) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Payee already exists",
+				"message": "Payee with this name already exists, use target_payee_id instead",
+			})
+			return
+		}
+		target = models.Payee{UserID: userID, Name: req.Name}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "target_payee_id or name is required",
+		})
+		return
+	}
+
+	moved, err := models.SplitPayee(c.Request.Context(), payee, &target, req.TransactionIDs, req.AliasIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to split payee!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeFetchResponse{
+		Error:   false,
+		Message: strconv.FormatInt(moved, 10) + " transaction(s) moved",
+		Data:    target.ToPublicPayee(nil),
+	})
+}
+
+// NormalizePayee handler untuk mengisi payee pada transaction yang belum
+// punya payee berdasarkan alias dan nama payee saat ini
+func NormalizePayee(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	resolver, err := models.NewPayeeResolver(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch payees",
+		})
+		return
+	}
+
+	scanned, updated := 0, 0
+	var batch []models.Transaction
+	err = database.DB.WithContext(c.Request.Context()).
+		Select("id", "remarks").
+		Where("user_id = ? AND payee_id IS NULL", userID).
+		FindInBatches(&batch, ruleBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, transaction := range batch {
+				scanned++
+				payeeID := resolver.Resolve(transaction.Remarks)
+				if payeeID == nil {
+					continue
+				}
+				if err := database.DB.WithContext(c.Request.Context()).Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("payee_id", *payeeID).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to normalize transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeNormalizeResponse{
+		Error:   false,
+		Message: "Payee normalization completed",
+		Scanned: scanned,
+		Updated: updated,
+	})
+}
+
+// GetPayeeReport handler untuk laporan spending per payee. Default periode
+// budget berjalan user, tanggal dibaca di zona waktu user. Bisa diatur dengan query start & end (YYYY-MM-DD).
+func GetPayeeReport(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	calendar := models.GetUserCalendar(userID)
+	period := calendar.PeriodOf(time.Now())
+	from, to := period.Start, period.End
+
+	if start := c.Query("start"); start != "" {
+		parsed, err := parseDate(start, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start " + err.Error(),
+			})
+			return
+		}
+		from = parsed
+	}
+	if end := c.Query("end"); end != "" {
+		parsed, err := parseDate(end, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "end " + err.Error(),
+			})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	report, err := models.GetPayeeSpending(userID, from.UTC(), to.UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to calculate payee spending",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PayeeReportResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    report,
+	})
+}
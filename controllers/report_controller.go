@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultReportPeriods adalah jumlah periode report jika start tidak diisi
+	defaultReportPeriods = 12
+
+	// defaultTopLimit dan maxTopLimit mengatur jumlah baris report top
+	defaultTopLimit = 5
+	maxTopLimit     = 50
+)
+
+type CashFlowReportResponse struct {
+	Error   bool                   `json:"error"`
+	Message string                 `json:"message"`
+	Data    []models.CashFlowPoint `json:"data"`
+}
+
+type CategoryTrendReportResponse struct {
+	Error   bool                   `json:"error"`
+	Message string                 `json:"message"`
+	Data    []models.CategoryTrend `json:"data"`
+}
+
+type HeatmapReportResponse struct {
+	Error   bool                `json:"error"`
+	Message string              `json:"message"`
+	Data    []models.HeatmapDay `json:"data"`
+}
+
+type TopReport struct {
+	Period     models.BudgetPeriod       `json:"period"`
+	Categories []models.CategorySpending `json:"categories"`
+	Payees     []models.PayeeSpending    `json:"payees"`
+}
+
+type TopReportResponse struct {
+	Error   bool      `json:"error"`
+	Message string    `json:"message"`
+	Data    TopReport `json:"data"`
+}
+
+// GetCashFlowReport handler untuk pemasukan, spending dan arus kas bersih per
+// periode budget user. Rentang diatur dengan query start & end (YYYY-MM),
+// default 12 periode terakhir.
+func GetCashFlowReport(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	periods, ok := queryReportPeriods(c, models.GetUserCalendar(userID))
+	if !ok {
+		return
+	}
+
+	report, err := models.GetCashFlow(userID, periods)
+	if err != nil {
+		respondReportError(c, err, "Failed to calculate cash flow")
+		return
+	}
+
+	c.JSON(http.StatusOK, CashFlowReportResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    report,
+	})
+}
+
+// GetCategoryTrendReport handler untuk spending per category per periode
+// budget beserta perubahan month-over-month dan year-over-year. Rentang sama
+// seperti GetCashFlowReport, bisa difilter dengan query category_id.
+func GetCategoryTrendReport(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var categoryID uint64
+	if value := c.Query("category_id"); value != "" {
+		var err error
+		if categoryID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "category_id must be a number",
+			})
+			return
+		}
+	}
+
+	calendar := models.GetUserCalendar(userID)
+	periods, ok := queryReportPeriods(c, calendar)
+	if !ok {
+		return
+	}
+
+	report, err := models.GetCategoryTrends(userID, calendar, periods, uint(categoryID))
+	if err != nil {
+		respondReportError(c, err, "Failed to calculate category trends")
+		return
+	}
+
+	c.JSON(http.StatusOK, CategoryTrendReportResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    report,
+	})
+}
+
+// GetSpendingHeatmapReport handler untuk spending per hari di zona waktu
+// user. Rentang diatur dengan query start & end (YYYY-MM-DD), default 365
+// hari terakhir, maksimal 366 hari.
+func GetSpendingHeatmapReport(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	calendar := models.GetUserCalendar(userID)
+	to := calendar.Today(time.Now())
+	from := to.AddDate(0, 0, -364)
+
+	if end := c.Query("end"); end != "" {
+		parsed, err := parseDate(end, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "end " + err.Error(),
+			})
+			return
+		}
+		to = parsed
+		from = to.AddDate(0, 0, -364)
+	}
+	if start := c.Query("start"); start != "" {
+		parsed, err := parseDate(start, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start " + err.Error(),
+			})
+			return
+		}
+		from = parsed
+	}
+
+	days := 0
+	for day := from; !day.After(to) && days <= models.MaxHeatmapDays; day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	if days == 0 || days > models.MaxHeatmapDays {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "end must not be before start and the range must be at most " + strconv.Itoa(models.MaxHeatmapDays) + " days",
+		})
+		return
+	}
+
+	report, err := models.GetSpendingHeatmap(userID, from, days)
+	if err != nil {
+		respondReportError(c, err, "Failed to calculate spending heatmap")
+		return
+	}
+
+	c.JSON(http.StatusOK, HeatmapReportResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    report,
+	})
+}
+
+// GetTopReport handler untuk category dan payee dengan spending terbesar
+// dalam satu periode budget (query month & year, default periode berjalan).
+// Jumlah baris diatur dengan query limit.
+func GetTopReport(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTopLimit)))
+	if err != nil || limit <= 0 || limit > maxTopLimit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "limit must be between 1 and " + strconv.Itoa(maxTopLimit),
+		})
+		return
+	}
+
+	period, ok := queryPeriod(c, userID)
+	if !ok {
+		return
+	}
+
+	categories, err := models.GetCategorySpending(userID, period.Start, period.End)
+	if err != nil {
+		respondReportError(c, err, "Failed to calculate category spending")
+		return
+	}
+	payees, err := models.GetPayeeSpending(userID, period.Start.UTC(), period.End.UTC())
+	if err != nil {
+		respondReportError(c, err, "Failed to calculate payee spending")
+		return
+	}
+
+	if len(categories) > limit {
+		categories = categories[:limit]
+	}
+	if len(payees) > limit {
+		payees = payees[:limit]
+	}
+
+	c.JSON(http.StatusOK, TopReportResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data: TopReport{
+			Period:     period,
+			Categories: categories,
+			Payees:     payees,
+		},
+	})
+}
+
+// queryReportPeriods membaca rentang periode dari query start & end
+// (YYYY-MM). Default end adalah periode berjalan dan start 11 periode
+// sebelumnya. Menulis response error jika query tidak valid.
+func queryReportPeriods(c *gin.Context, calendar models.UserCalendar) ([]models.BudgetPeriod, bool) {
+	current := calendar.PeriodOf(time.Now())
+	end := time.Date(int(current.Year), time.Month(current.Month), 1, 0, 0, 0, 0, time.UTC)
+
+	if value := c.Query("end"); value != "" {
+		parsed, err := time.Parse(monthLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "end must use YYYY-MM format",
+			})
+			return nil, false
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 1-defaultReportPeriods, 0)
+	if value := c.Query("start"); value != "" {
+		parsed, err := time.Parse(monthLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start must use YYYY-MM format",
+			})
+			return nil, false
+		}
+		start = parsed
+	}
+
+	count := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	if count < 1 || count > models.MaxReportPeriods {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "end must not be before start and the range must be at most " + strconv.Itoa(models.MaxReportPeriods) + " periods",
+		})
+		return nil, false
+	}
+
+	return calendar.Periods(uint(start.Year()), uint(start.Month()), count), true
+}
+
+// respondReportError menulis response error dari perhitungan report.
+// Transaction dalam currency lain tanpa exchange rate tidak bisa dihitung.
+func respondReportError(c *gin.Context, err error, message string) {
+	if errors.Is(err, models.ErrMissingExchangeRate) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Missing exchange rate",
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Database error",
+		"message": message,
+	})
+}
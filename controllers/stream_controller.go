@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamPingInterval menjaga koneksi SSE tetap hidup melewati proxy
+	streamPingInterval = 25 * time.Second
+
+	// streamWriteTimeout menggantikan WriteTimeout server untuk setiap write
+	// karena koneksi SSE berumur panjang
+	streamWriteTimeout = 10 * time.Second
+)
+
+// StreamEvents handler untuk Server-Sent Events. Setiap create/update/delete
+// transaction, category dan budget milik user dikirim sebagai SSE event.
+// Client yang reconnect mengirim header Last-Event-ID (atau query
+// last_event_id) untuk menerima event yang terlewat. ID yang tidak dikenal,
+// misalnya dari sebelum server restart, dibalas event reset.
+func StreamEvents(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	lastEventHeader := c.GetHeader("Last-Event-ID")
+	if lastEventHeader == "" {
+		lastEventHeader = c.Query("last_event_id")
+	}
+
+	sub, missed, complete := events.Default.Subscribe(userID, strings.TrimSpace(lastEventHeader))
+	defer events.Default.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	write := func(chunk string) bool {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprint(c.Writer, chunk); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	writeEvent := func(event events.Event) bool {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return true
+		}
+		return write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data))
+	}
+
+	if !write("retry: 3000\n\n") {
+		return
+	}
+
+	// Event setelah Last-Event-ID sudah tidak ada di history atau ID-nya
+	// tidak dikenal (misalnya setelah server restart), client perlu reload
+	// data secara penuh
+	if !complete && !write("event: reset\ndata: {}\n\n") {
+		return
+	}
+
+	for _, event := range missed {
+		if !writeEvent(event) {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-sub.C:
+			if !writeEvent(event) {
+				return
+			}
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		}
+	}
+}
@@ -1,36 +1,57 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
-	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/alerts"
+	"ashborn.id/moniplan/categorizer"
+	"ashborn.id/moniplan/events"
 	"ashborn.id/moniplan/middlewares"
 	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+	"ashborn.id/moniplan/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type TransactionRequest struct {
-	TransactionID   uint   `json:"transaction_id"`
-	CategoryID      uint   `json:"category_id"`
-	Amount          uint   `json:"amount" binding:"required"`
-	Type            string `json:"type" binding:"required,max=100"`
-	Remarks         string `json:"remarks" binding:"required"`
-	TransactionDate string `json:"transaction_date"`
+	TransactionID   uint         `json:"transaction_id"`
+	CategoryID      uint         `json:"category_id"`
+	PayeeID         *uint        `json:"payee_id"`
+	Amount          money.Amount `json:"amount" binding:"required"`
+	Currency        string       `json:"currency" binding:"omitempty,len=3"`
+	Type            string       `json:"type" binding:"required,oneof=income expense transfer refund adjustment"`
+	Remarks         string       `json:"remarks" binding:"required"`
+	Tags            string       `json:"tags" binding:"max=255"`
+	TransactionDate string       `json:"transaction_date"`
 }
 
 type UpdateTransactionRequest struct {
-	TransactionID   uint   `json:"transaction_id"`
-	CategoryID      uint   `json:"category_id"`
-	Amount          uint   `json:"amount"`
-	Type            string `json:"type" binding:"max=100"`
-	Remarks         string `json:"remarks" binding:"max=255"`
-	TransactionDate string `json:"transaction_date"`
+	TransactionID   uint         `json:"transaction_id"`
+	CategoryID      uint         `json:"category_id"`
+	PayeeID         *uint        `json:"payee_id"`
+	Amount          money.Amount `json:"amount"`
+	Currency        string       `json:"currency" binding:"omitempty,len=3"`
+	Type            string       `json:"type" binding:"omitempty,oneof=income expense transfer refund adjustment"`
+	Remarks         string       `json:"remarks" binding:"max=255"`
+	Tags            string       `json:"tags" binding:"max=255"`
+	TransactionDate string       `json:"transaction_date"`
 }
 
+type TransactionSuggestResponse struct {
+	Error   bool                     `json:"error"`
+	Message string                   `json:"message"`
+	Data    []categorizer.Suggestion `json:"data"`
+}
+
+// defaultSuggestionLimit adalah jumlah category yang disarankan jika query
+// `limit` tidak diisi
+const defaultSuggestionLimit = 3
+
 type TransactionDefaultResponse struct {
 	Error   bool   `json:"error"`
 	Message string `json:"message"`
@@ -39,6 +60,7 @@ type TransactionDefaultResponse struct {
 type TransactionIndexResponse struct {
 	Error   bool                               `json:"error"`
 	Message string                             `json:"message"`
+	Period  models.BudgetPeriod                `json:"period"`
 	Data    []models.TransactionCategoryBudget `json:"data"`
 }
 
@@ -59,44 +81,36 @@ func IndexTransaction(c *gin.Context) {
 		return
 	}
 
-	now := time.Now() // current local time
-	currentYear, currentMonth, _ := now.Date()
-	current_year := currentYear
-	current_month := int(currentMonth)
-	current_category_id := 0
-
-	param_month := c.DefaultQuery("month", strconv.Itoa(current_month))
-	param_year := c.DefaultQuery("year", strconv.Itoa(current_year))
-	param_category_id := c.DefaultQuery("category_id", strconv.Itoa(current_category_id))
-
-	// Fetch user data dari database
-	var transactions []models.TransactionCategoryBudget
-	query := database.DB.
-		Table("transactions t").
-		Select("t.id, t.user_id, t.category_id, t.amount, t.type, t.remarks, DATE_FORMAT(t.transaction_date, '%W, %d %M %Y %H:%i') AS transaction_date, t.created_at, t.updated_at, c.name as category_name").
-		Joins("JOIN categories c ON c.id = t.category_id").
-		Where("t.user_id = ? AND YEAR(t.transaction_date) = ? AND MONTH(t.transaction_date) = ?", userID, param_year, param_month)
-
-	if param_category_id != "0" {
-		query = query.Where("t.category_id = ?", param_category_id)
+	// Default ke periode berjalan user dan semua category
+	period, ok := queryPeriod(c, userID)
+	if !ok {
+		return
 	}
 
-	err := query.
-		Order("t.id DESC").
-		Scan(&transactions).Error
+	filter := repository.TransactionFilter{
+		UserID: userID,
+		Year:   int(period.Year),
+		Month:  int(period.Month),
+	}
 
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "Transaction not found",
-				"message": "Transaction no longer exists",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Database error",
-				"message": "Failed to fetch transaction",
+	if value := c.Query("category_id"); value != "" {
+		categoryID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "category_id must be a number",
 			})
+			return
 		}
+		filter.CategoryID = uint(categoryID)
+	}
+
+	transactions, err := Services.Transactions.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch transaction",
+		})
 		return
 	}
 
@@ -104,41 +118,55 @@ func IndexTransaction(c *gin.Context) {
 	c.JSON(http.StatusCreated, TransactionIndexResponse{
 		Error:   false,
 		Message: "Data loaded!",
+		Period:  period,
 		Data:    transactions,
 	})
 }
 
 func GetTransactionByID(c *gin.Context) {
-	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	var transaction models.Transaction
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
 
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Unauthorized",
-			"message": "Invalid Category ID!",
+			"message": "Invalid Transaction ID!",
 		})
 		return
-	} else {
-		if errTransaction := database.DB.First(&transaction, transactionID).Error; errTransaction != nil {
-			if errTransaction == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{
-					"error":   "Category not found",
-					"message": "Category no longer exists",
-				})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "Database error",
-					"message": "Failed to fetch category",
-				})
-			}
-			return
+	}
+
+	transaction, err := Services.Transactions.Get(c.Request.Context(), userID, uint(transactionID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Transaction not found",
+				"message": "Transaction no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch transaction",
+			})
 		}
+		return
+	}
+
+	// Tampilkan tanggal transaction di zona waktu user
+	if calendar, err := Services.Users.Calendar(c.Request.Context(), userID); err == nil {
+		transaction.TransactionDate = calendar.In(transaction.TransactionDate)
 	}
 
 	// Success response
 	c.JSON(http.StatusCreated, TransactionFetchResponse{
 		Error:   false,
-		Message: "Category & Budget creation successful",
+		Message: "Data loaded!",
 		Data:    transaction,
 	})
 }
@@ -163,36 +191,60 @@ func CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	layout := "2006-01-02 15:04:05"
-	jakartaOffset := 7 * 60 * 60 // 25200 seconds
-	location := time.FixedZone("WIB", jakartaOffset)
-	parsedTime, err := time.ParseInLocation(layout, req.TransactionDate, location)
+	calendar, err := Services.Users.Calendar(c.Request.Context(), userID)
 	if err != nil {
-		fmt.Println("Error loading location:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch user settings",
+		})
 		return
 	}
 
-	fmt.Println("parsedTime: ", parsedTime)
+	// Tanggal tanpa offset dibaca di zona waktu user, disimpan dalam UTC
+	parsedTime := time.Now().UTC()
+	if req.TransactionDate != "" {
+		parsedTime, err = parseDateTime(req.TransactionDate, calendar.Location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "transaction_date " + err.Error(),
+			})
+			return
+		}
+	}
 
+	// Currency, rule, payee dan saran category dilengkapi oleh service
 	newTransaction := models.Transaction{
 		UserID:          userID,
 		CategoryID:      req.CategoryID,
+		PayeeID:         req.PayeeID,
 		Amount:          req.Amount,
+		Currency:        req.Currency,
 		Type:            req.Type,
 		Remarks:         req.Remarks,
+		Tags:            req.Tags,
 		TransactionDate: parsedTime,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
 	}
 
-	if err := database.DB.Create(&newTransaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Transaction creation failed",
-			"message": err,
-		})
+	if err := Services.Transactions.Create(c.Request.Context(), &newTransaction); err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Transaction creation failed",
+				"message": err.Error(),
+			})
+		}
 		return
 	}
 
+	alerts.EvaluateAsync(userID, newTransaction.CategoryID, newTransaction.TransactionDate)
+	events.Publish(userID, events.TransactionCreated, newTransaction.ToPublicTransaction())
+
 	// Success response
 	c.JSON(http.StatusCreated, TransactionDefaultResponse{
 		Error:   false,
@@ -200,15 +252,71 @@ func CreateTransaction(c *gin.Context) {
 	})
 }
 
+// SuggestTransactionCategory handler untuk menyarankan category berdasarkan
+// remarks, dipelajari dari history transaction user
+func SuggestTransactionCategory(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	remarks := c.Query("remarks")
+	if remarks == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "remarks is required",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuggestionLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": "limit must be a positive number",
+		})
+		return
+	}
+
+	suggestions, err := categorizer.Default.Suggest(userID, remarks, c.Query("type"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to load transaction history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionSuggestResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    suggestions,
+	})
+}
+
 func UpdateTransaction(c *gin.Context) {
 	var req UpdateTransactionRequest
 
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
 	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Required Param",
 			"message": err.Error(),
 		})
+		return
 	}
 
 	// Validasi dan bind request body
@@ -220,61 +328,64 @@ func UpdateTransaction(c *gin.Context) {
 		return
 	}
 
-	fmt.Println("Transaction ID: ", transactionID)
-
-	now := time.Now()
-
-	// Cek apakah email sudah terdaftar
-	var existingTransaction models.Transaction
-	result := database.DB.First(&existingTransaction, transactionID)
-
-	if result.Error != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Transaction not found!",
-			"message": err,
-		})
-		return
+	changes := services.TransactionChanges{
+		CategoryID: req.CategoryID,
+		PayeeID:    req.PayeeID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Type:       req.Type,
+		Remarks:    req.Remarks,
+		Tags:       req.Tags,
 	}
 
-	existingTransaction.UpdatedAt = now
-
-	if req.CategoryID > 0 {
-		existingTransaction.CategoryID = req.CategoryID
-		result := database.DB.First(&existingTransaction, transactionID)
+	if req.TransactionDate != "" {
+		calendar, err := Services.Users.Calendar(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch user settings",
+			})
+			return
+		}
 
-		if result.Error != nil {
+		// Sama dengan CreateTransaction: dibaca di zona waktu user
+		transactionDate, err := parseDateTime(req.TransactionDate, calendar.Location)
+		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Transaction not found!",
-				"message": err,
+				"error":   "Validation failed",
+				"message": "transaction_date " + err.Error(),
 			})
 			return
 		}
+		changes.TransactionDate = &transactionDate
 	}
 
-	if req.Amount > 0 {
-		existingTransaction.Amount = req.Amount
-	}
-
-	if req.TransactionDate != "" {
-		layout := "2006-01-02 15:04:05"
-		transaction_date, _ := time.Parse(layout, req.TransactionDate)
-		existingTransaction.TransactionDate = transaction_date
-	}
-
-	if req.Remarks != "" {
-		existingTransaction.Remarks = req.Remarks
-	}
-
-	fmt.Println("Amount: ", req.Amount)
-
-	if err := database.DB.Updates(existingTransaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error in updating transaction!",
-			"message": err,
-		})
+	transaction, err := Services.Transactions.Update(c.Request.Context(), userID, uint(transactionID), changes)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Transaction not found!",
+				"message": "Transaction no longer exists",
+			})
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error in updating transaction!",
+				"message": err.Error(),
+			})
+		}
 		return
 	}
 
+	alerts.EvaluateAsync(transaction.UserID, transaction.CategoryID, transaction.TransactionDate)
+	events.Publish(transaction.UserID, events.TransactionUpdated, transaction.ToPublicTransaction())
+
 	// Success response
 	c.JSON(http.StatusCreated, CategoryDefaultResponse{
 		Error:   false,
@@ -283,6 +394,15 @@ func UpdateTransaction(c *gin.Context) {
 }
 
 func DeleteTransactionByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
 	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 
 	if err != nil {
@@ -293,7 +413,14 @@ func DeleteTransactionByID(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&models.Transaction{}, transactionID).Error; err != nil {
+	if err := Services.Transactions.Delete(c.Request.Context(), userID, uint(transactionID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Transaction not found!",
+				"message": "Transaction no longer exists",
+			})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"error":   "Failed",
 			"message": "Unable to delete transaction!",
@@ -301,9 +428,63 @@ func DeleteTransactionByID(c *gin.Context) {
 		return
 	}
 
+	events.Publish(userID, events.TransactionDeleted, gin.H{"id": transactionID})
+
 	// Success response
 	c.JSON(http.StatusCreated, TransactionDefaultResponse{
 		Error:   false,
 		Message: "Transaction deletion successful",
 	})
 }
+
+// RestoreTransaction handler untuk mengembalikan transaction dari trash
+func RestoreTransaction(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Transaction ID!",
+		})
+		return
+	}
+
+	transaction, err := models.RestoreTransaction(c.Request.Context(), userID, uint(transactionID))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Transaction not found",
+				"message": "Transaction is not in the trash",
+			})
+		case errors.Is(err, models.ErrCategoryDeleted):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Restore failed",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Restore failed",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	alerts.EvaluateAsync(userID, transaction.CategoryID, transaction.TransactionDate)
+	events.Publish(userID, events.TransactionRestored, transaction.ToPublicTransaction())
+
+	c.JSON(http.StatusOK, TransactionFetchResponse{
+		Error:   false,
+		Message: "Transaction restored",
+		Data:    transaction,
+	})
+}
@@ -0,0 +1,519 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/alerts"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TransactionRuleRequest struct {
+	Name            string        `json:"name" binding:"required,max=100"`
+	Priority        int           `json:"priority"`
+	Active          *bool         `json:"active"`
+	RemarksContains string        `json:"remarks_contains" binding:"max=255"`
+	RemarksRegex    string        `json:"remarks_regex" binding:"max=255"`
+	MinAmount       *money.Amount `json:"min_amount"`
+	MaxAmount       *money.Amount `json:"max_amount"`
+	Type            string        `json:"type" binding:"omitempty,oneof=income expense transfer refund adjustment"`
+	SetCategoryID   *uint         `json:"set_category_id"`
+	AddTags         string        `json:"add_tags" binding:"max=255"`
+	RenameRemarks   string        `json:"rename_remarks" binding:"max=255"`
+}
+
+// TransactionRuleRunRequest membatasi transaction history yang diproses oleh
+// dry run dan re-apply. Rule diisi untuk mencoba rule yang belum disimpan.
+type TransactionRuleRunRequest struct {
+	Start string                  `json:"start"`
+	End   string                  `json:"end"`
+	Rule  *TransactionRuleRequest `json:"rule"`
+}
+
+type TransactionRuleDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type TransactionRuleIndexResponse struct {
+	Error   bool                     `json:"error"`
+	Message string                   `json:"message"`
+	Data    []models.TransactionRule `json:"data"`
+}
+
+type TransactionRuleFetchResponse struct {
+	Error   bool                   `json:"error"`
+	Message string                 `json:"message"`
+	Data    models.TransactionRule `json:"data"`
+}
+
+type TransactionRuleDryRunResponse struct {
+	Error   bool              `json:"error"`
+	Message string            `json:"message"`
+	Scanned int               `json:"scanned"`
+	Changed int               `json:"changed"`
+	Data    []models.RuleDiff `json:"data"`
+}
+
+type TransactionRuleApplyResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Scanned int    `json:"scanned"`
+	Updated int    `json:"updated"`
+}
+
+// maxRuleDryRunResults membatasi jumlah diff yang dikembalikan dry run
+const maxRuleDryRunResults = 200
+
+// ruleBatchSize adalah jumlah transaction yang diproses per batch
+const ruleBatchSize = 500
+
+// findUserTransactionRule mengambil rule milik user, menulis response error jika gagal
+func findUserTransactionRule(c *gin.Context, userID uint) (models.TransactionRule, bool) {
+	var rule models.TransactionRule
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Rule ID!",
+		})
+		return rule, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Rule not found",
+				"message": "Rule no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch rule",
+			})
+		}
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// fillTransactionRule memvalidasi request dan mengisi field rule dari request
+func fillTransactionRule(c *gin.Context, userID uint, req TransactionRuleRequest, rule *models.TransactionRule) bool {
+	rule.UserID = userID
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Priority = req.Priority
+	rule.RemarksContains = strings.TrimSpace(req.RemarksContains)
+	rule.RemarksRegex = strings.TrimSpace(req.RemarksRegex)
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.Type = strings.TrimSpace(req.Type)
+	rule.SetCategoryID = req.SetCategoryID
+	rule.AddTags = models.MergeTags(req.AddTags)
+	rule.RenameRemarks = strings.TrimSpace(req.RenameRemarks)
+	rule.Active = true
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	message := ""
+	switch {
+	case rule.RemarksContains == "" && rule.RemarksRegex == "" && rule.MinAmount == nil && rule.MaxAmount == nil && rule.Type == "":
+		message = "At least one condition is required"
+	case rule.SetCategoryID == nil && rule.AddTags == "" && rule.RenameRemarks == "":
+		message = "At least one action is required"
+	case rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount:
+		message = "min_amount must not be greater than max_amount"
+	case rule.Compile() != nil:
+		message = "remarks_regex is not a valid regular expression"
+	case rule.SetCategoryID != nil && !userOwnsCategory(userID, *rule.SetCategoryID):
+		message = "Category not found"
+	}
+
+	if message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": message,
+		})
+		return false
+	}
+	return true
+}
+
+// bindTransactionRuleRequest bind request body lalu mengisi field rule
+func bindTransactionRuleRequest(c *gin.Context, userID uint, rule *models.TransactionRule) bool {
+	var req TransactionRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	return fillTransactionRule(c, userID, req, rule)
+}
+
+// IndexTransactionRule handler untuk list rule milik user sesuai urutan evaluasi
+func IndexTransactionRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var rules []models.TransactionRule
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    rules,
+	})
+}
+
+// CreateTransactionRule handler untuk membuat rule baru
+func CreateTransactionRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var rule models.TransactionRule
+	if !bindTransactionRuleRequest(c, userID, &rule) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Rule creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, TransactionRuleFetchResponse{
+		Error:   false,
+		Message: "Rule creation successful",
+		Data:    rule,
+	})
+}
+
+// GetTransactionRuleByID handler untuk mengambil detail rule
+func GetTransactionRuleByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rule, ok := findUserTransactionRule(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleFetchResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    rule,
+	})
+}
+
+// UpdateTransactionRule handler untuk mengubah rule
+func UpdateTransactionRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rule, ok := findUserTransactionRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if !bindTransactionRuleRequest(c, userID, &rule) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating rule!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleFetchResponse{
+		Error:   false,
+		Message: "Rule successfully updated",
+		Data:    rule,
+	})
+}
+
+// DeleteTransactionRuleByID handler untuk menghapus rule. Transaction yang
+// sudah dikategorikan oleh rule ini tidak berubah.
+func DeleteTransactionRuleByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rule, ok := findUserTransactionRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Delete(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete rule!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleDefaultResponse{
+		Error:   false,
+		Message: "Rule deletion successful",
+	})
+}
+
+// bindTransactionRuleRun membaca request dry run/re-apply dan mengembalikan
+// rules yang akan diterapkan serta query transaction history user
+func bindTransactionRuleRun(c *gin.Context, userID uint) ([]models.TransactionRule, *gorm.DB, bool) {
+	var req TransactionRuleRunRequest
+
+	// Body opsional, tanpa body berarti semua rule aktif terhadap semua history
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": err.Error(),
+			})
+			return nil, nil, false
+		}
+	}
+
+	location := models.GetUserCalendar(userID).Location
+	query := database.DB.WithContext(c.Request.Context()).Model(&models.Transaction{}).Where("user_id = ?", userID)
+	if req.Start != "" {
+		start, err := parseDate(req.Start, location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "start " + err.Error(),
+			})
+			return nil, nil, false
+		}
+		query = query.Where("transaction_date >= ?", start.UTC())
+	}
+	if req.End != "" {
+		end, err := parseDate(req.End, location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "end " + err.Error(),
+			})
+			return nil, nil, false
+		}
+		query = query.Where("transaction_date < ?", end.AddDate(0, 0, 1).UTC())
+	}
+
+	if req.Rule != nil {
+		var rule models.TransactionRule
+		if !fillTransactionRule(c, userID, *req.Rule, &rule) {
+			return nil, nil, false
+		}
+		return []models.TransactionRule{rule}, query, true
+	}
+
+	rules, err := models.GetActiveRules(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch rules",
+		})
+		return nil, nil, false
+	}
+
+	return rules, query, true
+}
+
+// DryRunTransactionRule handler untuk melihat transaction mana yang akan
+// berubah jika rules diterapkan ke history, tanpa menyimpan perubahan
+func DryRunTransactionRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rules, query, ok := bindTransactionRuleRun(c, userID)
+	if !ok {
+		return
+	}
+
+	scanned, changed := 0, 0
+	diffs := []models.RuleDiff{}
+
+	var batch []models.Transaction
+	err := query.Order("transaction_date ASC, id ASC").FindInBatches(&batch, ruleBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			scanned++
+			result := models.ApplyRules(rules, &batch[i], false)
+			if !result.Changed {
+				continue
+			}
+			changed++
+			if len(diffs) < maxRuleDryRunResults {
+				diffs = append(diffs, models.RuleDiff{Before: batch[i].ToPublicTransaction(), After: result})
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleDryRunResponse{
+		Error:   false,
+		Message: "Dry run completed",
+		Scanned: scanned,
+		Changed: changed,
+		Data:    diffs,
+	})
+}
+
+// ApplyTransactionRule handler untuk menerapkan ulang rules aktif ke
+// transaction yang sudah ada. Setiap batch disimpan dalam satu transaction;
+// jika sebuah batch gagal, batch sebelumnya tetap tersimpan dan jumlahnya
+// dikembalikan di response error agar client bisa menjalankan ulang.
+func ApplyTransactionRule(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	rules, query, ok := bindTransactionRuleRun(c, userID)
+	if !ok {
+		return
+	}
+
+	// Catat category & periode budget yang terdampak untuk evaluasi budget alert
+	type alertKey struct {
+		categoryID uint
+		period     time.Time
+	}
+	affected := map[alertKey]time.Time{}
+	calendar := models.GetUserCalendar(userID)
+
+	scanned := 0
+	var updated []models.Transaction
+
+	var batch []models.Transaction
+	err := query.Order("transaction_date ASC, id ASC").FindInBatches(&batch, ruleBatchSize, func(_ *gorm.DB, _ int) error {
+		now := time.Now()
+		var changed []models.Transaction
+		var previousCategories []uint
+		err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+			// fn bisa dijalankan ulang saat retry
+			changed, previousCategories = changed[:0], previousCategories[:0]
+			for i := range batch {
+				transaction := batch[i]
+				result := models.ApplyRules(rules, &transaction, false)
+				if !result.Changed {
+					continue
+				}
+
+				previousCategories = append(previousCategories, transaction.CategoryID)
+				result.Apply(&transaction)
+				transaction.UpdatedAt = now
+				if err := tx.Model(&transaction).Select("category_id", "remarks", "tags", "updated_at").Updates(&transaction).Error; err != nil {
+					return err
+				}
+				changed = append(changed, transaction)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		scanned += len(batch)
+		for i, transaction := range changed {
+			period := calendar.PeriodOf(transaction.TransactionDate).Start
+			affected[alertKey{previousCategories[i], period}] = transaction.TransactionDate
+			affected[alertKey{transaction.CategoryID, period}] = transaction.TransactionDate
+		}
+		updated = append(updated, changed...)
+		return nil
+	}).Error
+
+	for key, at := range affected {
+		alerts.EvaluateAsync(userID, key.categoryID, at)
+	}
+	for _, transaction := range updated {
+		events.Publish(userID, events.TransactionUpdated, transaction.ToPublicTransaction())
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to apply rules",
+			"scanned": scanned,
+			"updated": len(updated),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionRuleApplyResponse{
+		Error:   false,
+		Message: "Rules applied",
+		Scanned: scanned,
+		Updated: len(updated),
+	})
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"github.com/gin-gonic/gin"
+)
+
+type TrashIndexResponse struct {
+	Error         bool         `json:"error"`
+	Message       string       `json:"message"`
+	RetentionDays int          `json:"retention_days"`
+	Data          models.Trash `json:"data"`
+}
+
+// IndexTrash handler untuk daftar transaction dan category di trash beserta
+// tanggal data tersebut akan dihapus permanen
+func IndexTrash(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	retention := models.TrashRetention()
+	trash, err := models.GetTrash(userID, retention)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TrashIndexResponse{
+		Error:         false,
+		Message:       "Data loaded!",
+		RetentionDays: int(retention.Hours() / 24),
+		Data:          trash,
+	})
+}
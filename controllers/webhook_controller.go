@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/middlewares"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/safehttp"
+	"ashborn.id/moniplan/webhooks"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=255"`
+	Events []string `json:"events" binding:"required,min=1"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookDefaultResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+type WebhookIndexResponse struct {
+	Error   bool             `json:"error"`
+	Message string           `json:"message"`
+	Data    []models.Webhook `json:"data"`
+}
+
+type WebhookFetchResponse struct {
+	Error   bool           `json:"error"`
+	Message string         `json:"message"`
+	Data    models.Webhook `json:"data"`
+	Secret  string         `json:"secret,omitempty"` // Hanya dikirim saat webhook dibuat
+}
+
+type WebhookDeliveryIndexResponse struct {
+	Error   bool                     `json:"error"`
+	Message string                   `json:"message"`
+	Data    []models.WebhookDelivery `json:"data"`
+}
+
+// findUserWebhook mengambil webhook milik user, menulis response error jika gagal
+func findUserWebhook(c *gin.Context, userID uint) (models.Webhook, bool) {
+	var hook models.Webhook
+
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Webhook ID!",
+		})
+		return hook, false
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", webhookID, userID).First(&hook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Webhook not found",
+				"message": "Webhook no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Database error",
+				"message": "Failed to fetch webhook",
+			})
+		}
+		return hook, false
+	}
+
+	return hook, true
+}
+
+// bindWebhookRequest memvalidasi request dan mengisi field webhook dari request
+func bindWebhookRequest(c *gin.Context, userID uint, hook *models.Webhook) bool {
+	var req WebhookRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	if err := safehttp.ValidateURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	for _, event := range req.Events {
+		if !webhooks.IsValidEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"message": "Unknown event " + event + ", valid events: " + strings.Join(webhooks.Events, ", "),
+			})
+			return false
+		}
+	}
+
+	hook.UserID = userID
+	hook.URL = strings.TrimSpace(req.URL)
+	hook.Events = strings.Join(req.Events, ",")
+	hook.Active = true
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	return true
+}
+
+// IndexWebhook handler untuk list webhook milik user
+func IndexWebhook(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := database.DB.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("id ASC").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch webhooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    hooks,
+	})
+}
+
+// CreateWebhook handler untuk mendaftarkan webhook baru. Secret untuk
+// verifikasi signature hanya ditampilkan sekali di response ini.
+func CreateWebhook(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	var hook models.Webhook
+	if !bindWebhookRequest(c, userID, &hook) {
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Webhook creation failed",
+			"message": "Failed to generate webhook secret",
+		})
+		return
+	}
+	hook.Secret = secret
+
+	if err := database.DB.WithContext(c.Request.Context()).Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Webhook creation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookFetchResponse{
+		Error:   false,
+		Message: "Webhook creation successful",
+		Data:    hook,
+		Secret:  secret,
+	})
+}
+
+// UpdateWebhook handler untuk mengubah URL atau event webhook
+func UpdateWebhook(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	hook, ok := findUserWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	if !bindWebhookRequest(c, userID, &hook) {
+		return
+	}
+
+	if err := database.DB.WithContext(c.Request.Context()).Save(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error in updating webhook!",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookFetchResponse{
+		Error:   false,
+		Message: "Webhook successfully updated",
+		Data:    hook,
+	})
+}
+
+// DeleteWebhookByID handler untuk menghapus webhook beserta delivery log-nya
+func DeleteWebhookByID(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	hook, ok := findUserWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.Transaction(database.DB.WithContext(c.Request.Context()), func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed",
+			"message": "Unable to delete webhook!",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDefaultResponse{
+		Error:   false,
+		Message: "Webhook deletion successful",
+	})
+}
+
+// IndexWebhookDelivery handler untuk delivery log sebuah webhook. Query
+// `status` bisa diisi pending, success atau failed.
+func IndexWebhookDelivery(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	hook, ok := findUserWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	query := database.DB.WithContext(c.Request.Context()).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(100).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"message": "Failed to fetch webhook deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveryIndexResponse{
+		Error:   false,
+		Message: "Data loaded!",
+		Data:    deliveries,
+	})
+}
+
+// RedeliverWebhook handler untuk mengirim ulang sebuah delivery secara manual
+func RedeliverWebhook(c *gin.Context) {
+	userID, exists := middlewares.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Required Param",
+			"message": "Invalid Delivery ID!",
+		})
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := database.DB.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", deliveryID, userID).First(&delivery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Delivery not found",
+			"message": "Webhook delivery no longer exists",
+		})
+		return
+	}
+
+	if err := webhooks.Redeliver(&delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Redelivery failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, WebhookDefaultResponse{
+		Error:   false,
+		Message: "Webhook delivery queued",
+	})
+}
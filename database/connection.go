@@ -3,54 +3,133 @@ package database
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Driver database yang didukung, dipilih dengan env DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// TimeZone adalah zona waktu penyimpanan. Semua timestamp disimpan dalam
+// UTC, zona waktu user hanya dipakai saat parsing input dan menampilkan
+// tanggal (lihat models.UserCalendar).
+const TimeZone = "UTC"
+
+// Location adalah TimeZone sebagai *time.Location
+var Location = time.UTC
+
 var DB *gorm.DB
 
-func ConnectDatabase() {
+// Config adalah konfigurasi koneksi database. Untuk SQLite, Name adalah path
+// file database (atau :memory:).
+type Config struct {
+	Driver   string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+// ConfigFromEnv membaca konfigurasi dari env DB_DRIVER (default mysql),
+// DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME dan DB_SSLMODE (postgres)
+func ConfigFromEnv() Config {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER")))
+	if driver == "" {
+		driver = DriverMySQL
+	}
 
-	if dbHost == "" || dbPort == "" || dbUser == "" || dbName == "" {
-		log.Fatal("Database configuration is incomplete. Please check your .env file")
+	return Config{
+		Driver:   driver,
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		Name:     os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
 	}
+}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Asia%%2FJakarta", dbUser, dbPassword, dbHost, dbPort, dbName)
+// Validate mengecek konfigurasi yang wajib diisi untuk driver
+func (c Config) Validate() error {
+	switch c.Driver {
+	case DriverMySQL, DriverPostgres:
+		if c.Host == "" || c.Port == "" || c.User == "" || c.Name == "" {
+			return fmt.Errorf("DB_HOST, DB_PORT, DB_USER and DB_NAME are required for %s", c.Driver)
+		}
+	case DriverSQLite:
+		if c.Name == "" {
+			return fmt.Errorf("DB_NAME (database file) is required for sqlite")
+		}
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q, use mysql, postgres or sqlite", c.Driver)
+	}
+	return nil
+}
 
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-		NowFunc: func() time.Time {
-			return time.Now().Local()
-		},
+// Dialector membuat gorm.Dialector sesuai driver
+func (c Config) Dialector() (gorm.Dialector, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
 	}
 
-	var err error
-	for i := 0; i < 3; i++ {
-		DB, err = gorm.Open(mysql.Open(dsn), config)
-		if err == nil {
-			break
+	switch c.Driver {
+	case DriverPostgres:
+		sslMode := c.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
 		}
-		log.Printf("Failed to connect to database (attempt %d/3): %v", i+1, err)
-		time.Sleep(2 * time.Second)
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s", c.Host, c.Port, c.User, c.Password, c.Name, sslMode, TimeZone)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		// busy_timeout agar write yang bersamaan menunggu, bukan langsung gagal
+		dsn := c.Name + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+		return sqlite.Open(dsn), nil
+	default:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=%s", c.User, c.Password, c.Host, c.Port, c.Name, url.QueryEscape(TimeZone))
+		return mysql.Open(dsn), nil
 	}
+}
 
+// Open membuka koneksi database dan mengatur connection pool
+func Open(c Config, config *gorm.Config) (*gorm.DB, error) {
+	dialector, err := c.Dialector()
 	if err != nil {
-		log.Fatal("Failed to connect to database after 3 attempts:", err)
+		return nil, err
 	}
 
-	sqlDB, err := DB.DB()
+	db, err := gorm.Open(dialector, config)
 	if err != nil {
-		log.Fatal("Failed to configure database connection pool:", err)
+		return nil, err
+	}
+	if err := registerUTCCallbacks(db); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Driver == DriverSQLite {
+		// SQLite hanya mengizinkan satu writer, dan database :memory:
+		// hilang jika koneksinya ditutup
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		return db, nil
 	}
 
 	// SetMaxIdleConns sets the maximum number of connections in the idle connection pool
@@ -62,7 +141,37 @@ func ConnectDatabase() {
 	// SetConnMaxLifetime sets the maximum amount of time a connection may be reused
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	log.Println("✅ Database connected successfully")
+	return db, nil
+}
+
+func ConnectDatabase() {
+	cfg := ConfigFromEnv()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Database configuration is incomplete. Please check your .env file: ", err)
+	}
+
+	config := &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	var err error
+	for i := 0; i < 3; i++ {
+		DB, err = Open(cfg, config)
+		if err == nil {
+			break
+		}
+		log.Printf("Failed to connect to database (attempt %d/3): %v", i+1, err)
+		time.Sleep(2 * time.Second)
+	}
+
+	if err != nil {
+		log.Fatal("Failed to connect to database after 3 attempts:", err)
+	}
+
+	log.Printf("✅ Database connected successfully (%s)", cfg.Driver)
 }
 
 func CloseDatabase() {
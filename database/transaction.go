@@ -0,0 +1,66 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// MaxTransactionAttempts adalah jumlah percobaan Transaction jika database
+// membatalkan transaction karena deadlock
+const MaxTransactionAttempts = 3
+
+// transactionRetryDelay adalah jeda sebelum percobaan berikutnya, dikali
+// nomor percobaan
+const transactionRetryDelay = 50 * time.Millisecond
+
+// Transaction menjalankan fn di dalam satu database transaction: commit jika
+// fn berhasil dan rollback jika fn mengembalikan error. Jika transaction
+// dibatalkan karena deadlock, fn dijalankan ulang dari awal, sehingga fn
+// tidak boleh punya efek samping di luar tx (event, notifikasi, dsb).
+//
+// Jika db sudah berada di dalam transaction, fn dijalankan sebagai nested
+// transaction (savepoint) tanpa retry, karena deadlock membatalkan seluruh
+// transaction luar.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if _, nested := db.Statement.ConnPool.(gorm.TxCommitter); nested {
+		return db.Transaction(fn)
+	}
+
+	var err error
+	for attempt := 1; attempt <= MaxTransactionAttempts; attempt++ {
+		err = db.Transaction(fn)
+		if err == nil || !IsRetryable(err) || attempt == MaxTransactionAttempts {
+			return err
+		}
+
+		ctx := db.Statement.Context
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * transactionRetryDelay):
+		}
+	}
+	return err
+}
+
+// IsRetryable mengecek apakah err berarti transaction dibatalkan database
+// dan aman dijalankan ulang: deadlock dan lock wait timeout di MySQL, serta
+// deadlock dan serialization failure di PostgreSQL
+func IsRetryable(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// 1213: ER_LOCK_DEADLOCK, 1205: ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40P01: deadlock_detected, 40001: serialization_failure
+		return pgErr.Code == "40P01" || pgErr.Code == "40001"
+	}
+	return false
+}
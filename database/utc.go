@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// registerUTCCallbacks memastikan semua field time.Time pada model disimpan
+// dalam UTC, termasuk yang diisi dengan time.Now() di zona waktu server.
+// SQLite menyimpan tanggal sebagai teks, jadi zona yang campur membuat
+// perbandingan tanggal salah.
+func registerUTCCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("moniplan:utc_create", toUTC); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("moniplan:utc_update", toUTC)
+}
+
+func toUTC(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || !db.Statement.ReflectValue.IsValid() {
+		return
+	}
+
+	ctx := db.Statement.Context
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			convertFields(ctx, db.Statement.Schema, reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		convertFields(ctx, db.Statement.Schema, value)
+	}
+}
+
+func convertFields(ctx context.Context, s *schema.Schema, value reflect.Value) {
+	if value.Kind() != reflect.Struct || !value.CanAddr() {
+		return
+	}
+
+	for _, field := range s.Fields {
+		if field.IndirectFieldType != timeType {
+			continue
+		}
+
+		raw, zero := field.ValueOf(ctx, value)
+		if zero {
+			continue
+		}
+		switch t := raw.(type) {
+		case time.Time:
+			_ = field.Set(ctx, value, t.UTC())
+		case *time.Time:
+			if t != nil {
+				utc := t.UTC()
+				_ = field.Set(ctx, value, &utc)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event type yang dipublish oleh controller
+const (
+	TransactionCreated  = "transaction.created"
+	TransactionUpdated  = "transaction.updated"
+	TransactionDeleted  = "transaction.deleted"
+	TransactionRestored = "transaction.restored"
+	CategoryCreated     = "category.created"
+	CategoryUpdated     = "category.updated"
+	CategoryDeleted     = "category.deleted"
+	CategoryRestored    = "category.restored"
+	BudgetCreated       = "budget.created"
+	BudgetUpdated       = "budget.updated"
+	BudgetDeleted       = "budget.deleted"
+	BudgetExceeded      = "budget.exceeded"
+)
+
+// Event adalah perubahan data milik seorang user. ID berformat
+// "<epoch>-<seq>": epoch berbeda setiap kali proses start dan seq naik terus
+// selama proses berjalan. ID dipakai sebagai SSE id untuk resume via
+// Last-Event-ID.
+type Event struct {
+	ID     string      `json:"id"`
+	Seq    uint64      `json:"-"`
+	UserID uint        `json:"user_id"`
+	Type   string      `json:"type"`
+	Data   interface{} `json:"data"`
+	At     time.Time   `json:"at"`
+}
+
+// Subscription menerima event milik satu user lewat channel C
+type Subscription struct {
+	UserID uint
+	C      chan Event
+}
+
+// Bus adalah event bus in-process. Event terakhir disimpan di ring buffer
+// agar subscriber yang reconnect bisa melanjutkan dari Last-Event-ID.
+type Bus struct {
+	mu          sync.RWMutex
+	epoch       string
+	nextID      uint64
+	history     []Event
+	historySize int
+	subscribers map[uint]map[*Subscription]struct{}
+	listeners   []func(Event)
+}
+
+// NewBus membuat Bus yang menyimpan historySize event terakhir
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		history:     make([]Event, 0, historySize),
+		subscribers: map[uint]map[*Subscription]struct{}{},
+	}
+}
+
+// Default adalah bus yang dipakai oleh seluruh aplikasi
+var Default = NewBus(1000)
+
+// Publish mengirim event ke Default bus
+func Publish(userID uint, eventType string, data interface{}) Event {
+	return Default.Publish(userID, eventType, data)
+}
+
+// AddListener mendaftarkan fungsi yang dipanggil untuk setiap event semua
+// user. Listener dipanggil secara sinkron sehingga tidak boleh blocking.
+func (b *Bus) AddListener(listener func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// Publish menyimpan event ke history lalu mengirimnya ke subscriber user
+// tersebut. Subscriber yang lambat akan kehilangan event daripada
+// memblokir publisher, dan bisa resume lewat Last-Event-ID.
+func (b *Bus) Publish(userID uint, eventType string, data interface{}) Event {
+	b.mu.Lock()
+	b.nextID++
+	event := Event{
+		ID:     b.epoch + "-" + strconv.FormatUint(b.nextID, 10),
+		Seq:    b.nextID,
+		UserID: userID,
+		Type:   eventType,
+		Data:   data,
+		At:     time.Now(),
+	}
+
+	if len(b.history) >= b.historySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, event)
+
+	subscribers := make([]*Subscription, 0, len(b.subscribers[userID]))
+	for sub := range b.subscribers[userID] {
+		subscribers = append(subscribers, sub)
+	}
+	listeners := b.listeners
+	b.mu.Unlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.C <- event:
+		default:
+		}
+	}
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+
+	return event
+}
+
+// Subscribe mendaftarkan subscriber untuk event milik userID. Jika
+// lastEventID diisi, event user setelah ID tersebut yang masih ada di
+// history dikembalikan sebagai missed. complete bernilai false jika event
+// setelah lastEventID sudah keluar dari history atau ID-nya tidak dikenal
+// (misalnya dari proses sebelum restart), sehingga client perlu reload data
+// secara penuh.
+func (b *Bus) Subscribe(userID uint, lastEventID string) (sub *Subscription, missed []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{UserID: userID, C: make(chan Event, 64)}
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[userID][sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	lastSeq, ok := b.parseID(lastEventID)
+	if !ok || lastSeq > b.nextID {
+		return sub, nil, false
+	}
+
+	complete = len(b.history) == 0 || b.history[0].Seq <= lastSeq+1
+	for _, event := range b.history {
+		if event.Seq > lastSeq && event.UserID == userID {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed, complete
+}
+
+// parseID mengambil seq dari ID event milik proses ini
+func (b *Bus) parseID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != b.epoch {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(seq, 10, 64)
+	return parsed, err == nil
+}
+
+// Unsubscribe menghentikan subscription
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers[sub.UserID], sub)
+	if len(b.subscribers[sub.UserID]) == 0 {
+		delete(b.subscribers, sub.UserID)
+	}
+}
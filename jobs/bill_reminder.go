@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ashborn.id/moniplan/alerts"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/notifier"
+)
+
+// BillReminderJob mengingatkan user untuk tagihan yang jatuh tempo dalam
+// leadDays hari ke depan, dan membayar otomatis tagihan autopay yang sudah
+// jatuh tempo
+func BillReminderJob(n notifier.Notifier, leadDays int) Job {
+	return Job{
+		Name:     "bill-reminder",
+		Interval: time.Hour,
+		Run: func(ctx context.Context, now time.Time) error {
+			return runBillReminders(ctx, n, leadDays, now)
+		},
+	}
+}
+
+func runBillReminders(ctx context.Context, n notifier.Notifier, leadDays int, now time.Time) error {
+	var bills []models.Bill
+	if err := database.DB.Where("active = ?", true).Find(&bills).Error; err != nil {
+		return err
+	}
+
+	users := map[uint]models.User{}
+
+	for _, bill := range bills {
+		user, ok := users[bill.UserID]
+		if !ok {
+			if err := database.DB.First(&user, bill.UserID).Error; err != nil {
+				continue
+			}
+			users[bill.UserID] = user
+		}
+
+		// Hari ini dan due date dihitung di zona waktu user
+		calendar := user.Calendar()
+		today := calendar.Today(now)
+		until := today.AddDate(0, 0, leadDays)
+
+		dates, err := bill.Occurrences(today, until)
+		if err != nil {
+			log.Printf("Bill %d has invalid due rule: %v", bill.ID, err)
+			continue
+		}
+
+		for _, dueDate := range dates {
+			var paid int64
+			database.DB.Model(&models.BillPayment{}).Where("bill_id = ? AND due_date = ?", bill.ID, dueDate.UTC()).Count(&paid)
+			if paid > 0 {
+				continue
+			}
+
+			// Tagihan autopay yang sudah jatuh tempo langsung dicatat sebagai paid
+			if bill.Autopay && !dueDate.After(today) {
+				if _, err := bill.MarkPaid(ctx, dueDate, bill.Amount, now); err != nil {
+					if err != models.ErrBillAlreadyPaid {
+						log.Printf("Failed to autopay bill %d: %v", bill.ID, err)
+					}
+					continue
+				}
+
+				if err := alerts.Evaluate(ctx, bill.UserID, bill.CategoryID, now); err != nil {
+					log.Printf("Failed to evaluate budget alerts for bill %d: %v", bill.ID, err)
+				}
+				continue
+			}
+
+			var reminded int64
+			database.DB.Model(&models.BillReminder{}).Where("bill_id = ? AND due_date = ?", bill.ID, dueDate.UTC()).Count(&reminded)
+			if reminded > 0 {
+				continue
+			}
+
+			body := fmt.Sprintf("%s (%d) is due on %s", bill.Payee, bill.Amount, calendar.FormatDate(dueDate))
+			if bill.Autopay {
+				body += " and will be paid automatically"
+			}
+
+			if err := n.Notify(ctx, notifier.Message{
+				UserID:  user.ID,
+				Email:   user.Email,
+				Name:    user.Name,
+				Subject: "Upcoming bill: " + bill.Payee,
+				Body:    body,
+			}); err != nil {
+				log.Printf("Failed to send reminder for bill %d: %v", bill.ID, err)
+				continue
+			}
+
+			database.DB.Create(&models.BillReminder{
+				BillID:  bill.ID,
+				DueDate: dueDate,
+				SentAt:  now,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job adalah pekerjaan background yang dijalankan secara periodik
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Start menjalankan setiap job dalam goroutine sendiri. Job langsung
+// dijalankan sekali saat start, lalu setiap Interval sampai ctx di-cancel.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, job)
+	}
+}
+
+func run(ctx context.Context, job Job) {
+	log.Printf("⏱️  Background job %s started (every %s)", job.Name, job.Interval)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx, time.Now()); err != nil {
+			log.Printf("Background job %s failed: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Background job %s stopped", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
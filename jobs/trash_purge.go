@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ashborn.id/moniplan/attachments"
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+)
+
+// trashPurgeBatch adalah jumlah transaction yang dihapus permanen per batch
+const trashPurgeBatch = 500
+
+// TrashPurgeJob menghapus permanen transaction, budget dan category yang
+// sudah berada di trash lebih lama dari retention, termasuk attachment-nya
+func TrashPurgeJob(retention time.Duration) Job {
+	return Job{
+		Name:     "trash-purge",
+		Interval: 6 * time.Hour,
+		Run: func(ctx context.Context, now time.Time) error {
+			return purgeTrash(ctx, now.Add(-retention))
+		},
+	}
+}
+
+func purgeTrash(ctx context.Context, before time.Time) error {
+	var purged int
+	for {
+		ids, err := models.ExpiredTrashTransactionIDs(before, trashPurgeBatch)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		var owners []models.Transaction
+		if err := database.DB.Unscoped().Select("id", "user_id").Where("id IN ?", ids).Find(&owners).Error; err != nil {
+			return err
+		}
+		for _, t := range owners {
+			if err := attachments.DeleteForTransaction(ctx, t.UserID, t.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := models.PurgeTransactions(ids); err != nil {
+			return err
+		}
+		purged += len(ids)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	budgets, categories, err := models.PurgeTrash(before)
+	if err != nil {
+		return err
+	}
+
+	if purged+int(budgets)+int(categories) > 0 {
+		log.Printf("🗑️  Purged %d transactions, %d budgets and %d categories from trash", purged, budgets, categories)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"time"
+
+	"ashborn.id/moniplan/webhooks"
+)
+
+// WebhookRetryJob mengirim ulang webhook delivery yang gagal sesuai jadwal
+// exponential backoff-nya
+func WebhookRetryJob() Job {
+	return Job{
+		Name:     "webhook-retry",
+		Interval: 30 * time.Second,
+		Run:      webhooks.RetryPending,
+	}
+}
@@ -6,12 +6,25 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"ashborn.id/moniplan/attachments"
+	"ashborn.id/moniplan/audit"
+	"ashborn.id/moniplan/categorizer"
+	"ashborn.id/moniplan/controllers"
 	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/jobs"
+	"ashborn.id/moniplan/migrations"
 	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/notifier"
+	"ashborn.id/moniplan/repository"
 	"ashborn.id/moniplan/routes"
+	"ashborn.id/moniplan/services"
+	"ashborn.id/moniplan/storage"
+	"ashborn.id/moniplan/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -36,11 +49,50 @@ func main() {
 	// Connect ke database
 	database.ConnectDatabase()
 
-	// Auto migrate models
-	if err := database.DB.AutoMigrate(&models.User{}); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	// Subcommand migrate: status, up, down [n], to <version>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrations.Run(database.DB, os.Args[2:], os.Stdout)
+		database.CloseDatabase()
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
+	// Jangan jalan di atas schema yang belum dimigrasi
+	if err := migrations.Check(database.DB); err != nil {
+		log.Fatal("Database schema check failed: ", err)
 	}
-	log.Println("✅ Database migration completed")
+	log.Printf("✅ Database schema is at version %d", migrations.Latest())
+
+	// Service untuk handler, data diakses lewat repository GORM
+	controllers.Services = services.New(repository.NewGorm(database.DB), categorizer.Default)
+
+	// Storage untuk attachment (local atau S3)
+	attachments.Storage = storage.FromEnv()
+
+	// Catat setiap perubahan data ke audit log
+	if err := audit.Register(database.DB); err != nil {
+		log.Fatal("Failed to register audit callbacks:", err)
+	}
+
+	// Start background jobs, berhenti saat server shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	billReminderDays, err := strconv.Atoi(os.Getenv("BILL_REMINDER_DAYS"))
+	if err != nil || billReminderDays < 0 {
+		billReminderDays = 3
+	}
+
+	webhooks.Listen(events.Default)
+	categorizer.Default.Listen(events.Default)
+	webhooks.Start(jobsCtx)
+	jobs.Start(jobsCtx,
+		jobs.BillReminderJob(notifier.FromEnv(), billReminderDays),
+		jobs.WebhookRetryJob(),
+		jobs.TrashPurgeJob(models.TrashRetention()),
+	)
 
 	// Setup Gin router
 	router := gin.New()
@@ -82,6 +134,7 @@ func main() {
 	<-quit
 
 	log.Println("⚠️  Shutting down server...")
+	stopJobs()
 
 	// Graceful shutdown dengan timeout 5 detik
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"ashborn.id/moniplan/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditContext menyimpan IP dan user agent di context request, sehingga
+// perubahan data lewat database.DB.WithContext(c.Request.Context()) tercatat
+// lengkap di audit log. AuthMiddleware menambahkan user ID-nya.
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setAuditActor(c, 0)
+		c.Next()
+	}
+}
+
+func setAuditActor(c *gin.Context, userID uint) {
+	c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}))
+}
@@ -135,6 +135,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("userEmail", claims.Email)
 		c.Set("userName", claims.Name)
 		c.Set("claims", claims)
+		setAuditActor(c, claims.UserID)
 
 		// Lanjutkan ke handler berikutnya
 		c.Next()
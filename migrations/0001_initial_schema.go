@@ -0,0 +1,364 @@
+package migrations
+
+import (
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+// initialTables adalah tabel baseline, diurutkan agar tabel yang direferensikan
+// dibuat lebih dulu. Struct-nya dibekukan sesuai schema sebelum ada
+// schema_migrations, bukan memakai models, agar perubahan model berikutnya
+// (unique index, foreign key, kolom baru) hanya dibuat oleh migration
+// setelahnya.
+var initialTables = []interface{}{
+	&baselineUser{},
+	&baselineCategory{},
+	&baselineBudget{},
+	&baselineTransaction{},
+	&baselineGoal{},
+	&baselineLoan{},
+	&baselineLoanSchedule{},
+	&baselineBill{},
+	&baselineBillPayment{},
+	&baselineBillReminder{},
+	&baselineAlertRule{},
+	&baselineBudgetAlertLog{},
+	&baselineNotification{},
+	&baselineWebhook{},
+	&baselineWebhookDelivery{},
+	&baselineTransactionRule{},
+	&baselinePayee{},
+	&baselinePayeeAlias{},
+	&baselineExchangeRate{},
+	&baselineAttachment{},
+	&baselineAuditLog{},
+}
+
+// initialSchema membuat semua tabel. Database lama yang dibuat dengan
+// AutoMigrate sebelum ada schema_migrations juga dinaikkan ke baseline ini:
+// type transaction dinormalkan sebelum kolomnya diperkecil, lalu nominal
+// lama diubah ke signed minor unit.
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		if err := models.MigrateTransactionTypes(tx); err != nil {
+			return err
+		}
+		if err := tx.AutoMigrate(initialTables...); err != nil {
+			return err
+		}
+		return models.MigrateMoneyColumns(tx)
+	},
+	Down: func(tx *gorm.DB) error {
+		for i := len(initialTables) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(initialTables[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+type baselineUser struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"not null;size:100"`
+	Email        string `gorm:"uniqueIndex;not null;size:100"`
+	Password     string `gorm:"not null"`
+	BaseCurrency string `gorm:"not null;size:3;default:'IDR'"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineCategory struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null"`
+	Name      string `gorm:"not null;size:100"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineCategory) TableName() string { return "categories" }
+
+type baselineBudget struct {
+	ID         uint         `gorm:"primaryKey"`
+	UserID     uint         `gorm:"not null"`
+	CategoryID uint         `gorm:"not null"`
+	Month      uint         `gorm:"not null"`
+	Year       uint         `gorm:"not null"`
+	Amount     money.Amount `gorm:"not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineBudget) TableName() string { return "budgets" }
+
+type baselineTransaction struct {
+	ID              uint         `gorm:"primaryKey"`
+	UserID          uint         `gorm:"not null"`
+	CategoryID      uint         `gorm:"not null"`
+	PayeeID         *uint        `gorm:"index"`
+	Amount          money.Amount `gorm:"not null"`
+	Currency        string       `gorm:"not null;size:3;default:'IDR'"`
+	Type            string       `gorm:"not null;size:20;index"`
+	Remarks         string       `gorm:"not null"`
+	Tags            string       `gorm:"not null;size:255;default:''"`
+	TransactionDate time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineTransaction) TableName() string { return "transactions" }
+
+type baselineGoal struct {
+	ID           uint         `gorm:"primaryKey"`
+	UserID       uint         `gorm:"not null;index"`
+	CategoryID   uint         `gorm:"not null"`
+	Name         string       `gorm:"not null;size:100"`
+	TargetAmount money.Amount `gorm:"not null"`
+	TargetDate   time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (baselineGoal) TableName() string { return "goals" }
+
+type baselineLoan struct {
+	ID           uint         `gorm:"primaryKey"`
+	UserID       uint         `gorm:"not null;index"`
+	CategoryID   uint         `gorm:"not null"`
+	Name         string       `gorm:"not null;size:100"`
+	Principal    money.Amount `gorm:"not null"`
+	InterestRate float64      `gorm:"not null"`
+	TermMonths   uint         `gorm:"not null"`
+	PaymentDay   uint         `gorm:"not null"`
+	StartDate    time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (baselineLoan) TableName() string { return "loans" }
+
+type baselineLoanSchedule struct {
+	ID            uint `gorm:"primaryKey"`
+	LoanID        uint `gorm:"not null;index"`
+	Period        uint `gorm:"not null"`
+	DueDate       time.Time
+	Payment       money.Amount `gorm:"not null"`
+	Principal     money.Amount `gorm:"not null"`
+	Interest      money.Amount `gorm:"not null"`
+	Balance       money.Amount `gorm:"not null"`
+	TransactionID *uint
+	PaidAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (baselineLoanSchedule) TableName() string { return "loan_schedules" }
+
+type baselineBill struct {
+	ID         uint         `gorm:"primaryKey"`
+	UserID     uint         `gorm:"not null;index"`
+	CategoryID uint         `gorm:"not null"`
+	Payee      string       `gorm:"not null;size:100"`
+	Amount     money.Amount `gorm:"not null"`
+	IsEstimate bool         `gorm:"not null;default:false"`
+	DueRule    string       `gorm:"not null;size:50"`
+	Autopay    bool         `gorm:"not null;default:false"`
+	StartDate  time.Time
+	Active     bool `gorm:"not null;default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (baselineBill) TableName() string { return "bills" }
+
+type baselineBillPayment struct {
+	ID            uint `gorm:"primaryKey"`
+	BillID        uint `gorm:"not null;index"`
+	DueDate       time.Time
+	TransactionID uint         `gorm:"not null"`
+	Amount        money.Amount `gorm:"not null"`
+	PaidAt        time.Time
+	CreatedAt     time.Time
+}
+
+func (baselineBillPayment) TableName() string { return "bill_payments" }
+
+type baselineBillReminder struct {
+	ID        uint `gorm:"primaryKey"`
+	BillID    uint `gorm:"not null;index"`
+	DueDate   time.Time
+	SentAt    time.Time
+	CreatedAt time.Time
+}
+
+func (baselineBillReminder) TableName() string { return "bill_reminders" }
+
+type baselineAlertRule struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	CategoryID uint   `gorm:"not null;index"`
+	Threshold  uint   `gorm:"not null"`
+	Channel    string `gorm:"not null;size:20;default:in_app"`
+	Target     string `gorm:"size:255"`
+	Active     bool   `gorm:"not null;default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (baselineAlertRule) TableName() string { return "alert_rules" }
+
+type baselineBudgetAlertLog struct {
+	ID         uint         `gorm:"primaryKey"`
+	RuleID     uint         `gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	CategoryID uint         `gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Year       uint         `gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Month      uint         `gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Spent      money.Amount `gorm:"not null"`
+	Budget     money.Amount `gorm:"not null"`
+	FiredAt    time.Time
+	CreatedAt  time.Time
+}
+
+func (baselineBudgetAlertLog) TableName() string { return "budget_alert_logs" }
+
+type baselineNotification struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Type      string `gorm:"not null;size:50"`
+	Title     string `gorm:"not null;size:255"`
+	Body      string `gorm:"type:text"`
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (baselineNotification) TableName() string { return "notifications" }
+
+type baselineWebhook struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	URL       string `gorm:"not null;size:255"`
+	Secret    string `gorm:"not null;size:100"`
+	Events    string `gorm:"not null;size:500"`
+	Active    bool   `gorm:"not null;default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineWebhook) TableName() string { return "webhooks" }
+
+type baselineWebhookDelivery struct {
+	ID            uint   `gorm:"primaryKey"`
+	WebhookID     uint   `gorm:"not null;index"`
+	UserID        uint   `gorm:"not null;index"`
+	Event         string `gorm:"not null;size:50"`
+	Payload       string `gorm:"type:text"`
+	Status        string `gorm:"not null;size:20;index"`
+	Attempts      uint   `gorm:"not null;default:0"`
+	ResponseCode  int
+	LastError     string     `gorm:"size:500"`
+	NextAttemptAt *time.Time `gorm:"index"`
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (baselineWebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+type baselineTransactionRule struct {
+	ID              uint   `gorm:"primaryKey"`
+	UserID          uint   `gorm:"not null;index"`
+	Name            string `gorm:"not null;size:100"`
+	Priority        int    `gorm:"not null;default:0"`
+	Active          bool   `gorm:"not null;default:true"`
+	RemarksContains string `gorm:"size:255"`
+	RemarksRegex    string `gorm:"size:255"`
+	MinAmount       *money.Amount
+	MaxAmount       *money.Amount
+	Type            string `gorm:"size:20"`
+	SetCategoryID   *uint
+	AddTags         string `gorm:"size:255"`
+	RenameRemarks   string `gorm:"size:255"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (baselineTransactionRule) TableName() string { return "transaction_rules" }
+
+type baselinePayee struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_payees_user_name"`
+	Name      string `gorm:"not null;size:100;uniqueIndex:idx_payees_user_name"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselinePayee) TableName() string { return "payees" }
+
+type baselinePayeeAlias struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	PayeeID   uint   `gorm:"not null;index"`
+	Pattern   string `gorm:"not null;size:255"`
+	MatchType string `gorm:"not null;size:20;default:contains"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselinePayeeAlias) TableName() string { return "payee_aliases" }
+
+type baselineExchangeRate struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_exchange_rates_pair_date"`
+	Base      string    `gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_pair_date"`
+	Quote     string    `gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_pair_date"`
+	Date      time.Time `gorm:"not null;type:date;uniqueIndex:idx_exchange_rates_pair_date"`
+	Rate      float64   `gorm:"not null;type:decimal(20,10)"`
+	Source    string    `gorm:"not null;size:20;default:manual"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineExchangeRate) TableName() string { return "exchange_rates" }
+
+type baselineAttachment struct {
+	ID            uint   `gorm:"primaryKey"`
+	UserID        uint   `gorm:"not null;index"`
+	TransactionID uint   `gorm:"not null;index"`
+	FileName      string `gorm:"not null;size:255"`
+	ContentType   string `gorm:"not null;size:100"`
+	Size          int64  `gorm:"not null"`
+	Checksum      string `gorm:"not null;size:64"`
+	StorageKey    string `gorm:"not null;size:255"`
+	ThumbnailKey  string `gorm:"size:255"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (baselineAttachment) TableName() string { return "attachments" }
+
+type baselineAuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null;index"`
+	ActorID    *uint     `gorm:"index"`
+	Action     string    `gorm:"not null;size:20"`
+	EntityType string    `gorm:"not null;size:50;index:idx_audit_logs_entity"`
+	EntityID   uint      `gorm:"not null;index:idx_audit_logs_entity"`
+	Before     string    `gorm:"type:text"`
+	After      string    `gorm:"type:text"`
+	Changes    string    `gorm:"type:text"`
+	IP         string    `gorm:"size:45"`
+	UserAgent  string    `gorm:"size:255"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (baselineAuditLog) TableName() string { return "audit_logs" }
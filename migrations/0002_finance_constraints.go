@@ -0,0 +1,299 @@
+package migrations
+
+import (
+	"errors"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// financeTables adalah tabel yang diubah migration ini
+var financeTables = []interface{}{&models.Category{}, &models.Budget{}, &models.Transaction{}}
+
+// financeIndexes adalah index yang ditambahkan ke tabel keuangan
+var financeIndexes = []struct {
+	model interface{}
+	name  string
+}{
+	{&models.Category{}, "idx_categories_user_name"},
+	{&models.Budget{}, "idx_budgets_user_category_period"},
+	{&models.Transaction{}, "idx_transactions_user_date"},
+	{&models.Transaction{}, "idx_transactions_category_id"},
+}
+
+// financeConstraints adalah foreign key tabel keuangan, dibuat berurutan
+// karena SQLite membuat ulang tabel saat menambah constraint: categories
+// harus selesai sebelum tabel yang mereferensikannya.
+var financeConstraints = []struct {
+	model interface{}
+	name  string
+}{
+	{&models.Category{}, "User"},
+	{&models.Budget{}, "User"},
+	{&models.Budget{}, "Category"},
+	{&models.Transaction{}, "User"},
+	{&models.Transaction{}, "Category"},
+	{&models.Transaction{}, "Payee"},
+}
+
+// financeConstraintsMigration menambahkan unique index, index dan foreign key
+// untuk categories, budgets dan transactions. Data lama dibersihkan dulu:
+// category dengan nama sama digabung, budget dobel per bulan disisakan yang
+// terbaru, dan baris yang mereferensikan data yang sudah tidak ada dihapus
+// atau dipindah ke category "uncategorized".
+var financeConstraintsMigration = Migration{
+	Version: 2,
+	Name:    "finance_constraints",
+	Up: func(tx *gorm.DB) error {
+		steps := []func(*gorm.DB) error{
+			mergeDuplicateCategories,
+			dedupeBudgets,
+			removeOrphanedFinanceRows,
+		}
+		for _, step := range steps {
+			if err := step(tx); err != nil {
+				return err
+			}
+		}
+
+		migrator := tx.Migrator()
+		for _, constraint := range financeConstraints {
+			if migrator.HasConstraint(constraint.model, constraint.name) {
+				continue
+			}
+			if err := migrator.CreateConstraint(constraint.model, constraint.name); err != nil {
+				return err
+			}
+		}
+		for _, model := range financeTables {
+			if err := createMissingIndexes(tx, model, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for i := len(financeConstraints) - 1; i >= 0; i-- {
+			constraint := financeConstraints[i]
+			if !migrator.HasConstraint(constraint.model, constraint.name) {
+				continue
+			}
+			if err := migrator.DropConstraint(constraint.model, constraint.name); err != nil {
+				return err
+			}
+		}
+		skip := map[string]bool{}
+		for _, index := range financeIndexes {
+			skip[index.name] = true
+			if !migrator.HasIndex(index.model, index.name) {
+				continue
+			}
+			if err := migrator.DropIndex(index.model, index.name); err != nil {
+				return err
+			}
+		}
+		for _, model := range financeTables {
+			if err := createMissingIndexes(tx, model, skip); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// createMissingIndexes membuat index model yang belum ada, kecuali yang ada
+// di skip. Di SQLite menambah atau menghapus constraint membuat ulang tabel,
+// sehingga index lama ikut hilang dan perlu dibuat kembali.
+func createMissingIndexes(tx *gorm.DB, model interface{}, skip map[string]bool) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+
+	migrator := tx.Migrator()
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if skip[index.Name] || migrator.HasIndex(model, index.Name) {
+			continue
+		}
+		if err := migrator.CreateIndex(model, index.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDuplicateCategories menggabungkan category user dengan nama yang sama
+// (tanpa membedakan huruf besar kecil). Category yang dipertahankan adalah yang
+// tidak di trash dengan ID terkecil, semua referensi dipindah ke sana.
+func mergeDuplicateCategories(tx *gorm.DB) error {
+	var groups []struct {
+		UserID uint
+		Name   string
+	}
+	err := tx.Model(&models.Category{}).Unscoped().
+		Select("user_id, LOWER(name) AS name").
+		Group("user_id, LOWER(name)").
+		Having("COUNT(*) > 1").
+		Scan(&groups).Error
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		var categories []models.Category
+		if err := tx.Unscoped().
+			Where("user_id = ? AND LOWER(name) = ?", group.UserID, group.Name).
+			Order("id ASC").
+			Find(&categories).Error; err != nil {
+			return err
+		}
+
+		keep := categories[0]
+		for _, category := range categories {
+			if !category.DeletedAt.Valid {
+				keep = category
+				break
+			}
+		}
+
+		var duplicateIDs []uint
+		for _, category := range categories {
+			if category.ID != keep.ID {
+				duplicateIDs = append(duplicateIDs, category.ID)
+			}
+		}
+
+		for _, model := range []interface{}{&models.Transaction{}, &models.Budget{}, &models.Goal{}, &models.Loan{}, &models.Bill{}, &models.AlertRule{}} {
+			if err := tx.Unscoped().Model(model).Where("category_id IN ?", duplicateIDs).Update("category_id", keep.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.TransactionRule{}).Where("set_category_id IN ?", duplicateIDs).Update("set_category_id", keep.ID).Error; err != nil {
+			return err
+		}
+		// Log alert hanya mencegah notifikasi dobel, cukup dihapus agar tidak
+		// bentrok dengan unique index log category yang dipertahankan
+		if err := tx.Where("category_id IN ?", duplicateIDs).Delete(&models.BudgetAlertLog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id IN ?", duplicateIDs).Delete(&models.Category{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dedupeBudgets menyisakan satu budget per category dan bulan: yang tidak di
+// trash dengan ID terbesar, sama seperti budget yang selama ini dibaca aplikasi
+func dedupeBudgets(tx *gorm.DB) error {
+	var groups []struct {
+		UserID     uint
+		CategoryID uint
+		Year       uint
+		Month      uint
+	}
+	err := tx.Model(&models.Budget{}).Unscoped().
+		Select("user_id, category_id, year, month").
+		Group("user_id, category_id, year, month").
+		Having("COUNT(*) > 1").
+		Scan(&groups).Error
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		var budgets []models.Budget
+		if err := tx.Unscoped().
+			Where("user_id = ? AND category_id = ? AND year = ? AND month = ?", group.UserID, group.CategoryID, group.Year, group.Month).
+			Order("id DESC").
+			Find(&budgets).Error; err != nil {
+			return err
+		}
+
+		keep := budgets[0]
+		for _, budget := range budgets {
+			if !budget.DeletedAt.Valid {
+				keep = budget
+				break
+			}
+		}
+
+		if err := tx.Unscoped().
+			Where("user_id = ? AND category_id = ? AND year = ? AND month = ? AND id <> ?", group.UserID, group.CategoryID, group.Year, group.Month, keep.ID).
+			Delete(&models.Budget{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeOrphanedFinanceRows membersihkan baris yang akan melanggar foreign
+// key: data milik user yang sudah tidak ada dihapus, budget tanpa category
+// dihapus, transaction tanpa category dipindah ke category "uncategorized"
+// dan payee yang sudah tidak ada dikosongkan.
+func removeOrphanedFinanceRows(tx *gorm.DB) error {
+	users := tx.Model(&models.User{}).Select("id")
+	for _, model := range []interface{}{&models.Transaction{}, &models.Budget{}, &models.Category{}} {
+		if err := tx.Unscoped().Where("user_id NOT IN (?)", users).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	categories := tx.Unscoped().Model(&models.Category{}).Select("id")
+	if err := tx.Unscoped().Where("category_id NOT IN (?)", categories).Delete(&models.Budget{}).Error; err != nil {
+		return err
+	}
+
+	var userIDs []uint
+	if err := tx.Unscoped().Model(&models.Transaction{}).
+		Distinct("user_id").
+		Where("category_id NOT IN (?)", categories).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		category, err := uncategorizedCategory(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Transaction{}).
+			Where("user_id = ? AND category_id NOT IN (?)", userID, categories).
+			Update("category_id", category.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	payees := tx.Model(&models.Payee{}).Select("id")
+	return tx.Unscoped().Model(&models.Transaction{}).
+		Where("payee_id IS NOT NULL AND payee_id NOT IN (?)", payees).
+		Update("payee_id", nil).Error
+}
+
+// uncategorizedCategory mengambil category "uncategorized" user, membuatnya
+// jika belum ada atau mengeluarkannya dari trash
+func uncategorizedCategory(tx *gorm.DB, userID uint) (models.Category, error) {
+	var category models.Category
+	err := tx.Unscoped().
+		Where("user_id = ? AND LOWER(name) = ?", userID, models.UncategorizedCategoryName).
+		First(&category).Error
+	if err == nil {
+		if category.DeletedAt.Valid {
+			err = tx.Unscoped().Model(&category).Update("deleted_at", nil).Error
+		}
+		return category, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return category, err
+	}
+
+	now := time.Now()
+	category = models.Category{
+		UserID:    userID,
+		Name:      models.UncategorizedCategoryName,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return category, tx.Create(&category).Error
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// budgetTemplatesMigration menambahkan tabel template budget
+var budgetTemplatesMigration = Migration{
+	Version: 3,
+	Name:    "budget_templates",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.BudgetTemplate{}, &models.BudgetTemplateItem{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&models.BudgetTemplateItem{}, &models.BudgetTemplate{})
+	},
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// periodStartDayMigration menambahkan tanggal awal periode budget per user.
+// Kolomnya dilewati jika sudah ada, misalnya di database yang dibuat dengan
+// AutoMigrate model terbaru.
+var periodStartDayMigration = Migration{
+	Version: 4,
+	Name:    "period_start_day",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&models.User{}, "PeriodStartDay") {
+			return nil
+		}
+		return tx.Migrator().AddColumn(&models.User{}, "PeriodStartDay")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&models.User{}, "PeriodStartDay")
+	},
+}
@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// legacyOffset adalah offset zona waktu lama (loc=Asia/Jakarta) yang dipakai
+// MySQL untuk menyimpan kolom DATETIME sebelum semua timestamp disimpan UTC
+const legacyOffset = "+07:00"
+
+// calendarDateColumns adalah kolom tanggal tanpa jam yang disimpan sebagai
+// tengah malam UTC, jadi tidak ikut digeser
+var calendarDateColumns = map[string]bool{
+	"loans.start_date":        true,
+	"loan_schedules.due_date": true,
+	"goals.target_date":       true,
+	"exchange_rates.date":     true,
+}
+
+// timeTypes adalah tipe field yang disimpan sebagai DATETIME. IndirectFieldType
+// *time.Time adalah time.Time.
+var timeTypes = map[reflect.Type]bool{
+	reflect.TypeOf(time.Time{}):      true,
+	reflect.TypeOf(gorm.DeletedAt{}): true,
+}
+
+// userTimeZoneMigration menambahkan zona waktu dan locale user. Di MySQL,
+// DATETIME lama yang tersimpan dalam WIB digeser ke UTC; Postgres memakai
+// timestamptz dan SQLite menyimpan offset, jadi keduanya tidak perlu diubah.
+var userTimeZoneMigration = Migration{
+	Version: 5,
+	Name:    "user_time_zone",
+	Up: func(tx *gorm.DB) error {
+		for _, column := range []string{"TimeZone", "Locale"} {
+			if tx.Migrator().HasColumn(&models.User{}, column) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(&models.User{}, column); err != nil {
+				return err
+			}
+		}
+		return shiftDateTimeColumns(tx, legacyOffset, "+00:00")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := shiftDateTimeColumns(tx, "+00:00", legacyOffset); err != nil {
+			return err
+		}
+		for _, column := range []string{"Locale", "TimeZone"} {
+			if err := tx.Migrator().DropColumn(&models.User{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// shiftDateTimeColumns mengubah semua kolom waktu MySQL dari offset from ke
+// offset to
+func shiftDateTimeColumns(tx *gorm.DB, from, to string) error {
+	if tx.Dialector.Name() != "mysql" {
+		return nil
+	}
+
+	tables := append(append([]interface{}{}, initialTables...), &models.BudgetTemplate{}, &models.BudgetTemplateItem{})
+	for _, model := range tables {
+		table, columns, err := dateTimeColumns(tx, model)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		assignments := make([]string, len(columns))
+		for i, column := range columns {
+			column = tx.Statement.Quote(column)
+			assignments[i] = column + " = CONVERT_TZ(" + column + ", '" + from + "', '" + to + "')"
+		}
+		sql := "UPDATE " + tx.Statement.Quote(table) + " SET " + strings.Join(assignments, ", ")
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dateTimeColumns mengembalikan nama tabel model dan kolom waktunya
+// (time.Time, *time.Time dan gorm.DeletedAt), kecuali kolom di
+// calendarDateColumns
+func dateTimeColumns(tx *gorm.DB, model interface{}) (string, []string, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return "", nil, err
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !timeTypes[field.IndirectFieldType] {
+			continue
+		}
+		if calendarDateColumns[stmt.Schema.Table+"."+field.DBName] {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+	return stmt.Schema.Table, columns, nil
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+// Usage adalah bantuan untuk subcommand migrate
+const Usage = `usage: moniplan migrate <command>
+
+commands:
+  status          tampilkan versi yang sudah dan belum dijalankan
+  up              jalankan semua migration yang belum dijalankan
+  down [n]        batalkan n migration terakhir (default 1)
+  to <version>    naik atau turun sampai versi tertentu (0 = batalkan semua)
+`
+
+// Run menjalankan subcommand migrate dengan args setelah kata "migrate"
+func Run(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", Usage)
+	}
+
+	log := func(line string) {
+		fmt.Fprintln(out, line)
+	}
+
+	switch args[0] {
+	case "status":
+		return printStatus(db, out)
+	case "up":
+		if err := Up(db, log); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[1])
+			}
+			steps = n
+		}
+		if err := Down(db, steps, log); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("to expects a version\n\n%s", Usage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := To(db, uint(version), log); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], Usage)
+	}
+
+	current, err := Current(db)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Schema version: %d (latest %d)\n", current, Latest())
+	return nil
+}
+
+func printStatus(db *gorm.DB, out io.Writer) error {
+	statuses, err := GetStatus(db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
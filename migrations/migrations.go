@@ -0,0 +1,247 @@
+// Package migrations mengelola versi schema database. Setiap perubahan
+// schema ditambahkan sebagai Migration baru dengan Version yang lebih besar,
+// migration yang sudah dirilis tidak boleh diubah lagi. Versi yang sudah
+// dijalankan dicatat di tabel schema_migrations.
+//
+// Migration ditulis dalam Go (GORM Migrator) agar sama untuk MySQL,
+// PostgreSQL dan SQLite. Karena baseline memakai struct model terbaru,
+// migration berikutnya harus idempotent (cek HasColumn/HasIndex dulu).
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration adalah satu versi schema. Up dan Down dijalankan di dalam
+// transaction, kecuali DDL di MySQL yang selalu auto-commit.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration mencatat migration yang sudah dijalankan
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status adalah kondisi satu migration, AppliedAt nil berarti belum dijalankan
+type Status struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+var (
+	// ErrPending dikembalikan Check jika masih ada migration yang belum dijalankan
+	ErrPending = errors.New("database schema is not up to date, run `migrate up` first")
+
+	// ErrUnknownVersion dikembalikan jika database berisi versi yang tidak
+	// dikenal build ini, biasanya karena database dimigrasi oleh versi yang lebih baru
+	ErrUnknownVersion = errors.New("database schema has migrations unknown to this build")
+)
+
+// All adalah daftar migration, diurutkan berdasarkan Version
+var All = []Migration{
+	initialSchema,
+	financeConstraintsMigration,
+	budgetTemplatesMigration,
+	periodStartDayMigration,
+	userTimeZoneMigration,
+}
+
+func init() {
+	sort.Slice(All, func(i, j int) bool { return All[i].Version < All[j].Version })
+}
+
+// applied mengambil migration yang sudah dijalankan, dipetakan per versi
+func applied(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// GetStatus mengembalikan status semua migration yang dikenal
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(All))
+	for _, m := range All {
+		status := Status{Version: m.Version, Name: m.Name}
+		if row, ok := done[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Current mengembalikan versi tertinggi yang sudah dijalankan (0 jika belum ada)
+func Current(db *gorm.DB) (uint, error) {
+	done, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var current uint
+	for version := range done {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Latest mengembalikan versi migration terbaru di build ini
+func Latest() uint {
+	if len(All) == 0 {
+		return 0
+	}
+	return All[len(All)-1].Version
+}
+
+// Check memastikan semua migration sudah dijalankan, dipanggil saat startup
+func Check(db *gorm.DB) error {
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+
+	known := map[uint]bool{}
+	for _, m := range All {
+		known[m.Version] = true
+		if _, ok := done[m.Version]; !ok {
+			return fmt.Errorf("%w (pending version %d %s)", ErrPending, m.Version, m.Name)
+		}
+	}
+	for version := range done {
+		if !known[version] {
+			return fmt.Errorf("%w (version %d)", ErrUnknownVersion, version)
+		}
+	}
+	return nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan
+func Up(db *gorm.DB, log func(string)) error {
+	return To(db, Latest(), log)
+}
+
+// Down membatalkan steps migration terakhir
+func Down(db *gorm.DB, steps int, log func(string)) error {
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(All) - 1; i >= 0 && steps > 0; i-- {
+		if _, ok := done[All[i].Version]; !ok {
+			continue
+		}
+		if err := rollback(db, All[i], log); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+// To menjalankan atau membatalkan migration sampai versi target. Target 0
+// membatalkan semua migration.
+func To(db *gorm.DB, target uint, log func(string)) error {
+	if target != 0 && !isKnown(target) {
+		return fmt.Errorf("unknown migration version %d", target)
+	}
+
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+
+	// Batalkan dulu versi di atas target, dari yang terbaru
+	for i := len(All) - 1; i >= 0; i-- {
+		m := All[i]
+		if _, ok := done[m.Version]; ok && m.Version > target {
+			if err := rollback(db, m, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, m := range All {
+		if _, ok := done[m.Version]; !ok && m.Version <= target {
+			if err := apply(db, m, log); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isKnown(version uint) bool {
+	for _, m := range All {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func apply(db *gorm.DB, m Migration, log func(string)) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s up: %w", m.Version, m.Name, err)
+	}
+	log(fmt.Sprintf("✅ Applied migration %d %s", m.Version, m.Name))
+	return nil
+}
+
+func rollback(db *gorm.DB, m Migration, log func(string)) error {
+	if m.Down == nil {
+		return fmt.Errorf("migration %d %s cannot be rolled back", m.Version, m.Name)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, m.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s down: %w", m.Version, m.Name, err)
+	}
+	log(fmt.Sprintf("↩️  Rolled back migration %d %s", m.Version, m.Name))
+	return nil
+}
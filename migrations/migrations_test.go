@@ -0,0 +1,220 @@
+package migrations
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database SQLite kosong
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{
+		Driver: database.DriverSQLite,
+		Name:   filepath.Join(t.TempDir(), "moniplan.db"),
+	}, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestUpDown(t *testing.T) {
+	db := openTestDB(t)
+	quiet := func(string) {}
+
+	if err := Check(db); err == nil {
+		t.Fatal("Check on an empty database succeeded, want pending error")
+	}
+
+	if err := Up(db, quiet); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := Check(db); err != nil {
+		t.Fatalf("Check after Up: %v", err)
+	}
+	if current, _ := Current(db); current != Latest() {
+		t.Errorf("Current = %d, want %d", current, Latest())
+	}
+
+	// Menjalankan ulang tidak mengubah apa pun
+	if err := Up(db, quiet); err != nil {
+		t.Fatalf("Up again: %v", err)
+	}
+
+	if err := To(db, 0, quiet); err != nil {
+		t.Fatalf("To(0): %v", err)
+	}
+	if current, _ := Current(db); current != 0 {
+		t.Errorf("Current after To(0) = %d, want 0", current)
+	}
+	if db.Migrator().HasTable("transactions") {
+		t.Error("transactions table still exists after To(0)")
+	}
+
+	if err := Up(db, quiet); err != nil {
+		t.Fatalf("Up after To(0): %v", err)
+	}
+}
+
+// currentModels adalah semua model yang tabelnya dibuat oleh migration
+var currentModels = []interface{}{
+	&models.User{},
+	&models.Category{},
+	&models.Budget{},
+	&models.BudgetTemplate{},
+	&models.BudgetTemplateItem{},
+	&models.Transaction{},
+	&models.Goal{},
+	&models.Loan{},
+	&models.LoanSchedule{},
+	&models.Bill{},
+	&models.BillPayment{},
+	&models.BillReminder{},
+	&models.AlertRule{},
+	&models.BudgetAlertLog{},
+	&models.Notification{},
+	&models.Webhook{},
+	&models.WebhookDelivery{},
+	&models.TransactionRule{},
+	&models.Payee{},
+	&models.PayeeAlias{},
+	&models.ExchangeRate{},
+	&models.Attachment{},
+	&models.AuditLog{},
+}
+
+// TestUpMatchesModels memastikan schema hasil migration punya semua kolom,
+// index dan foreign key yang didefinisikan model
+func TestUpMatchesModels(t *testing.T) {
+	db := openTestDB(t)
+	if err := Up(db, func(string) {}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	migrator := db.Migrator()
+	for _, model := range currentModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("Parse %T: %v", model, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+				t.Errorf("%s: missing column %s", stmt.Table, field.DBName)
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(model, index.Name) {
+				t.Errorf("%s: missing index %s", stmt.Table, index.Name)
+			}
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if constraint := rel.ParseConstraint(); constraint != nil && constraint.Schema == stmt.Schema &&
+				!migrator.HasConstraint(model, constraint.Name) {
+				t.Errorf("%s: missing constraint %s", stmt.Table, constraint.Name)
+			}
+		}
+	}
+}
+
+// TestUpLegacyDatabase menjalankan migration di database lama hasil
+// AutoMigrate yang berisi category dengan nama sama, budget dobel dan
+// transaction yang category atau payee-nya sudah tidak ada
+func TestUpLegacyDatabase(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(initialTables...); err != nil {
+		t.Fatalf("AutoMigrate baseline: %v", err)
+	}
+
+	date := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	payeeID := uint(99)
+	rows := []interface{}{
+		&baselineUser{ID: 1, Name: "Budi", Email: "budi@example.com", Password: "secret", BaseCurrency: "IDR"},
+		&baselineCategory{ID: 1, UserID: 1, Name: "Food"},
+		&baselineCategory{ID: 2, UserID: 1, Name: "food"},
+		&baselineBudget{ID: 1, UserID: 1, CategoryID: 1, Year: 2026, Month: 5, Amount: 100},
+		&baselineBudget{ID: 2, UserID: 1, CategoryID: 2, Year: 2026, Month: 5, Amount: 200},
+		&baselineTransaction{ID: 1, UserID: 1, CategoryID: 2, Amount: 50, Currency: "IDR", Type: "expense", Remarks: "lunch", TransactionDate: date},
+		&baselineTransaction{ID: 2, UserID: 1, CategoryID: 42, PayeeID: &payeeID, Amount: 70, Currency: "IDR", Type: "expense", Remarks: "taxi", TransactionDate: date},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("Create %T: %v", row, err)
+		}
+	}
+
+	if err := Up(db, func(string) {}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	var categories []models.Category
+	if err := db.Order("id ASC").Find(&categories).Error; err != nil {
+		t.Fatalf("Find categories: %v", err)
+	}
+	if len(categories) != 2 || categories[0].ID != 1 || categories[1].Name != models.UncategorizedCategoryName {
+		t.Fatalf("categories = %+v, want Food and %s", categories, models.UncategorizedCategoryName)
+	}
+
+	var budgets []models.Budget
+	if err := db.Find(&budgets).Error; err != nil {
+		t.Fatalf("Find budgets: %v", err)
+	}
+	if len(budgets) != 1 || budgets[0].CategoryID != 1 {
+		t.Errorf("budgets = %+v, want one budget for category 1", budgets)
+	}
+
+	var transactions []models.Transaction
+	if err := db.Order("id ASC").Find(&transactions).Error; err != nil {
+		t.Fatalf("Find transactions: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(transactions))
+	}
+	if transactions[0].CategoryID != 1 {
+		t.Errorf("transaction 1 category = %d, want 1", transactions[0].CategoryID)
+	}
+	if transactions[1].CategoryID != categories[1].ID || transactions[1].PayeeID != nil {
+		t.Errorf("transaction 2 = category %d payee %v, want category %d without payee",
+			transactions[1].CategoryID, transactions[1].PayeeID, categories[1].ID)
+	}
+
+	if !db.Migrator().HasIndex(&models.Category{}, "idx_categories_user_name") {
+		t.Error("idx_categories_user_name not created")
+	}
+	if err := db.Create(&models.Category{UserID: 1, Name: "Food"}).Error; err == nil {
+		t.Error("creating a duplicate category succeeded, want unique constraint error")
+	}
+}
+
+func TestDateTimeColumns(t *testing.T) {
+	db := openTestDB(t)
+	cases := []struct {
+		model interface{}
+		want  []string
+	}{
+		{&baselineCategory{}, []string{"created_at", "updated_at", "deleted_at"}},
+		{&baselineWebhookDelivery{}, []string{"next_attempt_at", "delivered_at", "created_at", "updated_at"}},
+		{&baselineLoan{}, []string{"created_at", "updated_at"}},
+	}
+	for _, tc := range cases {
+		table, columns, err := dateTimeColumns(db, tc.model)
+		if err != nil {
+			t.Fatalf("dateTimeColumns(%T): %v", tc.model, err)
+		}
+		if strings.Join(columns, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s columns = %v, want %v", table, columns, tc.want)
+		}
+	}
+}
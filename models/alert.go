@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"ashborn.id/moniplan/money"
+)
+
+// Channel pengiriman alert selain inbox in-app
+const (
+	AlertChannelInApp   = "in_app"
+	AlertChannelWebhook = "webhook"
+	AlertChannelEmail   = "email"
+)
+
+// AlertRule memicu notifikasi ketika spending sebuah category dalam satu
+// bulan mencapai Threshold persen dari Budget.Amount bulan tersebut
+type AlertRule struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	CategoryID uint      `json:"category_id" gorm:"not null;index"`
+	Threshold  uint      `json:"threshold" gorm:"not null"`
+	Channel    string    `json:"channel" gorm:"not null;size:20;default:in_app"`
+	Target     string    `json:"target" gorm:"size:255"` // URL webhook atau alamat email, kosong = email user
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// BudgetAlertLog mencatat rule yang sudah terpicu pada suatu bulan, sehingga
+// setiap threshold hanya terpicu sekali per bulan. RuleID 0 menandakan event
+// budget.exceeded untuk category tersebut.
+type BudgetAlertLog struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	RuleID     uint         `json:"rule_id" gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	CategoryID uint         `json:"category_id" gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Year       uint         `json:"year" gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Month      uint         `json:"month" gorm:"not null;uniqueIndex:idx_budget_alert_logs_rule_period"`
+	Spent      money.Amount `json:"spent" gorm:"not null"`
+	Budget     money.Amount `json:"budget" gorm:"not null"`
+	FiredAt    time.Time    `json:"fired_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func (BudgetAlertLog) TableName() string {
+	return "budget_alert_logs"
+}
+
+// Notification adalah pesan di inbox in-app milik user
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Type      string     `json:"type" gorm:"not null;size:50"`
+	Title     string     `json:"title" gorm:"not null;size:255"`
+	Body      string     `json:"body" gorm:"type:text"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment adalah file (foto struk, invoice PDF, ...) yang dilampirkan ke
+// transaction. File disimpan di storage dengan StorageKey, thumbnail hanya
+// ada untuk gambar.
+type Attachment struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null;index"`
+	FileName      string    `json:"file_name" gorm:"not null;size:255"`
+	ContentType   string    `json:"content_type" gorm:"not null;size:100"`
+	Size          int64     `json:"size" gorm:"not null"`
+	Checksum      string    `json:"checksum" gorm:"not null;size:64"`
+	StorageKey    string    `json:"-" gorm:"not null;size:255"`
+	ThumbnailKey  string    `json:"-" gorm:"size:255"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// PublicAttachment adalah Attachment beserta URL download sementara
+type PublicAttachment struct {
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"user_id"`
+	TransactionID uint       `json:"transaction_id"`
+	FileName      string     `json:"file_name"`
+	ContentType   string     `json:"content_type"`
+	Size          int64      `json:"size"`
+	Checksum      string     `json:"checksum"`
+	HasThumbnail  bool       `json:"has_thumbnail"`
+	URL           string     `json:"url,omitempty"`
+	ThumbnailURL  string     `json:"thumbnail_url,omitempty"`
+	URLExpiresAt  *time.Time `json:"url_expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (a *Attachment) ToPublicAttachment() PublicAttachment {
+	return PublicAttachment{
+		ID:            a.ID,
+		UserID:        a.UserID,
+		TransactionID: a.TransactionID,
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		Checksum:      a.Checksum,
+		HasThumbnail:  a.ThumbnailKey != "",
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+	}
+}
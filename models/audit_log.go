@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Action pada audit log
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// ErrAuditLogImmutable dikembalikan jika ada yang mencoba mengubah atau
+// menghapus audit log
+var ErrAuditLogImmutable = errors.New("audit log is append-only")
+
+// AuditLog mencatat satu perubahan pada satu baris data. UserID adalah
+// pemilik data, ActorID adalah user yang melakukan perubahan (kosong untuk
+// background job). Before, After dan Changes berisi JSON.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	ActorID    *uint     `json:"actor_id" gorm:"index"`
+	Action     string    `json:"action" gorm:"not null;size:20"`
+	EntityType string    `json:"entity_type" gorm:"not null;size:50;index:idx_audit_logs_entity"`
+	EntityID   uint      `json:"entity_id" gorm:"not null;index:idx_audit_logs_entity"`
+	Before     string    `json:"-" gorm:"type:text"`
+	After      string    `json:"-" gorm:"type:text"`
+	Changes    string    `json:"-" gorm:"type:text"`
+	IP         string    `json:"ip" gorm:"size:45"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// PublicAuditLog adalah AuditLog dengan Before, After dan Changes sebagai JSON
+type PublicAuditLog struct {
+	ID         uint            `json:"id"`
+	UserID     uint            `json:"user_id"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uint            `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Changes    json.RawMessage `json:"changes"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func (a *AuditLog) ToPublicAuditLog() PublicAuditLog {
+	return PublicAuditLog{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		ActorID:    a.ActorID,
+		Action:     a.Action,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Before:     rawJSON(a.Before),
+		After:      rawJSON(a.After),
+		Changes:    rawJSON(a.Changes),
+		IP:         a.IP,
+		UserAgent:  a.UserAgent,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
+}
@@ -0,0 +1,320 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+// Bill adalah tagihan berulang (listrik, internet, asuransi). DueRule
+// menentukan kapan tagihan jatuh tempo, lihat ParseDueRule.
+type Bill struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	UserID     uint         `json:"user_id" gorm:"not null;index"`
+	CategoryID uint         `json:"category_id" gorm:"not null"`
+	Payee      string       `json:"payee" gorm:"not null;size:100"`
+	Amount     money.Amount `json:"amount" gorm:"not null"`
+	IsEstimate bool         `json:"is_estimate" gorm:"not null;default:false"`
+	DueRule    string       `json:"due_rule" gorm:"not null;size:50"`
+	Autopay    bool         `json:"autopay" gorm:"not null;default:false"`
+	StartDate  time.Time    `json:"start_date"`
+	Active     bool         `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+func (Bill) TableName() string {
+	return "bills"
+}
+
+// BillPayment mencatat bahwa tagihan untuk due date tertentu sudah dibayar
+// melalui sebuah transaction
+type BillPayment struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	BillID        uint         `json:"bill_id" gorm:"not null;index"`
+	DueDate       time.Time    `json:"due_date"`
+	TransactionID uint         `json:"transaction_id" gorm:"not null"`
+	Amount        money.Amount `json:"amount" gorm:"not null"`
+	PaidAt        time.Time    `json:"paid_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+func (BillPayment) TableName() string {
+	return "bill_payments"
+}
+
+// BillReminder mencatat reminder yang sudah dikirim agar setiap due date
+// hanya diingatkan satu kali
+type BillReminder struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BillID    uint      `json:"bill_id" gorm:"not null;index"`
+	DueDate   time.Time `json:"due_date"`
+	SentAt    time.Time `json:"sent_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (BillReminder) TableName() string {
+	return "bill_reminders"
+}
+
+// ErrBillAlreadyPaid dikembalikan MarkPaid jika due date sudah dibayar
+var ErrBillAlreadyPaid = errors.New("bill is already paid for this due date")
+
+// Status tagihan di calendar
+const (
+	BillStatusPaid     = "paid"
+	BillStatusOverdue  = "overdue"
+	BillStatusUpcoming = "upcoming"
+)
+
+// BillOccurrence adalah satu jatuh tempo tagihan di calendar
+type BillOccurrence struct {
+	BillID        uint         `json:"bill_id"`
+	Payee         string       `json:"payee"`
+	CategoryID    uint         `json:"category_id"`
+	Amount        money.Amount `json:"amount"`
+	IsEstimate    bool         `json:"is_estimate"`
+	Autopay       bool         `json:"autopay"`
+	DueDate       time.Time    `json:"due_date"`
+	Status        string       `json:"status"`
+	TransactionID *uint        `json:"transaction_id"`
+}
+
+// Jenis due rule yang didukung
+const (
+	DueRuleOnce    = "once"
+	DueRuleWeekly  = "weekly"
+	DueRuleMonthly = "monthly"
+	DueRuleYearly  = "yearly"
+)
+
+// DueRule adalah hasil parse dari Bill.DueRule
+type DueRule struct {
+	Kind    string
+	Day     int        // Tanggal (monthly, yearly) atau weekday 0-6 (weekly)
+	Month   time.Month // Hanya untuk yearly
+	OnceDay time.Time  // Hanya untuk once
+}
+
+// ParseDueRule mem-parse due rule dengan format:
+//
+//	monthly:25      setiap tanggal 25 (dipotong ke akhir bulan jika perlu)
+//	weekly:1        setiap hari Senin (0 = Minggu)
+//	yearly:12-25    setiap 25 Desember
+//	once:2026-11-01 satu kali pada tanggal tersebut
+func ParseDueRule(rule string) (DueRule, error) {
+	kind, value, found := strings.Cut(strings.TrimSpace(strings.ToLower(rule)), ":")
+	if !found || value == "" {
+		return DueRule{}, fmt.Errorf("due rule must be in format <kind>:<value>")
+	}
+
+	switch kind {
+	case DueRuleMonthly:
+		day, err := strconv.Atoi(value)
+		if err != nil || day < 1 || day > 31 {
+			return DueRule{}, fmt.Errorf("monthly due rule day must be between 1 and 31")
+		}
+		return DueRule{Kind: kind, Day: day}, nil
+	case DueRuleWeekly:
+		weekday, err := strconv.Atoi(value)
+		if err != nil || weekday < 0 || weekday > 6 {
+			return DueRule{}, fmt.Errorf("weekly due rule weekday must be between 0 and 6")
+		}
+		return DueRule{Kind: kind, Day: weekday}, nil
+	case DueRuleYearly:
+		date, err := time.Parse("01-02", value)
+		if err != nil {
+			return DueRule{}, fmt.Errorf("yearly due rule must be in format MM-DD")
+		}
+		return DueRule{Kind: kind, Day: date.Day(), Month: date.Month()}, nil
+	case DueRuleOnce:
+		// Tanggal kalender, zona waktunya diambil dari rentang di Occurrences
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return DueRule{}, fmt.Errorf("once due rule must be in format YYYY-MM-DD")
+		}
+		return DueRule{Kind: kind, OnceDay: date}, nil
+	}
+
+	return DueRule{}, fmt.Errorf("unknown due rule kind %q", kind)
+}
+
+// Occurrences mengembalikan semua due date di antara from dan to (inklusif).
+// Due date dihitung di zona waktu from.
+func (r DueRule) Occurrences(from, to time.Time) []time.Time {
+	var dates []time.Time
+	from = startOfDay(from)
+	to = startOfDay(to.In(from.Location()))
+
+	switch r.Kind {
+	case DueRuleOnce:
+		day := time.Date(r.OnceDay.Year(), r.OnceDay.Month(), r.OnceDay.Day(), 0, 0, 0, 0, from.Location())
+		if !day.Before(from) && !day.After(to) {
+			dates = append(dates, day)
+		}
+	case DueRuleWeekly:
+		offset := (r.Day - int(from.Weekday()) + 7) % 7
+		for day := from.AddDate(0, 0, offset); !day.After(to); day = day.AddDate(0, 0, 7) {
+			dates = append(dates, day)
+		}
+	case DueRuleMonthly:
+		for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+			day := clampDay(month.Year(), month.Month(), r.Day, from.Location())
+			if !day.Before(from) && !day.After(to) {
+				dates = append(dates, day)
+			}
+		}
+	case DueRuleYearly:
+		for year := from.Year(); year <= to.Year(); year++ {
+			day := clampDay(year, r.Month, r.Day, from.Location())
+			if !day.Before(from) && !day.After(to) {
+				dates = append(dates, day)
+			}
+		}
+	}
+
+	return dates
+}
+
+// Occurrences mengembalikan due date bill di antara from dan to, tidak
+// termasuk tanggal sebelum StartDate
+func (b *Bill) Occurrences(from, to time.Time) ([]time.Time, error) {
+	rule, err := ParseDueRule(b.DueRule)
+	if err != nil {
+		return nil, err
+	}
+
+	if start := startOfDay(b.StartDate.In(from.Location())); from.Before(start) {
+		from = start
+	}
+	if from.After(to) {
+		return nil, nil
+	}
+
+	return rule.Occurrences(from, to), nil
+}
+
+// MarkPaid membuat transaction untuk pembayaran tagihan pada due date
+// tertentu dan mencatatnya sebagai BillPayment
+func (b *Bill) MarkPaid(ctx context.Context, dueDate time.Time, amount money.Amount, paidAt time.Time) (BillPayment, error) {
+	payment := BillPayment{
+		BillID:  b.ID,
+		DueDate: startOfDay(dueDate).UTC(),
+		Amount:  amount,
+		PaidAt:  paidAt,
+	}
+
+	// Dibaca sebelum transaction: di dalamnya database.DB bisa menunggu koneksi
+	// yang sedang dipakai transaction ini (SQLite hanya punya satu koneksi)
+	currency := GetBaseCurrency(b.UserID)
+
+	err := database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&BillPayment{}).Where("bill_id = ? AND due_date = ?", b.ID, payment.DueDate).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrBillAlreadyPaid
+		}
+
+		now := time.Now()
+		transaction := Transaction{
+			UserID:          b.UserID,
+			CategoryID:      b.CategoryID,
+			Amount:          amount,
+			Currency:        currency,
+			Type:            TransactionTypeExpense,
+			Remarks:         b.Payee,
+			TransactionDate: paidAt,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		payment.ID = 0
+		payment.TransactionID = transaction.ID
+		return tx.Create(&payment).Error
+	})
+
+	return payment, err
+}
+
+// GetBillCalendar mengembalikan semua jatuh tempo tagihan aktif milik user
+// di antara from dan to, beserta status paid/overdue/upcoming. from, to dan
+// today sebaiknya di zona waktu user.
+func GetBillCalendar(userID uint, from, to, today time.Time) ([]BillOccurrence, error) {
+	var bills []Bill
+	if err := database.DB.Where("user_id = ? AND active = ?", userID, true).Find(&bills).Error; err != nil {
+		return nil, err
+	}
+
+	occurrences := []BillOccurrence{}
+	today = startOfDay(today)
+
+	for _, bill := range bills {
+		dates, err := bill.Occurrences(from, to)
+		if err != nil || len(dates) == 0 {
+			continue
+		}
+
+		var payments []BillPayment
+		if err := database.DB.Where("bill_id = ? AND due_date >= ? AND due_date <= ?", bill.ID, dates[0].UTC(), dates[len(dates)-1].UTC()).Find(&payments).Error; err != nil {
+			return nil, err
+		}
+
+		paid := make(map[string]uint, len(payments))
+		for _, payment := range payments {
+			paid[payment.DueDate.In(from.Location()).Format("2006-01-02")] = payment.TransactionID
+		}
+
+		for _, date := range dates {
+			occurrence := BillOccurrence{
+				BillID:     bill.ID,
+				Payee:      bill.Payee,
+				CategoryID: bill.CategoryID,
+				Amount:     bill.Amount,
+				IsEstimate: bill.IsEstimate,
+				Autopay:    bill.Autopay,
+				DueDate:    date,
+				Status:     BillStatusUpcoming,
+			}
+
+			if transactionID, ok := paid[date.Format("2006-01-02")]; ok {
+				occurrence.Status = BillStatusPaid
+				occurrence.TransactionID = &transactionID
+			} else if date.Before(today) {
+				occurrence.Status = BillStatusOverdue
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].DueDate.Before(occurrences[j].DueDate)
+	})
+	return occurrences, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// clampDay membuat tanggal dengan day dipotong ke hari terakhir bulan tersebut
+func clampDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
@@ -2,32 +2,54 @@ package models
 
 import (
 	"time"
+
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
 )
 
+// Budget unik per category dan bulan, termasuk yang ada di trash, sehingga
+// menyimpan budget untuk bulan yang sama akan mengganti nominalnya
 type Budget struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	UserID     uint      `json:"user_id" gorm:"not null"`
-	CategoryID uint      `json:"category_id" gorm:"not null"`
-	Month      uint      `json:"month" gorm:"not null"`
-	Year       uint      `json:"year" gorm:"not null"`
-	Amount     uint      `json:"amount" gorm:"not null"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_budgets_user_category_period,priority:1"`
+	CategoryID uint           `json:"category_id" gorm:"not null;uniqueIndex:idx_budgets_user_category_period,priority:2"`
+	Month      uint           `json:"month" gorm:"not null;uniqueIndex:idx_budgets_user_category_period,priority:4"`
+	Year       uint           `json:"year" gorm:"not null;uniqueIndex:idx_budgets_user_category_period,priority:3"`
+	Amount     money.Amount   `json:"amount" gorm:"not null"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	User     *User     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Category *Category `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
 
 func (Budget) TableName() string {
 	return "budgets"
 }
 
+// BudgetCategory adalah budget satu bulan beserta nama category-nya
+type BudgetCategory struct {
+	ID           uint         `json:"id"`
+	UserID       uint         `json:"user_id"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Month        uint         `json:"month"`
+	Year         uint         `json:"year"`
+	Amount       money.Amount `json:"amount"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
 type PublicBudget struct {
-	ID         uint      `json:"id"`
-	UserID     uint      `json:"user_id"`
-	CategoryID uint      `json:"category_id"`
-	Month      uint      `json:"month"`
-	Year       uint      `json:"year"`
-	Amount     uint      `json:"amount"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint         `json:"id"`
+	UserID     uint         `json:"user_id"`
+	CategoryID uint         `json:"category_id"`
+	Month      uint         `json:"month"`
+	Year       uint         `json:"year"`
+	Amount     money.Amount `json:"amount"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
 }
 
 func (c *Budget) ToPublicBudget() PublicBudget {
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"ashborn.id/moniplan/money"
+)
+
+// BudgetTemplate adalah kumpulan nominal budget per category yang bisa
+// diterapkan ke beberapa bulan sekaligus
+type BudgetTemplate struct {
+	ID        uint                 `json:"id" gorm:"primaryKey"`
+	UserID    uint                 `json:"user_id" gorm:"not null;uniqueIndex:idx_budget_templates_user_name,priority:1"`
+	Name      string               `json:"name" gorm:"not null;size:100;uniqueIndex:idx_budget_templates_user_name,priority:2"`
+	Items     []BudgetTemplateItem `json:"-" gorm:"foreignKey:TemplateID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+
+	User *User `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (BudgetTemplate) TableName() string {
+	return "budget_templates"
+}
+
+// BudgetTemplateItem adalah nominal satu category di template. Month 0 adalah
+// nominal dasar untuk semua bulan, Month 1-12 adalah override musiman untuk
+// bulan tersebut (misalnya groceries lebih besar saat Ramadan/Lebaran).
+type BudgetTemplateItem struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	TemplateID uint         `json:"template_id" gorm:"not null;uniqueIndex:idx_budget_template_items_category_month,priority:1"`
+	CategoryID uint         `json:"category_id" gorm:"not null;uniqueIndex:idx_budget_template_items_category_month,priority:2"`
+	Month      uint         `json:"month" gorm:"not null;default:0;uniqueIndex:idx_budget_template_items_category_month,priority:3"`
+	Amount     money.Amount `json:"amount" gorm:"not null"`
+
+	Category *Category `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (BudgetTemplateItem) TableName() string {
+	return "budget_template_items"
+}
+
+// AmountFor mengembalikan nominal category pada bulan month: override bulan
+// tersebut jika ada, atau nominal dasar. ok false jika category tidak ada di
+// template. seasonal true jika nominal berasal dari override.
+func (t *BudgetTemplate) AmountFor(categoryID, month uint) (amount money.Amount, seasonal, ok bool) {
+	for _, item := range t.Items {
+		if item.CategoryID != categoryID {
+			continue
+		}
+		if item.Month == month {
+			return item.Amount, true, true
+		}
+		if item.Month == 0 {
+			amount, ok = item.Amount, true
+		}
+	}
+	return amount, false, ok
+}
+
+// CategoryIDs mengembalikan category di template secara berurutan
+func (t *BudgetTemplate) CategoryIDs() []uint {
+	seen := map[uint]bool{}
+	var ids []uint
+	for _, item := range t.Items {
+		if !seen[item.CategoryID] {
+			seen[item.CategoryID] = true
+			ids = append(ids, item.CategoryID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// BudgetOverride adalah nominal musiman untuk satu bulan
+type BudgetOverride struct {
+	Month  uint         `json:"month"`
+	Amount money.Amount `json:"amount"`
+}
+
+type PublicBudgetTemplateItem struct {
+	CategoryID uint             `json:"category_id"`
+	Amount     money.Amount     `json:"amount"`
+	Overrides  []BudgetOverride `json:"overrides"`
+}
+
+type PublicBudgetTemplate struct {
+	ID        uint                       `json:"id"`
+	UserID    uint                       `json:"user_id"`
+	Name      string                     `json:"name"`
+	Items     []PublicBudgetTemplateItem `json:"items"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+// ToPublicBudgetTemplate mengelompokkan item per category, override
+// diurutkan berdasarkan bulan
+func (t *BudgetTemplate) ToPublicBudgetTemplate() PublicBudgetTemplate {
+	items := []PublicBudgetTemplateItem{}
+	for _, categoryID := range t.CategoryIDs() {
+		item := PublicBudgetTemplateItem{CategoryID: categoryID, Overrides: []BudgetOverride{}}
+		for _, row := range t.Items {
+			if row.CategoryID != categoryID {
+				continue
+			}
+			if row.Month == 0 {
+				item.Amount = row.Amount
+			} else {
+				item.Overrides = append(item.Overrides, BudgetOverride{Month: row.Month, Amount: row.Amount})
+			}
+		}
+		sort.Slice(item.Overrides, func(i, j int) bool { return item.Overrides[i].Month < item.Overrides[j].Month })
+		items = append(items, item)
+	}
+
+	return PublicBudgetTemplate{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Name:      t.Name,
+		Items:     items,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// AnnualPlanCategory adalah rencana budget satu category selama setahun.
+// Months berisi nominal Januari sampai Desember (0 jika belum ada budget),
+// SeasonalMonths adalah bulan yang nominalnya berbeda dari biasanya.
+type AnnualPlanCategory struct {
+	CategoryID     uint             `json:"category_id"`
+	CategoryName   string           `json:"category_name"`
+	Months         [12]money.Amount `json:"months"`
+	SeasonalMonths []uint           `json:"seasonal_months"`
+	Total          money.Amount     `json:"total"`
+}
+
+// AnnualPlan adalah rencana budget semua category dalam satu tahun, dari
+// budget yang tersimpan atau dari template (TemplateID diisi)
+type AnnualPlan struct {
+	Year        uint                 `json:"year"`
+	TemplateID  *uint                `json:"template_id"`
+	Categories  []AnnualPlanCategory `json:"categories"`
+	MonthTotals [12]money.Amount     `json:"month_totals"`
+	Total       money.Amount         `json:"total"`
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en_AU"
+	"github.com/go-playground/locales/en_GB"
+	"github.com/go-playground/locales/en_SG"
+	"github.com/go-playground/locales/en_US"
+	"github.com/go-playground/locales/id_ID"
+	"github.com/go-playground/locales/ms_MY"
+)
+
+const (
+	// DefaultTimeZone dipakai user yang belum memilih zona waktu
+	DefaultTimeZone = "Asia/Jakarta"
+
+	// DefaultLocale dipakai user yang belum memilih locale
+	DefaultLocale = "id-ID"
+)
+
+// ErrInvalidTimeZone dikembalikan LoadTimeZone untuk nama zona IANA yang
+// tidak dikenal
+var ErrInvalidTimeZone = errors.New("time_zone must be an IANA time zone such as Asia/Jakarta")
+
+// translators adalah locale yang didukung, dengan key tag BCP 47
+var translators = map[string]locales.Translator{
+	"id-ID": id_ID.New(),
+	"en-US": en_US.New(),
+	"en-GB": en_GB.New(),
+	"en-AU": en_AU.New(),
+	"en-SG": en_SG.New(),
+	"ms-MY": ms_MY.New(),
+}
+
+var locations sync.Map
+
+// NormalizeLocale mengubah locale seperti "en_us" menjadi "en-US". Hasilnya
+// kosong jika locale tidak didukung.
+func NormalizeLocale(value string) string {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "_", "-")
+	language, region, _ := strings.Cut(value, "-")
+	tag := strings.ToLower(language) + "-" + strings.ToUpper(region)
+	if _, ok := translators[tag]; !ok {
+		return ""
+	}
+	return tag
+}
+
+// LoadTimeZone memuat zona waktu IANA. Nama kosong dan "Local" ditolak agar
+// hasilnya tidak bergantung pada zona waktu server.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimeZone
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// UserCalendar adalah pengaturan tanggal user: zona waktu untuk parsing
+// input dan menampilkan tanggal, locale untuk format tanggal, dan tanggal
+// awal periode budget. Penyimpanan tetap dalam UTC.
+type UserCalendar struct {
+	Location       *time.Location
+	Locale         string
+	PeriodStartDay int
+}
+
+// DefaultCalendar adalah pengaturan untuk user yang tidak ditemukan
+func DefaultCalendar() UserCalendar {
+	return (&User{}).Calendar()
+}
+
+// Calendar mengembalikan pengaturan tanggal user, nilai yang tidak valid
+// diganti dengan default
+func (u *User) Calendar() UserCalendar {
+	location, err := LoadTimeZone(u.TimeZone)
+	if err != nil {
+		location, err = LoadTimeZone(DefaultTimeZone)
+		if err != nil {
+			location = time.FixedZone("WIB", 7*60*60)
+		}
+	}
+
+	locale := NormalizeLocale(u.Locale)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
+	return UserCalendar{
+		Location:       location,
+		Locale:         locale,
+		PeriodStartDay: NormalizePeriodStartDay(u.PeriodStartDay),
+	}
+}
+
+// GetUserCalendar mengambil pengaturan tanggal user dari database
+func GetUserCalendar(userID uint) UserCalendar {
+	var user User
+	if err := database.DB.Select("id", "period_start_day", "time_zone", "locale").First(&user, userID).Error; err != nil {
+		return DefaultCalendar()
+	}
+	return user.Calendar()
+}
+
+// In mengubah t ke zona waktu user
+func (c UserCalendar) In(t time.Time) time.Time {
+	return t.In(c.Location)
+}
+
+// Today mengembalikan tengah malam hari ini di zona waktu user
+func (c UserCalendar) Today(now time.Time) time.Time {
+	year, month, day := now.In(c.Location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, c.Location)
+}
+
+// PeriodRange mengembalikan periode budget year/month di zona waktu user
+func (c UserCalendar) PeriodRange(year, month uint) BudgetPeriod {
+	return PeriodRange(year, month, c.PeriodStartDay, c.Location)
+}
+
+// Periods mengembalikan count periode budget berurutan mulai dari periode
+// year/month
+func (c UserCalendar) Periods(year, month uint, count int) []BudgetPeriod {
+	first := time.Date(int(year), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	periods := make([]BudgetPeriod, 0, count)
+	for i := 0; i < count; i++ {
+		next := first.AddDate(0, i, 0)
+		periods = append(periods, c.PeriodRange(uint(next.Year()), uint(next.Month())))
+	}
+	return periods
+}
+
+// PeriodOf mengembalikan periode budget yang memuat t
+func (c UserCalendar) PeriodOf(t time.Time) BudgetPeriod {
+	return PeriodOf(t, c.PeriodStartDay, c.Location)
+}
+
+// FormatDate memformat tanggal sesuai locale user, misalnya
+// "Minggu, 03 Mei 2026" (id-ID) atau "Sunday, May 3, 2026" (en-US)
+func (c UserCalendar) FormatDate(t time.Time) string {
+	return c.translator().FmtDateFull(c.In(t))
+}
+
+// FormatDateTime memformat tanggal dan jam sesuai locale user, misalnya
+// "Minggu, 03 Mei 2026 10.00" (id-ID) atau "Sunday, May 3, 2026 10:00 AM"
+// (en-US)
+func (c UserCalendar) FormatDateTime(t time.Time) string {
+	t = c.In(t)
+	translator := c.translator()
+	return translator.FmtDateFull(t) + " " + translator.FmtTimeShort(t)
+}
+
+func (c UserCalendar) translator() locales.Translator {
+	if translator, ok := translators[c.Locale]; ok {
+		return translator
+	}
+	return translators[DefaultLocale]
+}
@@ -4,26 +4,37 @@ import (
 	"time"
 
 	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
 )
 
+// UncategorizedCategoryName adalah category fallback untuk transaction yang
+// tidak punya category dari user, rule maupun saran history
+const UncategorizedCategoryName = "uncategorized"
+
+// Category unik per user berdasarkan nama, termasuk yang ada di trash,
+// sehingga membuat category dengan nama yang sama akan memakai baris lama
 type Category struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null"`
-	Name      string    `json:"name" gorm:"not null;size:100"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_categories_user_name,priority:1"`
+	Name      string         `json:"name" gorm:"not null;size:100;uniqueIndex:idx_categories_user_name,priority:2"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	User *User `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 type CategoryAndBudget struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	CategoryID uint      `json:"category_id" gorm:"primaryKey"`
-	UserID     uint      `json:"user_id" gorm:"not null"`
-	Name       string    `json:"name" gorm:"not null;size:100"`
-	Month      uint      `json:"month" gorm:"not null"`
-	Year       uint      `json:"year" gorm:"not null"`
-	Amount     uint      `json:"amount" gorm:"not null"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	CategoryID uint         `json:"category_id" gorm:"primaryKey"`
+	UserID     uint         `json:"user_id" gorm:"not null"`
+	Name       string       `json:"name" gorm:"not null;size:100"`
+	Month      uint         `json:"month" gorm:"not null"`
+	Year       uint         `json:"year" gorm:"not null"`
+	Amount     money.Amount `json:"amount" gorm:"not null"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
 }
 
 func (Category) TableName() string {
@@ -77,8 +88,8 @@ func (c *CategoryAndBudget) GetCategoryAndBudgetByID(categoryID uint) CategoryAn
 		UserID:     category.UserID,
 		Name:       category.Name,
 		Month:      budget.Month,
-		Year:       budget.Month,
-		Amount:     budget.Month,
+		Year:       budget.Year,
+		Amount:     budget.Amount,
 		CreatedAt:  category.CreatedAt,
 		UpdatedAt:  category.UpdatedAt,
 	}
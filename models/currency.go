@@ -0,0 +1,315 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCurrency adalah currency untuk data lama yang belum punya currency
+const DefaultCurrency = "IDR"
+
+// Sumber exchange rate
+const (
+	RateSourceManual = "manual"
+	RateSourceCSV    = "csv"
+	RateSourceECB    = "ecb"
+)
+
+// ErrMissingExchangeRate dikembalikan jika tidak ada rate untuk konversi
+var ErrMissingExchangeRate = errors.New("exchange rate not found")
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency mengubah kode currency ke uppercase, kosong menjadi fallback
+func NormalizeCurrency(code, fallback string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return fallback
+	}
+	return code
+}
+
+// IsValidCurrency mengecek format kode currency ISO 4217 (tiga huruf)
+func IsValidCurrency(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// ExchangeRate menyatakan 1 Base = Rate Quote pada tanggal Date
+type ExchangeRate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_exchange_rates_pair_date"`
+	Base      string    `json:"base" gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_pair_date"`
+	Quote     string    `json:"quote" gorm:"not null;size:3;uniqueIndex:idx_exchange_rates_pair_date"`
+	Date      time.Time `json:"date" gorm:"not null;type:date;uniqueIndex:idx_exchange_rates_pair_date"`
+	Rate      float64   `json:"rate" gorm:"not null;type:decimal(20,10)"`
+	Source    string    `json:"source" gorm:"not null;size:20;default:manual"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// UpsertExchangeRates menyimpan rates, menimpa rate yang sudah ada untuk
+// pasangan currency dan tanggal yang sama
+func UpsertExchangeRates(rates []ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	// Semua batch disimpan dalam satu transaction agar import tidak
+	// tersimpan setengah jika salah satu batch gagal
+	return database.Transaction(database.DB, func(tx *gorm.DB) error {
+		for i := range rates {
+			rates[i].ID = 0
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "base"}, {Name: "quote"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+		}).CreateInBatches(&rates, 500).Error
+	})
+}
+
+// GetBaseCurrency mengambil base currency user, default ke DefaultCurrency
+func GetBaseCurrency(userID uint) string {
+	var user User
+	if err := database.DB.Select("id", "base_currency").First(&user, userID).Error; err != nil || user.BaseCurrency == "" {
+		return DefaultCurrency
+	}
+	return user.BaseCurrency
+}
+
+// BaseCurrencyAmounts adalah jumlah data user yang nominalnya disimpan tanpa
+// currency, sehingga dibaca dalam base currency
+type BaseCurrencyAmounts struct {
+	Budgets          int64 `json:"budgets"` // Termasuk budget di trash
+	BudgetTemplates  int64 `json:"budget_templates"`
+	Goals            int64 `json:"goals"`
+	Loans            int64 `json:"loans"`
+	Bills            int64 `json:"bills"`
+	TransactionRules int64 `json:"transaction_rules"` // Rule dengan batas nominal
+}
+
+// Total adalah jumlah semua data
+func (a BaseCurrencyAmounts) Total() int64 {
+	return a.Budgets + a.BudgetTemplates + a.Goals + a.Loans + a.Bills + a.TransactionRules
+}
+
+// BaseCurrencyInUseError dikembalikan ChangeBaseCurrency jika masih ada data
+// yang nominalnya mengikuti base currency lama
+type BaseCurrencyInUseError struct {
+	Amounts BaseCurrencyAmounts
+}
+
+func (e *BaseCurrencyInUseError) Error() string {
+	return "base currency cannot be changed while budgets, templates, goals, loans, bills or rules with amounts exist"
+}
+
+// CountBaseCurrencyAmounts menghitung data user yang nominalnya dalam base
+// currency
+func CountBaseCurrencyAmounts(tx *gorm.DB, userID uint) (BaseCurrencyAmounts, error) {
+	var amounts BaseCurrencyAmounts
+	counts := []struct {
+		query *gorm.DB
+		count *int64
+	}{
+		{tx.Unscoped().Model(&Budget{}).Where("user_id = ?", userID), &amounts.Budgets},
+		{tx.Model(&BudgetTemplateItem{}).Where("template_id IN (?)", tx.Model(&BudgetTemplate{}).Select("id").Where("user_id = ?", userID)), &amounts.BudgetTemplates},
+		{tx.Model(&Goal{}).Where("user_id = ?", userID), &amounts.Goals},
+		{tx.Model(&Loan{}).Where("user_id = ?", userID), &amounts.Loans},
+		{tx.Model(&Bill{}).Where("user_id = ?", userID), &amounts.Bills},
+		{tx.Model(&TransactionRule{}).Where("user_id = ? AND (min_amount IS NOT NULL OR max_amount IS NOT NULL)", userID), &amounts.TransactionRules},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.count).Error; err != nil {
+			return amounts, err
+		}
+	}
+	return amounts, nil
+}
+
+// ChangeBaseCurrency mengganti base currency user. Budget, template, goal,
+// loan, bill dan batas nominal rule disimpan tanpa currency, jadi perubahan
+// ditolak dengan BaseCurrencyInUseError selama data tersebut masih ada agar
+// nominalnya tidak terbaca dalam currency (dan exponent) yang baru.
+func ChangeBaseCurrency(ctx context.Context, userID uint, currency string) error {
+	return database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Select("id", "base_currency").First(&user, userID).Error; err != nil {
+			return err
+		}
+		if NormalizeCurrency(user.BaseCurrency, DefaultCurrency) == currency {
+			return nil
+		}
+
+		amounts, err := CountBaseCurrencyAmounts(tx, userID)
+		if err != nil {
+			return err
+		}
+		if amounts.Total() > 0 {
+			return &BaseCurrencyInUseError{Amounts: amounts}
+		}
+		return tx.Model(&user).Update("base_currency", currency).Error
+	})
+}
+
+// rateKey adalah cache key untuk rate sebuah pasangan pada suatu tanggal
+type rateKey struct {
+	from, to string
+	date     string
+}
+
+// RateBook mengkonversi amount ke base currency user memakai rate yang
+// berlaku pada tanggal transaction (rate terakhir pada atau sebelum tanggal
+// tersebut). Rate dicari langsung, kebalikannya, atau lewat EUR/USD sebagai
+// pivot (misalnya data referensi ECB yang berbasis EUR). Tanggal transaction
+// dibaca di zona waktu user, karena tanggal rate disimpan tanpa jam.
+type RateBook struct {
+	UserID   uint
+	Base     string
+	Location *time.Location
+	cache    map[rateKey]float64
+}
+
+// NewRateBook membuat RateBook untuk user dengan base currency-nya
+func NewRateBook(userID uint) *RateBook {
+	return &RateBook{
+		UserID:   userID,
+		Base:     GetBaseCurrency(userID),
+		Location: GetUserCalendar(userID).Location,
+		cache:    map[rateKey]float64{},
+	}
+}
+
+// lookup mencari rate langsung atau kebalikannya, 0 jika tidak ada
+func (b *RateBook) lookup(from, to string, on time.Time) float64 {
+	on = b.rateDate(on)
+	key := rateKey{from, to, on.Format("2006-01-02")}
+	if rate, ok := b.cache[key]; ok {
+		return rate
+	}
+
+	var rate float64
+	var found ExchangeRate
+	err := database.DB.
+		Where("user_id = ? AND base = ? AND quote = ? AND date <= ?", b.UserID, from, to, on).
+		Order("date DESC").
+		First(&found).Error
+	if err == nil && found.Rate > 0 {
+		rate = found.Rate
+	} else {
+		err = database.DB.
+			Where("user_id = ? AND base = ? AND quote = ? AND date <= ?", b.UserID, to, from, on).
+			Order("date DESC").
+			First(&found).Error
+		if err == nil && found.Rate > 0 {
+			rate = 1 / found.Rate
+		}
+	}
+
+	b.cache[key] = rate
+	return rate
+}
+
+// rateDate mengubah on menjadi tanggal di zona waktu user pada tengah malam
+// UTC, sama seperti ExchangeRate.Date disimpan
+func (b *RateBook) rateDate(on time.Time) time.Time {
+	if b.Location != nil {
+		on = on.In(b.Location)
+	}
+	year, month, day := on.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Rate mengembalikan rate from → to pada tanggal on
+func (b *RateBook) Rate(from, to string, on time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if rate := b.lookup(from, to, on); rate > 0 {
+		return rate, nil
+	}
+
+	for _, pivot := range []string{"EUR", "USD"} {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first := b.lookup(from, pivot, on)
+		if first == 0 {
+			continue
+		}
+		if second := b.lookup(pivot, to, on); second > 0 {
+			return first * second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s to %s on %s", ErrMissingExchangeRate, from, to, b.rateDate(on).Format("2006-01-02"))
+}
+
+// Convert mengkonversi amount dalam currency ke base currency
+func (b *RateBook) Convert(amount money.Amount, currency string, on time.Time) (money.Amount, error) {
+	currency = NormalizeCurrency(currency, DefaultCurrency)
+	if currency == b.Base {
+		return amount, nil
+	}
+
+	rate, err := b.Rate(currency, b.Base, on)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(currency, b.Base, rate), nil
+}
+
+// foreignAmount adalah baris transaction yang currency-nya bukan base currency
+type foreignAmount struct {
+	Amount          money.Amount
+	Currency        string
+	Type            string
+	TransactionDate time.Time
+}
+
+// SumInBaseCurrency menjumlahkan amount bertanda (sesuai measure) transaction
+// user yang difilter oleh scope, dikonversi ke base currency. Transaction
+// dalam base currency dijumlahkan di database, sisanya dikonversi per tanggal
+// transaction.
+func SumInBaseCurrency(book *RateBook, measure AmountMeasure, scope func(*gorm.DB) *gorm.DB) (money.Amount, error) {
+	var total money.Amount
+	if err := database.DB.
+		Model(&Transaction{}).
+		Scopes(scope).
+		Where("type IN ?", measure.Types()).
+		Where("currency = ?", book.Base).
+		Select("COALESCE(SUM(" + measure.SQL("") + "), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+
+	var foreign []foreignAmount
+	if err := database.DB.
+		Model(&Transaction{}).
+		Scopes(scope).
+		Where("type IN ?", measure.Types()).
+		Where("currency <> ?", book.Base).
+		Select("amount", "currency", "type", "transaction_date").
+		Scan(&foreign).Error; err != nil {
+		return 0, err
+	}
+
+	for _, row := range foreign {
+		converted, err := book.Convert(measure.Apply(row.Type, row.Amount), row.Currency, row.TransactionDate)
+		if err != nil {
+			return 0, err
+		}
+		total += converted
+	}
+	return total, nil
+}
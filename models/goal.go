@@ -0,0 +1,141 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+
+	"ashborn.id/moniplan/money"
+)
+
+// Goal adalah target tabungan (mobil, dana darurat, liburan) yang di-link ke
+// sebuah category. Setiap transaction pada category tersebut dihitung sebagai
+// kontribusi ke goal.
+type Goal struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	UserID       uint         `json:"user_id" gorm:"not null;index"`
+	CategoryID   uint         `json:"category_id" gorm:"not null"`
+	Name         string       `json:"name" gorm:"not null;size:100"`
+	TargetAmount money.Amount `json:"target_amount" gorm:"not null"`
+	TargetDate   time.Time    `json:"target_date"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func (Goal) TableName() string {
+	return "goals"
+}
+
+type PublicGoal struct {
+	ID           uint         `json:"id"`
+	UserID       uint         `json:"user_id"`
+	CategoryID   uint         `json:"category_id"`
+	Name         string       `json:"name"`
+	TargetAmount money.Amount `json:"target_amount"`
+	TargetDate   time.Time    `json:"target_date"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func (g *Goal) ToPublicGoal() PublicGoal {
+	return PublicGoal{
+		ID:           g.ID,
+		UserID:       g.UserID,
+		CategoryID:   g.CategoryID,
+		Name:         g.Name,
+		TargetAmount: g.TargetAmount,
+		TargetDate:   g.TargetDate,
+		CreatedAt:    g.CreatedAt,
+		UpdatedAt:    g.UpdatedAt,
+	}
+}
+
+// GoalProgress adalah ringkasan progress sebuah goal pada waktu tertentu
+type GoalProgress struct {
+	Goal                        PublicGoal   `json:"goal"`
+	SavedAmount                 money.Amount `json:"saved_amount"`
+	RemainingAmount             money.Amount `json:"remaining_amount"`
+	Percentage                  float64      `json:"percentage"`
+	MonthsRemaining             int          `json:"months_remaining"`
+	RequiredMonthlyContribution money.Amount `json:"required_monthly_contribution"`
+	AverageMonthlyContribution  money.Amount `json:"average_monthly_contribution"`
+	ProjectionMonths            int          `json:"projection_months"`
+	ProjectedCompletionDate     *time.Time   `json:"projected_completion_date"`
+	OnTrack                     bool         `json:"on_track"`
+}
+
+// GetProgress menghitung progress goal berdasarkan transaction di category
+// goal. Rata-rata kontribusi diambil dari `months` bulan terakhir sebelum now.
+func (g *Goal) GetProgress(months int, now time.Time) (GoalProgress, error) {
+	if months < 1 {
+		months = 1
+	}
+
+	book := NewRateBook(g.UserID)
+	saved, err := SumInBaseCurrency(book, SavingsMeasure, func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND category_id = ?", g.UserID, g.CategoryID)
+	})
+	if err != nil {
+		return GoalProgress{}, err
+	}
+
+	windowStart := now.AddDate(0, -months, 0)
+	recent, err := SumInBaseCurrency(book, SavingsMeasure, func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND category_id = ? AND transaction_date >= ? AND transaction_date <= ?", g.UserID, g.CategoryID, windowStart, now)
+	})
+	if err != nil {
+		return GoalProgress{}, err
+	}
+
+	progress := GoalProgress{
+		Goal:                       g.ToPublicGoal(),
+		SavedAmount:                saved,
+		AverageMonthlyContribution: recent / money.Amount(months),
+		ProjectionMonths:           months,
+	}
+
+	if saved < g.TargetAmount {
+		progress.RemainingAmount = g.TargetAmount - saved
+	}
+
+	if g.TargetAmount > 0 {
+		progress.Percentage = math.Round(float64(saved)/float64(g.TargetAmount)*10000) / 100
+	}
+
+	// Jumlah bulan tersisa sampai target date, minimal 1 bulan agar
+	// kontribusi yang dibutuhkan tidak dibagi dengan nol
+	progress.MonthsRemaining = monthsBetween(now, g.TargetDate)
+	divisor := progress.MonthsRemaining
+	if divisor < 1 {
+		divisor = 1
+	}
+	progress.RequiredMonthlyContribution = money.Amount(math.Ceil(float64(progress.RemainingAmount) / float64(divisor)))
+
+	if progress.RemainingAmount == 0 {
+		completed := now
+		progress.ProjectedCompletionDate = &completed
+		progress.OnTrack = true
+	} else if progress.AverageMonthlyContribution > 0 {
+		needed := int(math.Ceil(float64(progress.RemainingAmount) / float64(progress.AverageMonthlyContribution)))
+		projected := now.AddDate(0, needed, 0)
+		progress.ProjectedCompletionDate = &projected
+		progress.OnTrack = !projected.After(g.TargetDate)
+	}
+
+	return progress, nil
+}
+
+// monthsBetween menghitung jumlah bulan penuh dari `from` sampai `to`,
+// dibulatkan ke atas jika ada sisa hari
+func monthsBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if from.AddDate(0, months, 0).Before(to) {
+		months++
+	}
+	return months
+}
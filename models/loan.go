@@ -0,0 +1,224 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"ashborn.id/moniplan/money"
+)
+
+// Loan adalah hutang/cicilan (KPR, cicilan motor) dengan bunga tetap yang
+// dibayar bulanan pada PaymentDay
+type Loan struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	UserID       uint         `json:"user_id" gorm:"not null;index"`
+	CategoryID   uint         `json:"category_id" gorm:"not null"`
+	Name         string       `json:"name" gorm:"not null;size:100"`
+	Principal    money.Amount `json:"principal" gorm:"not null"`
+	InterestRate float64      `json:"interest_rate" gorm:"not null"` // Bunga per tahun dalam persen
+	TermMonths   uint         `json:"term_months" gorm:"not null"`
+	PaymentDay   uint         `json:"payment_day" gorm:"not null"`
+	StartDate    time.Time    `json:"start_date"` // Bulan pembayaran pertama
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func (Loan) TableName() string {
+	return "loans"
+}
+
+// LoanSchedule adalah satu baris jadwal amortisasi. TransactionID terisi
+// setelah pembayaran cicilan periode tersebut di-link ke sebuah transaction.
+type LoanSchedule struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	LoanID        uint         `json:"loan_id" gorm:"not null;index"`
+	Period        uint         `json:"period" gorm:"not null"`
+	DueDate       time.Time    `json:"due_date"`
+	Payment       money.Amount `json:"payment" gorm:"not null"`
+	Principal     money.Amount `json:"principal" gorm:"not null"`
+	Interest      money.Amount `json:"interest" gorm:"not null"`
+	Balance       money.Amount `json:"balance" gorm:"not null"`
+	TransactionID *uint        `json:"transaction_id"`
+	PaidAt        *time.Time   `json:"paid_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+func (LoanSchedule) TableName() string {
+	return "loan_schedules"
+}
+
+// LoanPayoffScenario adalah hasil simulasi pelunasan dengan extra payment
+// bulanan di atas cicilan normal
+type LoanPayoffScenario struct {
+	ExtraPayment  money.Amount `json:"extra_payment"`
+	Months        int          `json:"months"`
+	PayoffDate    time.Time    `json:"payoff_date"`
+	TotalInterest money.Amount `json:"total_interest"`
+	InterestSaved money.Amount `json:"interest_saved"`
+	MonthsSaved   int          `json:"months_saved"`
+}
+
+// LoanSummary adalah ringkasan posisi loan saat ini
+type LoanSummary struct {
+	Loan                 Loan                 `json:"loan"`
+	MonthlyPayment       money.Amount         `json:"monthly_payment"`
+	PaidPeriods          int                  `json:"paid_periods"`
+	OutstandingPrincipal money.Amount         `json:"outstanding_principal"`
+	InterestPaidToDate   money.Amount         `json:"interest_paid_to_date"`
+	PayoffDate           time.Time            `json:"payoff_date"`
+	Scenarios            []LoanPayoffScenario `json:"scenarios"`
+}
+
+// MonthlyRate mengembalikan bunga per bulan dalam bentuk desimal
+func (l *Loan) MonthlyRate() float64 {
+	return l.InterestRate / 12 / 100
+}
+
+// MonthlyPayment menghitung cicilan tetap per bulan (anuitas), dibulatkan
+// ke atas agar loan lunas tepat di akhir term
+func (l *Loan) MonthlyPayment() money.Amount {
+	if l.TermMonths == 0 {
+		return l.Principal
+	}
+
+	rate := l.MonthlyRate()
+	if rate == 0 {
+		return money.Amount(math.Ceil(float64(l.Principal) / float64(l.TermMonths)))
+	}
+
+	payment := float64(l.Principal) * rate / (1 - math.Pow(1+rate, -float64(l.TermMonths)))
+	return money.Amount(math.Ceil(payment))
+}
+
+// DueDate mengembalikan tanggal jatuh tempo untuk periode ke-n (mulai dari 1).
+// PaymentDay yang melebihi jumlah hari di bulan tersebut dipotong ke hari
+// terakhir bulan itu.
+func (l *Loan) DueDate(period uint) time.Time {
+	first := time.Date(l.StartDate.Year(), l.StartDate.Month(), 1, 0, 0, 0, 0, l.StartDate.Location())
+	month := first.AddDate(0, int(period)-1, 0)
+
+	day := int(l.PaymentDay)
+	lastDay := month.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	if day < 1 {
+		day = 1
+	}
+
+	return time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, month.Location())
+}
+
+// BuildSchedule membuat jadwal amortisasi lengkap untuk loan
+func (l *Loan) BuildSchedule() []LoanSchedule {
+	schedule := make([]LoanSchedule, 0, l.TermMonths)
+	rate := l.MonthlyRate()
+	payment := l.MonthlyPayment()
+	balance := l.Principal
+
+	for period := uint(1); period <= l.TermMonths && balance > 0; period++ {
+		interest := money.Amount(math.Round(float64(balance) * rate))
+		principal := money.Amount(0)
+		if payment > interest {
+			principal = payment - interest
+		}
+
+		// Periode terakhir melunasi seluruh sisa pokok
+		if principal > balance || period == l.TermMonths {
+			principal = balance
+		}
+		balance -= principal
+
+		schedule = append(schedule, LoanSchedule{
+			LoanID:    l.ID,
+			Period:    period,
+			DueDate:   l.DueDate(period),
+			Payment:   principal + interest,
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	return schedule
+}
+
+// SimulatePayoff mensimulasikan pelunasan sisa pokok `balance` mulai periode
+// `fromPeriod` dengan cicilan normal ditambah `extra` setiap bulan
+func (l *Loan) SimulatePayoff(balance money.Amount, fromPeriod uint, extra money.Amount) LoanPayoffScenario {
+	rate := l.MonthlyRate()
+	payment := l.MonthlyPayment() + extra
+	scenario := LoanPayoffScenario{ExtraPayment: extra}
+
+	period := fromPeriod
+	for balance > 0 {
+		interest := money.Amount(math.Round(float64(balance) * rate))
+		principal := money.Amount(0)
+		if payment > interest {
+			principal = payment - interest
+		}
+		if principal == 0 {
+			// Cicilan tidak cukup menutup bunga, loan tidak akan lunas
+			break
+		}
+		if principal > balance {
+			principal = balance
+		}
+
+		balance -= principal
+		scenario.TotalInterest += interest
+		scenario.Months++
+		scenario.PayoffDate = l.DueDate(period)
+		period++
+	}
+
+	return scenario
+}
+
+// GetSummary menghitung sisa pokok, bunga yang sudah dibayar dan tanggal
+// lunas berdasarkan jadwal yang sudah di-link ke transaction, beserta
+// skenario extra payment untuk setiap nilai di `extras`
+func (l *Loan) GetSummary(schedule []LoanSchedule, extras []money.Amount) LoanSummary {
+	summary := LoanSummary{
+		Loan:                 *l,
+		MonthlyPayment:       l.MonthlyPayment(),
+		OutstandingPrincipal: l.Principal,
+		Scenarios:            []LoanPayoffScenario{},
+	}
+
+	nextPeriod := uint(1)
+	for _, row := range schedule {
+		if row.TransactionID == nil {
+			continue
+		}
+
+		summary.PaidPeriods++
+		summary.InterestPaidToDate += row.Interest
+		if row.Principal > summary.OutstandingPrincipal {
+			summary.OutstandingPrincipal = 0
+		} else {
+			summary.OutstandingPrincipal -= row.Principal
+		}
+		if row.Period >= nextPeriod {
+			nextPeriod = row.Period + 1
+		}
+	}
+
+	base := l.SimulatePayoff(summary.OutstandingPrincipal, nextPeriod, 0)
+	summary.PayoffDate = base.PayoffDate
+	if summary.OutstandingPrincipal == 0 && len(schedule) > 0 {
+		summary.PayoffDate = schedule[len(schedule)-1].DueDate
+	}
+
+	for _, extra := range extras {
+		scenario := l.SimulatePayoff(summary.OutstandingPrincipal, nextPeriod, extra)
+		if base.TotalInterest > scenario.TotalInterest {
+			scenario.InterestSaved = base.TotalInterest - scenario.TotalInterest
+		}
+		scenario.MonthsSaved = base.Months - scenario.Months
+		summary.Scenarios = append(summary.Scenarios, scenario)
+	}
+
+	return summary
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+// moneyColumn adalah kolom nominal yang dulunya uint dalam major unit.
+// Owner adalah kondisi SQL untuk memilih baris berdasarkan daftar currency,
+// karena baris tanpa kolom currency mengikuti base currency user.
+type moneyColumn struct {
+	Model   interface{}
+	Columns []string
+	Owner   string
+}
+
+// userCurrency memilih baris milik user dengan base currency tertentu
+const userCurrency = "user_id IN (SELECT id FROM users WHERE base_currency IN ?)"
+
+var moneyColumns = []moneyColumn{
+	{&Transaction{}, []string{"amount"}, "currency IN ?"},
+	{&Budget{}, []string{"amount"}, userCurrency},
+	{&Goal{}, []string{"target_amount"}, userCurrency},
+	{&Loan{}, []string{"principal"}, userCurrency},
+	{&LoanSchedule{}, []string{"payment", "principal", "interest", "balance"}, "loan_id IN (SELECT id FROM loans WHERE " + userCurrency + ")"},
+	{&Bill{}, []string{"amount"}, userCurrency},
+	{&BillPayment{}, []string{"amount"}, "bill_id IN (SELECT id FROM bills WHERE " + userCurrency + ")"},
+	{&BudgetAlertLog{}, []string{"spent", "budget"}, "category_id IN (SELECT id FROM categories WHERE " + userCurrency + ")"},
+	{&TransactionRule{}, []string{"min_amount", "max_amount"}, userCurrency},
+}
+
+// moneyColumnScale menandai kolom yang nilainya sudah dikalikan ke minor unit
+// tetapi tipenya belum diubah ke signed. Ditulis dalam transaction yang sama
+// dengan UPDATE, sehingga jika proses berhenti atau ALTER gagal, run
+// berikutnya tidak mengalikan nilainya lagi.
+type moneyColumnScale struct {
+	Table  string `gorm:"column:table_name;primaryKey;size:100"`
+	Column string `gorm:"column:column_name;primaryKey;size:100"`
+}
+
+func (moneyColumnScale) TableName() string {
+	return "money_column_scales"
+}
+
+// MigrateMoneyColumns mengubah kolom nominal lama (unsigned, major unit) menjadi
+// signed minor unit. Kolom yang masih unsigned dikalikan 10^exponent currency
+// lalu diubah ke tipe signed. Kolom yang sudah dikalikan dicatat di
+// money_column_scales, sehingga migration ini aman dijalankan ulang walaupun
+// berhenti di antara UPDATE dan ALTER. Dijalankan setelah AutoMigrate agar
+// kolom currency sudah tersedia. Baris di trash ikut dikonversi karena tipe
+// kolomnya berubah untuk seluruh tabel.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	for _, spec := range moneyColumns {
+		if !db.Migrator().HasTable(spec.Model) {
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(spec.Model)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range spec.Columns {
+			if !isUnsignedColumn(columnTypes, column) {
+				continue
+			}
+			if err := migrateMoneyColumn(db, spec, column); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Semua kolom sudah signed, penanda tidak diperlukan lagi
+	if db.Migrator().HasTable(&moneyColumnScale{}) {
+		return db.Migrator().DropTable(&moneyColumnScale{})
+	}
+	return nil
+}
+
+// migrateMoneyColumn mengalikan nilai kolom ke minor unit jika belum ditandai
+// di money_column_scales, lalu mengubah tipe kolom ke signed
+func migrateMoneyColumn(db *gorm.DB, spec moneyColumn, column string) error {
+	if err := db.AutoMigrate(&moneyColumnScale{}); err != nil {
+		return err
+	}
+
+	table := tableName(db, spec.Model)
+	marker := moneyColumnScale{Table: table, Column: column}
+	var scaled int64
+	if err := db.Model(&moneyColumnScale{}).Where(&marker).Count(&scaled).Error; err != nil {
+		return err
+	}
+
+	// Scale sebelum alter, selama kolom unsigned tidak ada nilai negatif
+	if scaled == 0 {
+		groups := money.ExponentGroups()
+		known := []string{}
+		for _, currencies := range groups {
+			known = append(known, currencies...)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for exp, currencies := range groups {
+				if exp == 0 {
+					continue
+				}
+				if err := scaleMoneyColumn(tx, spec, column, exp, spec.Owner, currencies); err != nil {
+					return err
+				}
+			}
+
+			// Currency yang tidak terdaftar memakai DefaultExponent
+			if err := scaleMoneyColumn(tx, spec, column, money.DefaultExponent, "NOT ("+spec.Owner+")", known); err != nil {
+				return err
+			}
+			return tx.Create(&marker).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.Migrator().AlterColumn(spec.Model, column); err != nil {
+		return err
+	}
+	log.Printf("✅ Migrated %s.%s to signed minor units", table, column)
+	return nil
+}
+
+func scaleMoneyColumn(tx *gorm.DB, spec moneyColumn, column string, exp int, condition string, currencies []string) error {
+	factor := int64(math.Pow10(exp))
+	return tx.Table(tableName(tx, spec.Model)).
+		Where(condition, currencies).
+		Where(column+" IS NOT NULL").
+		UpdateColumn(column, gorm.Expr(column+" * ?", factor)).Error
+}
+
+func isUnsignedColumn(columnTypes []gorm.ColumnType, column string) bool {
+	for _, columnType := range columnTypes {
+		if columnType.Name() != column {
+			continue
+		}
+		fullType, ok := columnType.ColumnType()
+		return ok && strings.Contains(strings.ToLower(fullType), "unsigned")
+	}
+	return false
+}
+
+func tableName(db *gorm.DB, model interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "unknown"
+	}
+	return stmt.Schema.Table
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openLegacyMoneyDB membuat tabel transactions lama dengan amount unsigned
+// dalam major unit
+func openLegacyMoneyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{
+		Driver: database.DriverSQLite,
+		Name:   filepath.Join(t.TempDir(), "moniplan.db"),
+	}, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	statements := []string{
+		"CREATE TABLE transactions (id integer PRIMARY KEY AUTOINCREMENT, amount bigint unsigned NOT NULL, currency varchar(3) NOT NULL)",
+		"INSERT INTO transactions (amount, currency) VALUES (12, 'USD'), (150000, 'IDR')",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return db
+}
+
+func amounts(t *testing.T, db *gorm.DB) []money.Amount {
+	t.Helper()
+
+	var values []money.Amount
+	if err := db.Table("transactions").Order("id ASC").Pluck("amount", &values).Error; err != nil {
+		t.Fatalf("Pluck: %v", err)
+	}
+	return values
+}
+
+// TestMigrateMoneyColumnRerun menjalankan ulang konversi kolom yang sudah
+// dikalikan, seperti saat proses berhenti atau ALTER gagal setelah UPDATE
+// tersimpan
+func TestMigrateMoneyColumnRerun(t *testing.T) {
+	db := openLegacyMoneyDB(t)
+	spec := moneyColumns[0]
+
+	for run := 1; run <= 2; run++ {
+		if err := migrateMoneyColumn(db, spec, "amount"); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if got := amounts(t, db); got[0] != 1200 || got[1] != 150000 {
+			t.Fatalf("run %d: amounts = %v, want [1200 150000]", run, got)
+		}
+	}
+
+	if err := MigrateMoneyColumns(db); err != nil {
+		t.Fatalf("MigrateMoneyColumns: %v", err)
+	}
+	if db.Migrator().HasTable(&moneyColumnScale{}) {
+		t.Error("money_column_scales still exists after MigrateMoneyColumns")
+	}
+}
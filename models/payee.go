@@ -0,0 +1,311 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+// Jenis pencocokan alias terhadap deskripsi yang sudah dinormalisasi
+const (
+	PayeeMatchExact    = "exact"
+	PayeeMatchContains = "contains"
+	PayeeMatchPrefix   = "prefix"
+	PayeeMatchRegex    = "regex"
+)
+
+// Payee adalah merchant/penerima kanonik, misalnya "McDonald's" untuk
+// deskripsi mentah "GOFOOD*MCD 1234 JKT"
+type Payee struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_payees_user_name"`
+	Name      string    `json:"name" gorm:"not null;size:100;uniqueIndex:idx_payees_user_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Payee) TableName() string {
+	return "payees"
+}
+
+// PayeeAlias memetakan deskripsi mentah ke Payee. Pattern dicocokkan ke
+// hasil NormalizeDescription sesuai MatchType.
+type PayeeAlias struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	PayeeID   uint      `json:"payee_id" gorm:"not null;index"`
+	Pattern   string    `json:"pattern" gorm:"not null;size:255"`
+	MatchType string    `json:"match_type" gorm:"not null;size:20;default:contains"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PayeeAlias) TableName() string {
+	return "payee_aliases"
+}
+
+// PublicPayee adalah payee beserta alias-aliasnya
+type PublicPayee struct {
+	ID        uint         `json:"id"`
+	Name      string       `json:"name"`
+	Aliases   []PayeeAlias `json:"aliases"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func (p *Payee) ToPublicPayee(aliases []PayeeAlias) PublicPayee {
+	if aliases == nil {
+		aliases = []PayeeAlias{}
+	}
+	return PublicPayee{
+		ID:        p.ID,
+		Name:      p.Name,
+		Aliases:   aliases,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// PayeeSpending adalah total spending per payee dalam satu periode
+type PayeeSpending struct {
+	PayeeID          uint         `json:"payee_id"`
+	PayeeName        string       `json:"payee_name"`
+	TransactionCount uint         `json:"transaction_count"`
+	Total            money.Amount `json:"total"`
+}
+
+var descriptionSeparator = regexp.MustCompile(`[^\p{L}\p{N}&']+`)
+
+// NormalizeDescription menyeragamkan deskripsi transaction: lowercase,
+// tanda baca jadi spasi, dan token yang mengandung angka (nomor referensi,
+// nomor cabang) dibuang. "GOFOOD*MCD 1234 JKT" menjadi "gofood mcd jkt".
+func NormalizeDescription(raw string) string {
+	fields := strings.Fields(descriptionSeparator.ReplaceAllString(strings.ToLower(raw), " "))
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if strings.IndexFunc(field, unicode.IsDigit) >= 0 {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Matches mengecek apakah deskripsi yang sudah dinormalisasi cocok dengan alias
+func (a *PayeeAlias) Matches(normalized string) bool {
+	pattern := NormalizeDescription(a.Pattern)
+	switch a.MatchType {
+	case PayeeMatchExact:
+		return normalized == pattern
+	case PayeeMatchPrefix:
+		return normalized == pattern || strings.HasPrefix(normalized, pattern+" ")
+	case PayeeMatchRegex:
+		re, err := regexp.Compile("(?i)" + a.Pattern)
+		return err == nil && re.MatchString(normalized)
+	default:
+		return pattern != "" && strings.Contains(" "+normalized+" ", " "+pattern+" ")
+	}
+}
+
+// PayeeResolver mencocokkan deskripsi ke payee milik seorang user
+type PayeeResolver struct {
+	aliases []PayeeAlias
+	payees  []Payee
+}
+
+// NewPayeeResolver memuat alias dan payee user dari database
+func NewPayeeResolver(userID uint) (*PayeeResolver, error) {
+	var aliases []PayeeAlias
+	if err := database.DB.Where("user_id = ?", userID).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	var payees []Payee
+	if err := database.DB.Where("user_id = ?", userID).Find(&payees).Error; err != nil {
+		return nil, err
+	}
+	return NewPayeeResolverFrom(payees, aliases), nil
+}
+
+// NewPayeeResolverFrom membuat resolver dari payee dan alias yang sudah
+// dimuat. Alias dengan pattern lebih panjang dicek lebih dulu karena lebih
+// spesifik.
+func NewPayeeResolverFrom(payees []Payee, aliases []PayeeAlias) *PayeeResolver {
+	resolver := &PayeeResolver{
+		aliases: append([]PayeeAlias(nil), aliases...),
+		payees:  append([]Payee(nil), payees...),
+	}
+	sort.SliceStable(resolver.aliases, func(i, j int) bool {
+		return len(resolver.aliases[i].Pattern) > len(resolver.aliases[j].Pattern)
+	})
+	sort.SliceStable(resolver.payees, func(i, j int) bool {
+		return len(resolver.payees[i].Name) > len(resolver.payees[j].Name)
+	})
+	return resolver
+}
+
+// Resolve mengembalikan payee ID untuk deskripsi mentah, atau nil jika tidak
+// ada alias maupun nama payee yang cocok
+func (r *PayeeResolver) Resolve(raw string) *uint {
+	normalized := NormalizeDescription(raw)
+	if normalized == "" {
+		return nil
+	}
+
+	for i := range r.aliases {
+		if r.aliases[i].Matches(normalized) {
+			id := r.aliases[i].PayeeID
+			return &id
+		}
+	}
+
+	for i := range r.payees {
+		name := NormalizeDescription(r.payees[i].Name)
+		if name != "" && strings.Contains(" "+normalized+" ", " "+name+" ") {
+			id := r.payees[i].ID
+			return &id
+		}
+	}
+	return nil
+}
+
+// MergePayees memindahkan transaction dan alias dari sourceIDs ke target,
+// menyimpan nama payee sumber sebagai alias, lalu menghapus payee sumber
+func MergePayees(ctx context.Context, target Payee, sourceIDs []uint) error {
+	return database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		var sources []Payee
+		if err := tx.Where("user_id = ? AND id IN ? AND id <> ?", target.UserID, sourceIDs, target.ID).Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		ids := make([]uint, 0, len(sources))
+		for _, source := range sources {
+			ids = append(ids, source.ID)
+			alias := PayeeAlias{
+				UserID:    target.UserID,
+				PayeeID:   target.ID,
+				Pattern:   source.Name,
+				MatchType: PayeeMatchContains,
+			}
+			if err := tx.Create(&alias).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&Transaction{}).Where("user_id = ? AND payee_id IN ?", target.UserID, ids).Update("payee_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&PayeeAlias{}).Where("user_id = ? AND payee_id IN ?", target.UserID, ids).Update("payee_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id IN ?", target.UserID, ids).Delete(&Payee{}).Error
+	})
+}
+
+// SplitPayee memindahkan sebagian transaction dan alias dari source ke
+// payee baru (atau payee lain yang sudah ada)
+func SplitPayee(ctx context.Context, source Payee, target *Payee, transactionIDs, aliasIDs []uint) (int64, error) {
+	var moved int64
+	createTarget := target.ID == 0
+	err := database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		if createTarget {
+			target.ID = 0
+			if err := tx.Create(target).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(transactionIDs) > 0 {
+			result := tx.Model(&Transaction{}).
+				Where("user_id = ? AND payee_id = ? AND id IN ?", source.UserID, source.ID, transactionIDs).
+				Update("payee_id", target.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			moved = result.RowsAffected
+		}
+
+		if len(aliasIDs) > 0 {
+			if err := tx.Model(&PayeeAlias{}).Where("user_id = ? AND payee_id = ? AND id IN ?", source.UserID, source.ID, aliasIDs).Update("payee_id", target.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
+
+// GetPayeeSpending menghitung spending per payee dalam rentang [from, to)
+// dalam base currency user
+func GetPayeeSpending(userID uint, from, to time.Time) ([]PayeeSpending, error) {
+	book := NewRateBook(userID)
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.
+			Table("transactions t").
+			Joins("JOIN payees p ON p.id = t.payee_id").
+			Where("t.user_id = ? AND t.transaction_date >= ? AND t.transaction_date < ? AND t.deleted_at IS NULL", userID, from, to).
+			Where("t.type IN ?", SpendingMeasure.Types())
+	}
+
+	report := []PayeeSpending{}
+	err := database.DB.
+		Scopes(scope).
+		Select("p.id AS payee_id, p.name AS payee_name, COUNT(t.id) AS transaction_count, COALESCE(SUM("+SpendingMeasure.SQL("t.")+"), 0) AS total").
+		Where("t.currency = ?", book.Base).
+		Group("p.id, p.name").
+		Scan(&report).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var foreign []struct {
+		PayeeID         uint
+		PayeeName       string
+		Amount          money.Amount
+		Currency        string
+		Type            string
+		TransactionDate time.Time
+	}
+	err = database.DB.
+		Scopes(scope).
+		Select("p.id AS payee_id, p.name AS payee_name, t.amount, t.currency, t.type, t.transaction_date").
+		Where("t.currency <> ?", book.Base).
+		Scan(&foreign).Error
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[uint]int, len(report))
+	for i, row := range report {
+		index[row.PayeeID] = i
+	}
+	for _, row := range foreign {
+		converted, err := book.Convert(SpendingMeasure.Apply(row.Type, row.Amount), row.Currency, row.TransactionDate)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[row.PayeeID]
+		if !ok {
+			i = len(report)
+			index[row.PayeeID] = i
+			report = append(report, PayeeSpending{PayeeID: row.PayeeID, PayeeName: row.PayeeName})
+		}
+		report[i].TransactionCount++
+		report[i].Total += converted
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].Total > report[j].Total
+	})
+	return report, nil
+}
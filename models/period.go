@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+const (
+	// DefaultPeriodStartDay membuat periode budget sama dengan bulan kalender
+	DefaultPeriodStartDay = 1
+
+	// MaxPeriodStartDay dibatasi 28 agar tanggal awal periode ada di setiap bulan
+	MaxPeriodStartDay = 28
+)
+
+// BudgetPeriod adalah rentang satu periode budget user. Periode diberi nama
+// sesuai bulan awalnya: dengan start day 25, periode 3/2026 berjalan dari
+// 25 Maret sampai 24 April. End adalah awal periode berikutnya (eksklusif).
+type BudgetPeriod struct {
+	Year  uint      `json:"year"`
+	Month uint      `json:"month"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains mengecek apakah t berada di dalam periode
+func (p BudgetPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// NormalizePeriodStartDay mengembalikan DefaultPeriodStartDay untuk nilai di
+// luar 1-MaxPeriodStartDay (misalnya user lama yang belum punya kolomnya)
+func NormalizePeriodStartDay(day int) int {
+	if day < 1 || day > MaxPeriodStartDay {
+		return DefaultPeriodStartDay
+	}
+	return day
+}
+
+// PeriodRange mengembalikan periode year/month untuk start day di zona waktu loc
+func PeriodRange(year, month uint, startDay int, loc *time.Location) BudgetPeriod {
+	start := time.Date(int(year), time.Month(month), NormalizePeriodStartDay(startDay), 0, 0, 0, 0, loc)
+	return BudgetPeriod{
+		Year:  uint(start.Year()),
+		Month: uint(start.Month()),
+		Start: start,
+		End:   start.AddDate(0, 1, 0),
+	}
+}
+
+// PeriodOf mengembalikan periode yang memuat t. Tanggal sebelum start day
+// masih termasuk periode bulan sebelumnya.
+func PeriodOf(t time.Time, startDay int, loc *time.Location) BudgetPeriod {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	if day < NormalizePeriodStartDay(startDay) {
+		month--
+	}
+	return PeriodRange(uint(year), uint(month), startDay, loc)
+}
@@ -0,0 +1,357 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxReportPeriods adalah jumlah periode terbanyak dalam satu report
+	MaxReportPeriods = 60
+
+	// MaxHeatmapDays adalah rentang hari terpanjang untuk heatmap spending
+	MaxHeatmapDays = 366
+)
+
+// CashFlowPoint adalah pemasukan, spending dan arus kas bersih satu periode.
+// Net juga menghitung refund dan adjustment (lihat NetFlowMeasure).
+type CashFlowPoint struct {
+	Period  BudgetPeriod `json:"period"`
+	Income  money.Amount `json:"income"`
+	Expense money.Amount `json:"expense"`
+	Net     money.Amount `json:"net"`
+}
+
+// TrendDelta adalah perubahan nominal dibanding periode pembanding. Percent
+// kosong jika nominal pembandingnya 0.
+type TrendDelta struct {
+	Previous money.Amount `json:"previous"`
+	Change   money.Amount `json:"change"`
+	Percent  *float64     `json:"percent"`
+}
+
+// CategoryTrendPoint adalah spending category pada satu periode beserta
+// perubahannya dari periode sebelumnya dan periode yang sama tahun lalu
+type CategoryTrendPoint struct {
+	Period         BudgetPeriod `json:"period"`
+	Total          money.Amount `json:"total"`
+	MonthOverMonth TrendDelta   `json:"month_over_month"`
+	YearOverYear   TrendDelta   `json:"year_over_year"`
+}
+
+// CategoryTrend adalah deret spending per periode untuk satu category
+type CategoryTrend struct {
+	CategoryID   uint                 `json:"category_id"`
+	CategoryName string               `json:"category_name"`
+	Total        money.Amount         `json:"total"`
+	Points       []CategoryTrendPoint `json:"points"`
+}
+
+// HeatmapDay adalah spending satu hari di zona waktu user
+type HeatmapDay struct {
+	Date             string       `json:"date"`
+	Weekday          int          `json:"weekday"`
+	TransactionCount uint         `json:"transaction_count"`
+	Total            money.Amount `json:"total"`
+}
+
+// CategorySpending adalah spending per category dalam satu rentang
+type CategorySpending struct {
+	CategoryID       uint         `json:"category_id"`
+	CategoryName     string       `json:"category_name"`
+	TransactionCount uint         `json:"transaction_count"`
+	Total            money.Amount `json:"total"`
+}
+
+// reportGroup adalah kolom tambahan untuk pengelompokan report, misalnya
+// category. Key kosong berarti hanya dikelompokkan per bucket waktu.
+type reportGroup struct {
+	Join     string
+	JoinArgs []interface{}
+	Key      string
+	Name     string
+}
+
+var categoryGroup = reportGroup{
+	Join: "JOIN categories c ON c.id = t.category_id",
+	Key:  "c.id",
+	Name: "c.name",
+}
+
+// reportRow adalah hasil agregasi per bucket waktu, group dan jenis
+// transaction. Total belum diberi tanda sesuai measure.
+type reportRow struct {
+	Bucket           int
+	GroupID          uint
+	GroupName        string
+	Type             string
+	TransactionCount uint
+	Total            money.Amount
+}
+
+// bucketCase membuat ekspresi CASE yang memberi nomor bucket ke column
+// berdasarkan batas bounds (bucket i adalah [bounds[i], bounds[i+1])). Baris
+// di luar bounds sudah difilter di WHERE, jadi ELSE adalah bucket terakhir.
+func bucketCase(column string, bounds []time.Time) (string, []interface{}) {
+	last := len(bounds) - 2
+	if last == 0 {
+		return "0", nil
+	}
+
+	var b strings.Builder
+	args := make([]interface{}, 0, last)
+	b.WriteString("CASE")
+	for i := 0; i < last; i++ {
+		b.WriteString(" WHEN " + column + " < ? THEN " + strconv.Itoa(i))
+		args = append(args, bounds[i+1].UTC())
+	}
+	b.WriteString(" ELSE " + strconv.Itoa(last) + " END")
+	return b.String(), args
+}
+
+// bucketOf mencari bucket untuk t, -1 jika di luar bounds
+func bucketOf(t time.Time, bounds []time.Time) int {
+	i := sort.Search(len(bounds), func(i int) bool { return bounds[i].After(t) })
+	if i == 0 || i == len(bounds) {
+		return -1
+	}
+	return i - 1
+}
+
+// aggregateTransactions menjumlahkan transaction user dengan jenis di types
+// per bucket waktu, group dan jenis transaction dalam base currency.
+// Transaction dalam base currency dijumlahkan di database, sisanya
+// dikonversi per tanggal transaction lalu digabung ke baris yang sama.
+func aggregateTransactions(book *RateBook, bounds []time.Time, types []string, group reportGroup) ([]reportRow, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Table("transactions t")
+		if group.Join != "" {
+			db = db.Joins(group.Join, group.JoinArgs...)
+		}
+		return db.
+			Where("t.user_id = ? AND t.transaction_date >= ? AND t.transaction_date < ? AND t.deleted_at IS NULL", book.UserID, bounds[0].UTC(), bounds[len(bounds)-1].UTC()).
+			Where("t.type IN ?", types)
+	}
+
+	bucket, args := bucketCase("t.transaction_date", bounds)
+	columns := []string{bucket + " AS bucket", "t.type AS type"}
+	groupBy := "bucket, t.type"
+	if group.Key != "" {
+		columns = append(columns, group.Key+" AS group_id", group.Name+" AS group_name")
+		groupBy += ", " + group.Key + ", " + group.Name
+	}
+
+	var rows []reportRow
+	err := database.DB.
+		Scopes(scope).
+		Select(strings.Join(append(columns, "COUNT(t.id) AS transaction_count", "COALESCE(SUM(t.amount), 0) AS total"), ", "), args...).
+		Where("t.currency = ?", book.Base).
+		Group(groupBy).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	foreignColumns := "t.amount, t.currency, t.type, t.transaction_date"
+	if group.Key != "" {
+		foreignColumns = group.Key + " AS group_id, " + group.Name + " AS group_name, " + foreignColumns
+	}
+	var foreign []struct {
+		GroupID         uint
+		GroupName       string
+		Amount          money.Amount
+		Currency        string
+		Type            string
+		TransactionDate time.Time
+	}
+	err = database.DB.
+		Scopes(scope).
+		Select(foreignColumns).
+		Where("t.currency <> ?", book.Base).
+		Scan(&foreign).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type rowKey struct {
+		bucket int
+		group  uint
+		kind   string
+	}
+	index := make(map[rowKey]int, len(rows))
+	for i, row := range rows {
+		index[rowKey{row.Bucket, row.GroupID, row.Type}] = i
+	}
+	for _, row := range foreign {
+		converted, err := book.Convert(row.Amount, row.Currency, row.TransactionDate)
+		if err != nil {
+			return nil, err
+		}
+		key := rowKey{bucketOf(row.TransactionDate, bounds), row.GroupID, row.Type}
+		if key.bucket < 0 {
+			continue
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, reportRow{Bucket: key.bucket, GroupID: row.GroupID, GroupName: row.GroupName, Type: row.Type})
+		}
+		rows[i].TransactionCount++
+		rows[i].Total += converted
+	}
+	return rows, nil
+}
+
+// periodBounds mengembalikan batas bucket untuk periode yang berurutan
+func periodBounds(periods []BudgetPeriod) []time.Time {
+	bounds := make([]time.Time, 0, len(periods)+1)
+	for _, period := range periods {
+		bounds = append(bounds, period.Start)
+	}
+	return append(bounds, periods[len(periods)-1].End)
+}
+
+// GetCashFlow menghitung pemasukan, spending dan arus kas bersih user per
+// periode dalam base currency. periods harus berurutan dan tidak kosong.
+func GetCashFlow(userID uint, periods []BudgetPeriod) ([]CashFlowPoint, error) {
+	rows, err := aggregateTransactions(NewRateBook(userID), periodBounds(periods), NetFlowMeasure.Types(), reportGroup{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]CashFlowPoint, len(periods))
+	for i, period := range periods {
+		report[i].Period = period
+	}
+	for _, row := range rows {
+		point := &report[row.Bucket]
+		point.Income += IncomeMeasure.Apply(row.Type, row.Total)
+		point.Expense += SpendingMeasure.Apply(row.Type, row.Total)
+		point.Net += NetFlowMeasure.Apply(row.Type, row.Total)
+	}
+	return report, nil
+}
+
+// GetCategoryTrends menghitung spending per category untuk periods beserta
+// perubahan month-over-month dan year-over-year. 12 periode sebelum periods
+// ikut dihitung sebagai pembanding. Jika categoryID diisi, hanya category
+// tersebut yang dihitung.
+func GetCategoryTrends(userID uint, calendar UserCalendar, periods []BudgetPeriod, categoryID uint) ([]CategoryTrend, error) {
+	all := calendar.Periods(periods[0].Year-1, periods[0].Month, len(periods)+12)
+
+	group := categoryGroup
+	if categoryID > 0 {
+		group.Join += " AND c.id = ?"
+		group.JoinArgs = []interface{}{categoryID}
+	}
+	rows, err := aggregateTransactions(NewRateBook(userID), periodBounds(all), SpendingMeasure.Types(), group)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[uint]int{}
+	var totals [][]money.Amount
+	report := []CategoryTrend{}
+	for _, row := range rows {
+		i, ok := index[row.GroupID]
+		if !ok {
+			i = len(report)
+			index[row.GroupID] = i
+			report = append(report, CategoryTrend{CategoryID: row.GroupID, CategoryName: row.GroupName})
+			totals = append(totals, make([]money.Amount, len(all)))
+		}
+		totals[i][row.Bucket] += SpendingMeasure.Apply(row.Type, row.Total)
+	}
+
+	for i := range report {
+		trend := &report[i]
+		trend.Points = make([]CategoryTrendPoint, 0, len(periods))
+		for bucket := 12; bucket < len(all); bucket++ {
+			total := totals[i][bucket]
+			trend.Total += total
+			trend.Points = append(trend.Points, CategoryTrendPoint{
+				Period:         all[bucket],
+				Total:          total,
+				MonthOverMonth: trendDelta(total, totals[i][bucket-1]),
+				YearOverYear:   trendDelta(total, totals[i][bucket-12]),
+			})
+		}
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Total != report[j].Total {
+			return report[i].Total > report[j].Total
+		}
+		return report[i].CategoryName < report[j].CategoryName
+	})
+	return report, nil
+}
+
+func trendDelta(current, previous money.Amount) TrendDelta {
+	delta := TrendDelta{Previous: previous, Change: current - previous}
+	if previous != 0 {
+		percent := float64(current-previous) / float64(previous) * 100
+		delta.Percent = &percent
+	}
+	return delta
+}
+
+// GetSpendingHeatmap menghitung spending user per hari mulai dari tengah
+// malam from (di zona waktu user) selama days hari
+func GetSpendingHeatmap(userID uint, from time.Time, days int) ([]HeatmapDay, error) {
+	bounds := make([]time.Time, 0, days+1)
+	for i := 0; i <= days; i++ {
+		bounds = append(bounds, from.AddDate(0, 0, i))
+	}
+
+	rows, err := aggregateTransactions(NewRateBook(userID), bounds, SpendingMeasure.Types(), reportGroup{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]HeatmapDay, days)
+	for i := range report {
+		report[i].Date = bounds[i].Format("2006-01-02")
+		report[i].Weekday = int(bounds[i].Weekday())
+	}
+	for _, row := range rows {
+		day := &report[row.Bucket]
+		day.TransactionCount += row.TransactionCount
+		day.Total += SpendingMeasure.Apply(row.Type, row.Total)
+	}
+	return report, nil
+}
+
+// GetCategorySpending menghitung spending per category dalam rentang
+// [from, to) dalam base currency, diurutkan dari yang terbesar
+func GetCategorySpending(userID uint, from, to time.Time) ([]CategorySpending, error) {
+	rows, err := aggregateTransactions(NewRateBook(userID), []time.Time{from, to}, SpendingMeasure.Types(), categoryGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[uint]int{}
+	report := []CategorySpending{}
+	for _, row := range rows {
+		i, ok := index[row.GroupID]
+		if !ok {
+			i = len(report)
+			index[row.GroupID] = i
+			report = append(report, CategorySpending{CategoryID: row.GroupID, CategoryName: row.GroupName})
+		}
+		report[i].TransactionCount += row.TransactionCount
+		report[i].Total += SpendingMeasure.Apply(row.Type, row.Total)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].Total > report[j].Total
+	})
+	return report, nil
+}
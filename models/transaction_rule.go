@@ -0,0 +1,173 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/money"
+)
+
+// TransactionRule mengkategorikan transaction secara otomatis. Semua kondisi
+// yang diisi harus terpenuhi (AND), kondisi kosong diabaikan. Rule dievaluasi
+// berurutan berdasarkan Priority (kecil lebih dulu): category dan remarks
+// diambil dari rule pertama yang mengisinya, tags dari semua rule yang cocok.
+type TransactionRule struct {
+	ID               uint          `json:"id" gorm:"primaryKey"`
+	UserID           uint          `json:"user_id" gorm:"not null;index"`
+	Name             string        `json:"name" gorm:"not null;size:100"`
+	Priority         int           `json:"priority" gorm:"not null;default:0"`
+	Active           bool          `json:"active" gorm:"not null;default:true"`
+	RemarksContains  string        `json:"remarks_contains" gorm:"size:255"`
+	RemarksRegex     string        `json:"remarks_regex" gorm:"size:255"`
+	MinAmount        *money.Amount `json:"min_amount"`
+	MaxAmount        *money.Amount `json:"max_amount"`
+	Type             string        `json:"type" gorm:"size:20"`
+	SetCategoryID    *uint         `json:"set_category_id"`
+	AddTags          string        `json:"add_tags" gorm:"size:255"`
+	RenameRemarks    string        `json:"rename_remarks" gorm:"size:255"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+	compiledRegex    *regexp.Regexp
+	compiledRegexErr error
+}
+
+func (TransactionRule) TableName() string {
+	return "transaction_rules"
+}
+
+// RuleResult adalah hasil penerapan rule ke sebuah transaction
+type RuleResult struct {
+	TransactionID uint   `json:"transaction_id"`
+	Changed       bool   `json:"changed"`
+	RuleIDs       []uint `json:"rule_ids"`
+	CategoryID    uint   `json:"category_id"`
+	Remarks       string `json:"remarks"`
+	Tags          string `json:"tags"`
+}
+
+// RuleDiff adalah perbandingan transaction sebelum dan sesudah rule diterapkan
+type RuleDiff struct {
+	Before PublicTransaction `json:"before"`
+	After  RuleResult        `json:"after"`
+}
+
+// Compile memvalidasi dan menyiapkan RemarksRegex (case-insensitive)
+func (r *TransactionRule) Compile() error {
+	r.compiledRegex, r.compiledRegexErr = nil, nil
+	if r.RemarksRegex != "" {
+		r.compiledRegex, r.compiledRegexErr = regexp.Compile("(?i)" + r.RemarksRegex)
+	}
+	return r.compiledRegexErr
+}
+
+// Matches mengecek apakah transaction memenuhi semua kondisi rule
+func (r *TransactionRule) Matches(t *Transaction) bool {
+	if r.RemarksContains != "" && !strings.Contains(strings.ToLower(t.Remarks), strings.ToLower(r.RemarksContains)) {
+		return false
+	}
+	if r.RemarksRegex != "" {
+		if r.compiledRegex == nil && r.compiledRegexErr == nil {
+			r.Compile()
+		}
+		if r.compiledRegex == nil || !r.compiledRegex.MatchString(t.Remarks) {
+			return false
+		}
+	}
+	if r.MinAmount != nil && t.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && t.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.Type != "" && !strings.EqualFold(r.Type, t.Type) {
+		return false
+	}
+	return true
+}
+
+// GetActiveRules mengambil rule aktif milik user sesuai urutan evaluasi
+func GetActiveRules(userID uint) ([]TransactionRule, error) {
+	var rules []TransactionRule
+	err := database.DB.
+		Where("user_id = ? AND active = ?", userID, true).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		rules[i].Compile()
+	}
+	return rules, nil
+}
+
+// ApplyRules menerapkan rules ke transaction tanpa menyimpan ke database.
+// Jika keepCategory true, CategoryID yang sudah diisi tidak ditimpa (dipakai
+// saat user memilih category secara manual).
+func ApplyRules(rules []TransactionRule, t *Transaction, keepCategory bool) RuleResult {
+	result := RuleResult{
+		TransactionID: t.ID,
+		RuleIDs:       []uint{},
+		CategoryID:    t.CategoryID,
+		Remarks:       t.Remarks,
+		Tags:          t.Tags,
+	}
+
+	categorySet := keepCategory && t.CategoryID != 0
+	remarksSet := false
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(t) {
+			continue
+		}
+		result.RuleIDs = append(result.RuleIDs, rule.ID)
+
+		if !categorySet && rule.SetCategoryID != nil {
+			result.CategoryID = *rule.SetCategoryID
+			categorySet = true
+		}
+		if !remarksSet && rule.RenameRemarks != "" {
+			result.Remarks = rule.RenameRemarks
+			remarksSet = true
+		}
+		if rule.AddTags != "" {
+			result.Tags = MergeTags(result.Tags, rule.AddTags)
+		}
+	}
+
+	result.Changed = result.CategoryID != t.CategoryID || result.Remarks != t.Remarks || result.Tags != t.Tags
+	return result
+}
+
+// Apply menyalin hasil rule ke transaction
+func (r RuleResult) Apply(t *Transaction) {
+	t.CategoryID = r.CategoryID
+	t.Remarks = r.Remarks
+	t.Tags = r.Tags
+}
+
+// MergeTags menggabungkan dua daftar tag (dipisah koma), lowercase, tanpa
+// duplikat dan terurut
+func MergeTags(tags ...string) string {
+	seen := map[string]struct{}{}
+	for _, list := range tags {
+		for _, tag := range strings.Split(list, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" {
+				seen[tag] = struct{}{}
+			}
+		}
+	}
+
+	merged := make([]string, 0, len(seen))
+	for tag := range seen {
+		merged = append(merged, tag)
+	}
+	sort.Strings(merged)
+	return strings.Join(merged, ",")
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+)
+
+// Jenis transaction. Amount disimpan positif untuk semua jenis kecuali
+// adjustment, sign-nya ditentukan oleh jenis transaction:
+//   - income: uang masuk
+//   - expense: uang keluar, dihitung sebagai spending
+//   - transfer: perpindahan dana milik sendiri (misalnya ke goal), netral
+//   - refund: pengembalian expense, mengurangi spending category-nya
+//   - adjustment: koreksi saldo, amount boleh negatif
+const (
+	TransactionTypeIncome     = "income"
+	TransactionTypeExpense    = "expense"
+	TransactionTypeTransfer   = "transfer"
+	TransactionTypeRefund     = "refund"
+	TransactionTypeAdjustment = "adjustment"
+)
+
+// TransactionTypes adalah daftar jenis transaction yang valid
+var TransactionTypes = []string{
+	TransactionTypeIncome,
+	TransactionTypeExpense,
+	TransactionTypeTransfer,
+	TransactionTypeRefund,
+	TransactionTypeAdjustment,
+}
+
+// IsValidTransactionType mengecek apakah t termasuk TransactionTypes
+func IsValidTransactionType(t string) bool {
+	for _, valid := range TransactionTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransactionAmount mengecek amount sesuai jenis transaction
+func ValidateTransactionAmount(t string, amount money.Amount) error {
+	if t == TransactionTypeAdjustment {
+		if amount == 0 {
+			return fmt.Errorf("amount must not be zero")
+		}
+		return nil
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be greater than zero for %s transactions", t)
+	}
+	return nil
+}
+
+// AmountMeasure menentukan kontribusi tiap jenis transaction dalam sebuah
+// agregasi. Jenis yang tidak terdaftar tidak dihitung.
+type AmountMeasure map[string]int64
+
+var (
+	// SpendingMeasure: expense menambah spending, refund menguranginya
+	SpendingMeasure = AmountMeasure{
+		TransactionTypeExpense: 1,
+		TransactionTypeRefund:  -1,
+	}
+
+	// IncomeMeasure: pemasukan saja
+	IncomeMeasure = AmountMeasure{
+		TransactionTypeIncome: 1,
+	}
+
+	// NetFlowMeasure: arus kas bersih, transfer tidak mengubah saldo
+	NetFlowMeasure = AmountMeasure{
+		TransactionTypeIncome:     1,
+		TransactionTypeRefund:     1,
+		TransactionTypeAdjustment: 1,
+		TransactionTypeExpense:    -1,
+	}
+
+	// SavingsMeasure: dana di category goal, transfer masuk menambah dan
+	// expense dari category tersebut mengurangi
+	SavingsMeasure = AmountMeasure{
+		TransactionTypeTransfer:   1,
+		TransactionTypeAdjustment: 1,
+		TransactionTypeExpense:    -1,
+	}
+)
+
+// Types mengembalikan jenis transaction yang dihitung, terurut
+func (m AmountMeasure) Types() []string {
+	types := make([]string, 0, len(m))
+	for t := range m {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SQL mengembalikan ekspresi CASE untuk amount bertanda. prefix adalah alias
+// tabel transactions (misalnya "t."), boleh kosong.
+func (m AmountMeasure) SQL(prefix string) string {
+	var b strings.Builder
+	b.WriteString("CASE " + prefix + "type")
+	for _, t := range m.Types() {
+		switch m[t] {
+		case 1:
+			fmt.Fprintf(&b, " WHEN '%s' THEN %samount", t, prefix)
+		case -1:
+			fmt.Fprintf(&b, " WHEN '%s' THEN -%samount", t, prefix)
+		default:
+			fmt.Fprintf(&b, " WHEN '%s' THEN %samount * %d", t, prefix, m[t])
+		}
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String()
+}
+
+// Apply mengembalikan amount bertanda untuk jenis transaction t
+func (m AmountMeasure) Apply(t string, amount money.Amount) money.Amount {
+	return amount * money.Amount(m[t])
+}
+
+// legacyTransactionTypes memetakan nilai type lama (free-form) ke jenis baru.
+// Nilai yang tidak dikenal dianggap expense.
+var legacyTransactionTypes = map[string][]string{
+	TransactionTypeIncome:     {"income", "pemasukan", "masuk", "in", "credit", "kredit", "salary", "gaji", "bonus"},
+	TransactionTypeTransfer:   {"transfer", "tabungan", "saving", "savings", "investasi", "investment"},
+	TransactionTypeRefund:     {"refund", "pengembalian", "cashback", "reimburse", "reimbursement"},
+	TransactionTypeAdjustment: {"adjustment", "koreksi", "correction", "penyesuaian"},
+	TransactionTypeExpense:    {"expense", "pengeluaran", "keluar", "out", "debit", "spending", "belanja"},
+}
+
+// MigrateTransactionTypes menormalkan nilai type lama pada transactions dan
+// transaction_rules ke TransactionTypes. Aman dijalankan ulang. Dijalankan
+// sebelum AutoMigrate karena kolom type diperkecil. Query memakai nama tabel,
+// bukan Model, agar tidak ada filter deleted_at (kolomnya mungkin belum ada)
+// dan baris di trash ikut dinormalkan.
+func MigrateTransactionTypes(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&Transaction{}, &TransactionRule{}} {
+			if !tx.Migrator().HasTable(model) {
+				continue
+			}
+			table := tableName(tx, model)
+
+			for target, legacy := range legacyTransactionTypes {
+				result := tx.Table(table).
+					Where("LOWER(TRIM(type)) IN ? AND type <> ?", legacy, target).
+					UpdateColumn("type", target)
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected > 0 {
+					log.Printf("✅ Migrated %d %s rows to type %s", result.RowsAffected, table, target)
+				}
+			}
+
+			// Transaction tanpa jenis yang dikenal dianggap expense, rule
+			// dengan type kosong tetap berlaku untuk semua jenis
+			query := tx.Table(table).Where("type NOT IN ?", TransactionTypes)
+			if _, ok := model.(*TransactionRule); ok {
+				query = query.Where("type <> ''")
+			}
+			result := query.UpdateColumn("type", TransactionTypeExpense)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("✅ Migrated %d %s rows with unknown type to %s", result.RowsAffected, table, TransactionTypeExpense)
+			}
+		}
+		return nil
+	})
+}
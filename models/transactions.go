@@ -2,31 +2,46 @@ package models
 
 import (
 	"time"
+
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
 )
 
 type Transaction struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	UserID          uint      `json:"user_id" gorm:"not null"`
-	CategoryID      uint      `json:"category_id" gorm:"not null"`
-	Amount          uint      `json:"amount" gorm:"not null"`
-	Type            string    `json:"type" gorm:"not null"`
-	Remarks         string    `json:"remarks" gorm:"not null"`
-	TransactionDate time.Time `json:"transaction_date"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"not null;index:idx_transactions_user_date,priority:1"`
+	CategoryID      uint           `json:"category_id" gorm:"not null;index"`
+	PayeeID         *uint          `json:"payee_id" gorm:"index"`
+	Amount          money.Amount   `json:"amount" gorm:"not null"`
+	Currency        string         `json:"currency" gorm:"not null;size:3;default:'IDR'"`
+	Type            string         `json:"type" gorm:"not null;size:20;index"`
+	Remarks         string         `json:"remarks" gorm:"not null"`
+	Tags            string         `json:"tags" gorm:"not null;size:255;default:''"`
+	TransactionDate time.Time      `json:"transaction_date" gorm:"index:idx_transactions_user_date,priority:2"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	User     *User     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Category *Category `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Payee    *Payee    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 }
 
 type TransactionCategoryBudget struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	UserID          uint      `json:"user_id" gorm:"not null"`
-	CategoryID      uint      `json:"category_id" gorm:"not null"`
-	CategoryName    string    `json:"category_name" gorm:"column:category_name"`
-	Amount          uint      `json:"amount" gorm:"not null"`
-	Type            string    `json:"type" gorm:"not null"`
-	Remarks         string    `json:"remarks" gorm:"not null"`
-	TransactionDate string    `json:"transaction_date"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	UserID          uint         `json:"user_id" gorm:"not null"`
+	CategoryID      uint         `json:"category_id" gorm:"not null"`
+	CategoryName    string       `json:"category_name" gorm:"column:category_name"`
+	PayeeID         *uint        `json:"payee_id"`
+	PayeeName       *string      `json:"payee_name" gorm:"column:payee_name"`
+	Amount          money.Amount `json:"amount" gorm:"not null"`
+	Currency        string       `json:"currency"`
+	Type            string       `json:"type" gorm:"not null"`
+	Remarks         string       `json:"remarks" gorm:"not null"`
+	Tags            string       `json:"tags"`
+	TransactionDate string       `json:"transaction_date"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 func (Transaction) TableName() string {
@@ -34,15 +49,18 @@ func (Transaction) TableName() string {
 }
 
 type PublicTransaction struct {
-	ID              uint      `json:"id"`
-	UserID          uint      `json:"user_id"`
-	CategoryID      uint      `json:"category_id"`
-	Amount          uint      `json:"amount"`
-	Type            string    `json:"type"`
-	Remarks         string    `json:"remarks"`
-	TransactionDate time.Time `json:"transaction_date"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uint         `json:"id"`
+	UserID          uint         `json:"user_id"`
+	CategoryID      uint         `json:"category_id"`
+	PayeeID         *uint        `json:"payee_id"`
+	Amount          money.Amount `json:"amount"`
+	Currency        string       `json:"currency"`
+	Type            string       `json:"type"`
+	Remarks         string       `json:"remarks"`
+	Tags            string       `json:"tags"`
+	TransactionDate time.Time    `json:"transaction_date"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 func (c *Transaction) ToPublicTransaction() PublicTransaction {
@@ -50,9 +68,12 @@ func (c *Transaction) ToPublicTransaction() PublicTransaction {
 		ID:              c.ID,
 		UserID:          c.UserID,
 		CategoryID:      c.CategoryID,
+		PayeeID:         c.PayeeID,
 		Amount:          c.Amount,
+		Currency:        c.Currency,
 		Type:            c.Type,
 		Remarks:         c.Remarks,
+		Tags:            c.Tags,
 		TransactionDate: c.TransactionDate,
 		CreatedAt:       c.CreatedAt,
 		UpdatedAt:       c.UpdatedAt,
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"gorm.io/gorm"
+)
+
+// DefaultTrashRetentionDays adalah lama data di trash sebelum dihapus permanen
+// jika TRASH_RETENTION_DAYS tidak diisi
+const DefaultTrashRetentionDays = 30
+
+// Mode penghapusan category terhadap data yang mereferensikannya
+const (
+	// CategoryDeleteBlock menolak penghapusan jika category masih dipakai
+	CategoryDeleteBlock = "block"
+	// CategoryDeleteReassign memindahkan data ke category lain
+	CategoryDeleteReassign = "reassign"
+	// CategoryDeleteCascade ikut menghapus transaction dan budget category
+	CategoryDeleteCascade = "cascade"
+)
+
+var (
+	// ErrCategoryDeleted dikembalikan saat restore transaction yang category-nya
+	// masih di trash
+	ErrCategoryDeleted = errors.New("category is in the trash, restore it first")
+
+	// ErrInvalidReassignTarget dikembalikan jika category tujuan reassign tidak
+	// valid (tidak ada, milik user lain, atau sama dengan yang dihapus)
+	ErrInvalidReassignTarget = errors.New("target category is not valid")
+)
+
+// TrashRetention membaca masa simpan trash dari env TRASH_RETENTION_DAYS
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days < 1 {
+		days = DefaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// CategoryReferences adalah jumlah data aktif yang mereferensikan category
+type CategoryReferences struct {
+	Transactions     int64 `json:"transactions"`
+	Budgets          int64 `json:"budgets"`
+	Goals            int64 `json:"goals"`
+	Loans            int64 `json:"loans"`
+	Bills            int64 `json:"bills"`
+	AlertRules       int64 `json:"alert_rules"`
+	TransactionRules int64 `json:"transaction_rules"` // Rule aktif yang mengisi category ini
+}
+
+// Total adalah jumlah semua referensi
+func (r CategoryReferences) Total() int64 {
+	return r.Transactions + r.Budgets + r.Goals + r.Loans + r.Bills + r.AlertRules + r.TransactionRules
+}
+
+// CategoryInUseError dikembalikan jika category tidak bisa dihapus dengan mode
+// yang dipilih karena masih dipakai
+type CategoryInUseError struct {
+	References CategoryReferences
+	Reason     string
+}
+
+func (e *CategoryInUseError) Error() string {
+	return e.Reason
+}
+
+// CountCategoryReferences menghitung data aktif yang memakai category,
+// termasuk auto-categorization rule aktif yang mengisi category tersebut
+func CountCategoryReferences(tx *gorm.DB, userID, categoryID uint) (CategoryReferences, error) {
+	var refs CategoryReferences
+	counts := []struct {
+		model interface{}
+		count *int64
+	}{
+		{&Transaction{}, &refs.Transactions},
+		{&Budget{}, &refs.Budgets},
+		{&Goal{}, &refs.Goals},
+		{&Loan{}, &refs.Loans},
+		{&Bill{}, &refs.Bills},
+		{&AlertRule{}, &refs.AlertRules},
+	}
+	for _, c := range counts {
+		if err := tx.Model(c.model).Where("user_id = ? AND category_id = ?", userID, categoryID).Count(c.count).Error; err != nil {
+			return refs, err
+		}
+	}
+	if err := tx.Model(&TransactionRule{}).Where("user_id = ? AND set_category_id = ? AND active = ?", userID, categoryID, true).Count(&refs.TransactionRules).Error; err != nil {
+		return refs, err
+	}
+	return refs, nil
+}
+
+// DeleteCategory memindahkan category ke trash sesuai mode:
+//   - block: gagal dengan CategoryInUseError jika masih ada data yang memakainya
+//   - reassign: transaction, goal, loan, bill, alert rule dan auto-categorization
+//     rule dipindah ke targetID; budget ikut masuk trash
+//   - cascade: transaction dan budget ikut masuk trash, alert rule dan rule yang
+//     mengarah ke category dinonaktifkan. Goal, loan dan bill tidak ikut
+//     dihapus, sehingga harus dipindah atau dihapus lebih dulu.
+//
+// Data yang ikut masuk trash memakai DeletedAt yang sama dengan category,
+// sehingga bisa dikembalikan bersama oleh RestoreCategory.
+func DeleteCategory(ctx context.Context, userID, categoryID uint, mode string, targetID uint, now time.Time) (Category, error) {
+	var category Category
+	err := database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error; err != nil {
+			return err
+		}
+
+		refs, err := CountCategoryReferences(tx, userID, categoryID)
+		if err != nil {
+			return err
+		}
+
+		// Dibulatkan ke detik agar DeletedAt yang sama bisa dicocokkan kembali
+		// di semua database
+		deletedAt := gorm.DeletedAt{Time: now.Truncate(time.Second), Valid: true}
+
+		switch mode {
+		case CategoryDeleteBlock:
+			if refs.Total() > 0 {
+				return &CategoryInUseError{References: refs, Reason: "category is still in use, reassign or cascade instead"}
+			}
+
+		case CategoryDeleteReassign:
+			var target Category
+			if targetID == 0 || targetID == categoryID ||
+				tx.Where("id = ? AND user_id = ?", targetID, userID).First(&target).Error != nil {
+				return ErrInvalidReassignTarget
+			}
+			for _, model := range []interface{}{&Transaction{}, &Goal{}, &Loan{}, &Bill{}, &AlertRule{}} {
+				if err := tx.Model(model).Where("user_id = ? AND category_id = ?", userID, categoryID).Update("category_id", targetID).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&TransactionRule{}).Where("user_id = ? AND set_category_id = ?", userID, categoryID).Update("set_category_id", targetID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&Budget{}).Where("user_id = ? AND category_id = ?", userID, categoryID).Update("deleted_at", deletedAt).Error; err != nil {
+				return err
+			}
+
+		case CategoryDeleteCascade:
+			if refs.Goals+refs.Loans+refs.Bills > 0 {
+				return &CategoryInUseError{References: refs, Reason: "category is used by goals, loans or bills, reassign or delete them first"}
+			}
+			for _, model := range []interface{}{&Transaction{}, &Budget{}} {
+				if err := tx.Model(model).Where("user_id = ? AND category_id = ?", userID, categoryID).Update("deleted_at", deletedAt).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&AlertRule{}).Where("user_id = ? AND category_id = ?", userID, categoryID).Update("active", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&TransactionRule{}).Where("user_id = ? AND set_category_id = ?", userID, categoryID).Update("active", false).Error; err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("mode must be %s, %s or %s", CategoryDeleteBlock, CategoryDeleteReassign, CategoryDeleteCascade)
+		}
+
+		category.DeletedAt = deletedAt
+		return tx.Model(&category).Update("deleted_at", deletedAt).Error
+	})
+	return category, err
+}
+
+// RestoreCategory mengembalikan category dari trash beserta transaction dan
+// budget yang ikut terhapus bersamanya
+func RestoreCategory(ctx context.Context, userID, categoryID uint) (Category, error) {
+	var category Category
+	err := database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", categoryID, userID).First(&category).Error; err != nil {
+			return err
+		}
+
+		deletedAt := category.DeletedAt.Time
+		for _, model := range []interface{}{&Transaction{}, &Budget{}} {
+			if err := tx.Unscoped().Model(model).
+				Where("user_id = ? AND category_id = ? AND deleted_at = ?", userID, categoryID, deletedAt).
+				Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		category.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&category).Update("deleted_at", nil).Error
+	})
+	return category, err
+}
+
+// RestoreTransaction mengembalikan transaction dari trash. Gagal dengan
+// ErrCategoryDeleted jika category-nya masih di trash.
+func RestoreTransaction(ctx context.Context, userID, transactionID uint) (Transaction, error) {
+	var transaction Transaction
+	err := database.Transaction(database.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", transactionID, userID).First(&transaction).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&Category{}).Where("id = ?", transaction.CategoryID).Count(&active).Error; err != nil {
+			return err
+		}
+		if active == 0 {
+			return ErrCategoryDeleted
+		}
+
+		transaction.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&transaction).Update("deleted_at", nil).Error
+	})
+	return transaction, err
+}
+
+// TrashedTransaction adalah transaction di trash beserta jadwal purge-nya
+type TrashedTransaction struct {
+	PublicTransaction
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// TrashedCategory adalah category di trash beserta jadwal purge-nya
+type TrashedCategory struct {
+	PublicCategory
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// Trash adalah isi trash seorang user
+type Trash struct {
+	Transactions []TrashedTransaction `json:"transactions"`
+	Categories   []TrashedCategory    `json:"categories"`
+}
+
+// GetTrash mengambil transaction dan category user yang ada di trash,
+// terbaru lebih dulu
+func GetTrash(userID uint, retention time.Duration) (Trash, error) {
+	trash := Trash{Transactions: []TrashedTransaction{}, Categories: []TrashedCategory{}}
+
+	var transactions []Transaction
+	if err := database.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id DESC").
+		Find(&transactions).Error; err != nil {
+		return trash, err
+	}
+	for _, t := range transactions {
+		trash.Transactions = append(trash.Transactions, TrashedTransaction{
+			PublicTransaction: t.ToPublicTransaction(),
+			DeletedAt:         t.DeletedAt.Time,
+			PurgeAt:           t.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	var categories []Category
+	if err := database.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id DESC").
+		Find(&categories).Error; err != nil {
+		return trash, err
+	}
+	for _, c := range categories {
+		trash.Categories = append(trash.Categories, TrashedCategory{
+			PublicCategory: c.ToPublicCategory(),
+			DeletedAt:      c.DeletedAt.Time,
+			PurgeAt:        c.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	return trash, nil
+}
+
+// ExpiredTrashTransactionIDs mengambil id transaction yang sudah di trash
+// sebelum `before` dan siap dihapus permanen
+func ExpiredTrashTransactionIDs(before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := database.DB.Unscoped().
+		Model(&Transaction{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// PurgeTransactions menghapus permanen transaction yang ada di trash
+func PurgeTransactions(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return database.DB.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&Transaction{}).Error
+}
+
+// PurgeTrash menghapus permanen budget dan category yang sudah di trash
+// sebelum `before`. Category yang masih punya transaction di trash
+// dilewati dulu agar transaction-nya tetap bisa di-restore bersama,
+// begitu juga category yang masih direferensikan budget (foreign key).
+func PurgeTrash(before time.Time) (budgets, categories int64, err error) {
+	result := database.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&Budget{})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	budgets = result.RowsAffected
+
+	result = database.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("id NOT IN (?)", database.DB.Unscoped().Model(&Transaction{}).Select("category_id")).
+		Where("id NOT IN (?)", database.DB.Unscoped().Model(&Budget{}).Select("category_id")).
+		Delete(&Category{})
+	if result.Error != nil {
+		return budgets, 0, result.Error
+	}
+	return budgets, result.RowsAffected, nil
+}
@@ -9,12 +9,16 @@ import (
 )
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name" gorm:"not null;size:100"`
-	Email     string    `json:"email" gorm:"uniqueIndex;not null;size:100"`
-	Password  string    `json:"-" gorm:"not null"` // json:"-" avoid password serialized to json
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Name           string    `json:"name" gorm:"not null;size:100"`
+	Email          string    `json:"email" gorm:"uniqueIndex;not null;size:100"`
+	Password       string    `json:"-" gorm:"not null"`                                        // json:"-" avoid password serialized to json
+	BaseCurrency   string    `json:"base_currency" gorm:"not null;size:3;default:'IDR'"`       // currency budget & report
+	PeriodStartDay int       `json:"period_start_day" gorm:"not null;default:1"`               // tanggal awal periode budget (tanggal gajian)
+	TimeZone       string    `json:"time_zone" gorm:"not null;size:64;default:'Asia/Jakarta'"` // zona waktu input & tampilan tanggal
+	Locale         string    `json:"locale" gorm:"not null;size:16;default:'id-ID'"`           // bahasa & format tanggal
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 func (User) TableName() string {
@@ -52,17 +56,26 @@ func (u *User) CheckPassword(password string) error {
 }
 
 type PublicUser struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	BaseCurrency   string    `json:"base_currency"`
+	PeriodStartDay int       `json:"period_start_day"`
+	TimeZone       string    `json:"time_zone"`
+	Locale         string    `json:"locale"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 func (u *User) ToPublicUser() PublicUser {
+	calendar := u.Calendar()
 	return PublicUser{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		BaseCurrency:   u.BaseCurrency,
+		PeriodStartDay: calendar.PeriodStartDay,
+		TimeZone:       calendar.Location.String(),
+		Locale:         calendar.Locale,
+		CreatedAt:      calendar.In(u.CreatedAt),
 	}
 }
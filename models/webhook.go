@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook adalah endpoint milik user yang menerima event perubahan data.
+// Events berisi daftar event yang dipisahkan koma, atau "*" untuk semua event.
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	URL       string    `json:"url" gorm:"not null;size:255"`
+	Secret    string    `json:"-" gorm:"not null;size:100"` // json:"-" agar secret tidak ikut di response list
+	Events    string    `json:"events" gorm:"not null;size:500"`
+	Active    bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes mengecek apakah webhook berlangganan event tertentu
+func (w *Webhook) Subscribes(event string) bool {
+	for _, subscribed := range strings.Split(w.Events, ",") {
+		subscribed = strings.TrimSpace(subscribed)
+		if subscribed == "*" || subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending = "pending"
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed"
+)
+
+// WebhookDelivery adalah log setiap pengiriman event ke sebuah webhook,
+// termasuk retry yang dijadwalkan
+type WebhookDelivery struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	WebhookID     uint       `json:"webhook_id" gorm:"not null;index"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	Event         string     `json:"event" gorm:"not null;size:50"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"not null;size:20;index"`
+	Attempts      uint       `json:"attempts" gorm:"not null;default:0"`
+	ResponseCode  int        `json:"response_code"`
+	LastError     string     `json:"last_error" gorm:"size:500"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
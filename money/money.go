@@ -0,0 +1,158 @@
+// Package money menyimpan nominal uang sebagai integer dalam minor unit
+// currency (sen untuk USD, rupiah untuk IDR) agar bebas dari error floating
+// point, dengan exponent currency mengikuti ISO 4217.
+package money
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount adalah nominal bertanda dalam minor unit. Nilai negatif dipakai
+// untuk refund dan koreksi.
+type Amount int64
+
+// DefaultExponent dipakai untuk currency yang tidak ada di daftar exponents
+const DefaultExponent = 2
+
+// exponents adalah jumlah digit desimal minor unit untuk currency yang
+// berbeda dari DefaultExponent. IDR memakai 0 karena sen tidak lagi dipakai
+// dalam transaksi sehari-hari.
+var exponents = map[string]int{
+	"IDR": 0, "JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"PYG": 0, "UGX": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+var (
+	// ErrInvalidAmount dikembalikan jika input bukan angka yang valid
+	ErrInvalidAmount = errors.New("amount must be an integer in minor units")
+
+	// ErrTooPrecise dikembalikan jika input punya digit desimal melebihi
+	// exponent currency
+	ErrTooPrecise = errors.New("amount has more decimal places than the currency allows")
+
+	// ErrOutOfRange dikembalikan jika input melebihi batas int64
+	ErrOutOfRange = errors.New("amount is out of range")
+)
+
+// Exponent mengembalikan jumlah digit minor unit sebuah currency
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return DefaultExponent
+}
+
+// ExponentGroups mengelompokkan currency yang terdaftar berdasarkan
+// exponent-nya. Currency lain memakai DefaultExponent.
+func ExponentGroups() map[int][]string {
+	groups := map[int][]string{}
+	for currency, exp := range exponents {
+		groups[exp] = append(groups[exp], currency)
+	}
+	return groups
+}
+
+// UnmarshalJSON menerima angka atau string berisi integer minor unit,
+// misalnya 150000, "-2500". Pecahan dan notasi eksponen ditolak.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = bytes.TrimSpace(data[1 : len(data)-1])
+	}
+
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return ErrOutOfRange
+		}
+		return ErrInvalidAmount
+	}
+	*a = Amount(value)
+	return nil
+}
+
+// ParseMajor mengubah string desimal dalam major unit ("12.50") menjadi
+// Amount sesuai exponent currency tanpa melewati float
+func ParseMajor(input, currency string) (Amount, error) {
+	input = strings.TrimSpace(input)
+	exp := Exponent(currency)
+
+	negative := false
+	switch {
+	case strings.HasPrefix(input, "-"):
+		negative = true
+		input = input[1:]
+	case strings.HasPrefix(input, "+"):
+		input = input[1:]
+	}
+
+	whole, fraction, hasFraction := strings.Cut(input, ".")
+	if whole == "" && (!hasFraction || fraction == "") {
+		return 0, ErrInvalidAmount
+	}
+	if len(strings.TrimRight(fraction, "0")) > exp {
+		return 0, ErrTooPrecise
+	}
+	fraction += strings.Repeat("0", exp)
+	digits := whole + fraction[:exp]
+	if strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, ErrInvalidAmount
+	}
+	if digits == "" {
+		digits = "0"
+	}
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	if negative {
+		value = -value
+	}
+	return Amount(value), nil
+}
+
+// Format menampilkan Amount dalam major unit, misalnya 1250 USD menjadi "12.50"
+func (a Amount) Format(currency string) string {
+	exp := Exponent(currency)
+	value := int64(a)
+
+	sign := ""
+	magnitude := uint64(value)
+	if value < 0 {
+		sign = "-"
+		magnitude = uint64(-(value + 1)) + 1
+	}
+	digits := strconv.FormatUint(magnitude, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Convert mengubah Amount dari currency `from` ke `to` dengan rate (1 from
+// = rate to), memperhitungkan perbedaan exponent kedua currency
+func (a Amount) Convert(from, to string, rate float64) Amount {
+	shift := Exponent(to) - Exponent(from)
+	return Amount(math.Round(float64(a) * rate * math.Pow10(shift)))
+}
+
+// Abs mengembalikan nilai absolut Amount
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// EmailNotifier mengirim notifikasi via SMTP ke Message.Email
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewEmailNotifierFromEnv membaca konfigurasi SMTP dari env SMTP_HOST,
+// SMTP_PORT, SMTP_USER, SMTP_PASSWORD dan SMTP_FROM
+func NewEmailNotifierFromEnv() *EmailNotifier {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = os.Getenv("SMTP_USER")
+	}
+
+	return &EmailNotifier{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	if e.Host == "" {
+		return fmt.Errorf("SMTP_HOST is not set")
+	}
+	if msg.Email == "" {
+		return fmt.Errorf("recipient email is empty")
+	}
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+
+	// Hindari header injection dari subject
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(msg.Subject)
+
+	body := "From: " + e.From + "\r\n" +
+		"To: " + msg.Email + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n" +
+		"\r\n" + msg.Body + "\r\n"
+
+	return smtp.SendMail(e.Host+":"+e.Port, auth, e.From, []string{msg.Email}, []byte(body))
+}
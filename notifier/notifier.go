@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+)
+
+// Message adalah notifikasi yang akan dikirim ke user
+type Message struct {
+	UserID  uint
+	Email   string
+	Name    string
+	Subject string
+	Body    string
+}
+
+// Notifier mengirim Message melalui sebuah channel (log, webhook, email, ...)
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier hanya menulis notifikasi ke log, cocok untuk development
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Printf("🔔 Notify user %d <%s>: %s - %s", msg.UserID, msg.Email, msg.Subject, msg.Body)
+	return nil
+}
+
+// FromEnv membuat Notifier berdasarkan env NOTIFIER (log, webhook, email).
+// Default ke LogNotifier jika tidak diisi atau tidak dikenal.
+func FromEnv() Notifier {
+	switch strings.ToLower(os.Getenv("NOTIFIER")) {
+	case "", "log":
+		return LogNotifier{}
+	case "webhook":
+		return NewWebhookNotifier(os.Getenv("NOTIFIER_WEBHOOK_URL"))
+	case "email":
+		return NewEmailNotifierFromEnv()
+	default:
+		log.Printf("Warning: unknown NOTIFIER %q, falling back to log", os.Getenv("NOTIFIER"))
+		return LogNotifier{}
+	}
+}
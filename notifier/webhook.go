@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ashborn.id/moniplan/safehttp"
+)
+
+// WebhookNotifier mengirim notifikasi sebagai JSON POST ke URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// userClient dipakai untuk URL yang diisi user, hanya bisa terhubung ke
+// alamat publik
+var userClient = safehttp.NewClient(10 * time.Second)
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewUserWebhookNotifier membuat WebhookNotifier untuk URL milik user,
+// misalnya target alert rule. Koneksi ke loopback dan jaringan privat
+// ditolak, termasuk jika DNS-nya berubah setelah URL divalidasi.
+func NewUserWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: userClient}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"user_id": msg.UserID,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGorm membuat repository yang menyimpan data lewat GORM
+func NewGorm(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:        &gormUserRepository{db: db},
+		Categories:   &gormCategoryRepository{db: db},
+		Budgets:      &gormBudgetRepository{db: db},
+		Templates:    &gormBudgetTemplateRepository{db: db},
+		Transactions: &gormTransactionRepository{db: db},
+		Rules:        &gormTransactionRuleRepository{db: db},
+		Payees:       &gormPayeeRepository{db: db},
+		UnitOfWork:   &gormUnitOfWork{db: db},
+	}
+}
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(repos Repositories) error) error {
+	return database.Transaction(u.db.WithContext(ctx), func(tx *gorm.DB) error {
+		return fn(NewGorm(tx))
+	})
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	return user, err
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	return user, err
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *gormUserRepository) UpdatePreferences(ctx context.Context, user *models.User) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"period_start_day": user.PeriodStartDay,
+		"time_zone":        user.TimeZone,
+		"locale":           user.Locale,
+		"updated_at":       time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormCategoryRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormCategoryRepository) FindByID(ctx context.Context, userID, id uint) (models.Category, error) {
+	var category models.Category
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&category).Error
+	return category, err
+}
+
+func (r *gormCategoryRepository) FindByName(ctx context.Context, userID uint, name string) (models.Category, error) {
+	var category models.Category
+	err := r.db.WithContext(ctx).Where("name = ? AND user_id = ?", name, userID).First(&category).Error
+	return category, err
+}
+
+func (r *gormCategoryRepository) Upsert(ctx context.Context, category *models.Category) error {
+	db := r.db.WithContext(ctx)
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": category.UpdatedAt,
+		}),
+	}).Create(category).Error
+	if err != nil {
+		return err
+	}
+
+	// ID dari insert tidak bisa dipercaya saat terjadi konflik di MySQL,
+	// jadi baris yang tersimpan dibaca ulang
+	var saved models.Category
+	if err := db.Where("user_id = ? AND name = ?", category.UserID, category.Name).First(&saved).Error; err != nil {
+		return err
+	}
+	*category = saved
+	return nil
+}
+
+func (r *gormCategoryRepository) ListWithLatestBudget(ctx context.Context, userID uint) ([]models.CategoryAndBudget, error) {
+	db := r.db.WithContext(ctx)
+
+	subQuery := db.
+		Table("budgets").
+		Select("category_id, MAX(id) as latest_id").
+		Where("deleted_at IS NULL").
+		Group("category_id")
+
+	var categories []models.CategoryAndBudget
+	err := db.
+		Table("categories c").
+		Select("c.id, b.category_id, c.user_id, c.name, b.month, b.year, b.amount").
+		Joins("JOIN budgets b ON b.category_id = c.id").
+		Joins("JOIN (?) latest ON b.category_id = latest.category_id AND b.id = latest.latest_id", subQuery).
+		Where("c.user_id = ? AND c.deleted_at IS NULL", userID).
+		Order("c.name ASC").
+		Scan(&categories).Error
+	return categories, err
+}
+
+type gormBudgetRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormBudgetRepository) FindForPeriod(ctx context.Context, userID, categoryID, year, month uint) (models.Budget, error) {
+	var budget models.Budget
+	err := r.db.WithContext(ctx).
+		Where("category_id = ? AND user_id = ? AND year = ? AND month = ?", categoryID, userID, year, month).
+		Last(&budget).Error
+	return budget, err
+}
+
+func (r *gormBudgetRepository) FindLatest(ctx context.Context, userID, categoryID uint) (models.Budget, error) {
+	var budget models.Budget
+	err := r.db.WithContext(ctx).Where("category_id = ? AND user_id = ?", categoryID, userID).Last(&budget).Error
+	return budget, err
+}
+
+func (r *gormBudgetRepository) Upsert(ctx context.Context, budget *models.Budget) error {
+	db := r.db.WithContext(ctx)
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "category_id"}, {Name: "year"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"amount":     budget.Amount,
+			"deleted_at": nil,
+			"updated_at": budget.UpdatedAt,
+		}),
+	}).Create(budget).Error
+	if err != nil {
+		return err
+	}
+
+	saved, err := r.FindForPeriod(ctx, budget.UserID, budget.CategoryID, budget.Year, budget.Month)
+	if err != nil {
+		return err
+	}
+	*budget = saved
+	return nil
+}
+
+func (r *gormBudgetRepository) FindByID(ctx context.Context, userID, id uint) (models.Budget, error) {
+	var budget models.Budget
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&budget).Error
+	return budget, err
+}
+
+func (r *gormBudgetRepository) ListForPeriod(ctx context.Context, userID, year, month uint) ([]models.BudgetCategory, error) {
+	var budgets []models.BudgetCategory
+	err := r.db.WithContext(ctx).
+		Table("budgets b").
+		Select("b.id, b.user_id, b.category_id, c.name as category_name, b.month, b.year, b.amount, b.created_at, b.updated_at").
+		Joins("JOIN categories c ON c.id = b.category_id").
+		Where("b.user_id = ? AND b.year = ? AND b.month = ?", userID, year, month).
+		Where("b.deleted_at IS NULL AND c.deleted_at IS NULL").
+		Order("c.name ASC").
+		Scan(&budgets).Error
+	return budgets, err
+}
+
+func (r *gormBudgetRepository) ListForYear(ctx context.Context, userID, year uint) ([]models.BudgetCategory, error) {
+	var budgets []models.BudgetCategory
+	err := r.db.WithContext(ctx).
+		Table("budgets b").
+		Select("b.id, b.user_id, b.category_id, c.name as category_name, b.month, b.year, b.amount, b.created_at, b.updated_at").
+		Joins("JOIN categories c ON c.id = b.category_id").
+		Where("b.user_id = ? AND b.year = ?", userID, year).
+		Where("b.deleted_at IS NULL AND c.deleted_at IS NULL").
+		Order("c.name ASC, b.month ASC").
+		Scan(&budgets).Error
+	return budgets, err
+}
+
+func (r *gormBudgetRepository) History(ctx context.Context, userID, categoryID uint) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND category_id = ?", userID, categoryID).
+		Order("year ASC, month ASC").
+		Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *gormBudgetRepository) Delete(ctx context.Context, userID, id uint) error {
+	return deleteResult(r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Budget{}, id))
+}
+
+type gormBudgetTemplateRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormBudgetTemplateRepository) List(ctx context.Context, userID uint) ([]models.BudgetTemplate, error) {
+	var templates []models.BudgetTemplate
+	err := r.db.WithContext(ctx).Preload("Items").Where("user_id = ?", userID).Order("name ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *gormBudgetTemplateRepository) FindByID(ctx context.Context, userID, id uint) (models.BudgetTemplate, error) {
+	var template models.BudgetTemplate
+	err := r.db.WithContext(ctx).Preload("Items").Where("id = ? AND user_id = ?", id, userID).First(&template).Error
+	return template, err
+}
+
+func (r *gormBudgetTemplateRepository) Save(ctx context.Context, template *models.BudgetTemplate) error {
+	db := r.db.WithContext(ctx)
+
+	if template.ID == 0 {
+		if err := db.Omit("Items").Create(template).Error; err != nil {
+			return err
+		}
+	} else {
+		result := db.Model(&models.BudgetTemplate{}).
+			Where("id = ? AND user_id = ?", template.ID, template.UserID).
+			Updates(map[string]interface{}{"name": template.Name, "updated_at": template.UpdatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := db.Where("template_id = ?", template.ID).Delete(&models.BudgetTemplateItem{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for i := range template.Items {
+		template.Items[i].ID = 0
+		template.Items[i].TemplateID = template.ID
+	}
+	if len(template.Items) == 0 {
+		return nil
+	}
+	return db.Create(&template.Items).Error
+}
+
+func (r *gormBudgetTemplateRepository) Delete(ctx context.Context, userID, id uint) error {
+	db := r.db.WithContext(ctx)
+	result := db.Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&models.BudgetTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	if err := db.Where("template_id = ?", id).Delete(&models.BudgetTemplateItem{}).Error; err != nil {
+		return err
+	}
+	return deleteResult(db.Where("user_id = ?", userID).Delete(&models.BudgetTemplate{}, id))
+}
+
+type gormTransactionRepository struct {
+	db *gorm.DB
+}
+
+// transactionListRow adalah hasil query List sebelum transaction_date
+// diformat, agar query tidak bergantung pada fungsi tanggal database
+type transactionListRow struct {
+	ID              uint
+	UserID          uint
+	CategoryID      uint
+	CategoryName    string
+	PayeeID         *uint
+	PayeeName       *string
+	Amount          money.Amount
+	Currency        string
+	Type            string
+	Remarks         string
+	Tags            string
+	TransactionDate time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (r *gormTransactionRepository) List(ctx context.Context, filter TransactionFilter) ([]models.TransactionCategoryBudget, error) {
+	start, end := periodRange(filter)
+
+	query := r.db.WithContext(ctx).
+		Table("transactions t").
+		Select("t.id, t.user_id, t.category_id, t.amount, t.currency, t.type, t.remarks, t.tags, t.transaction_date, t.created_at, t.updated_at, c.name as category_name, t.payee_id, p.name as payee_name").
+		Joins("JOIN categories c ON c.id = t.category_id").
+		Joins("LEFT JOIN payees p ON p.id = t.payee_id").
+		Where("t.user_id = ? AND t.transaction_date >= ? AND t.transaction_date < ? AND t.deleted_at IS NULL", filter.UserID, start, end)
+
+	if filter.CategoryID != 0 {
+		query = query.Where("t.category_id = ?", filter.CategoryID)
+	}
+
+	var rows []transactionListRow
+	if err := query.Order("t.id DESC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	transactions := make([]models.TransactionCategoryBudget, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, models.TransactionCategoryBudget{
+			ID:              row.ID,
+			UserID:          row.UserID,
+			CategoryID:      row.CategoryID,
+			CategoryName:    row.CategoryName,
+			PayeeID:         row.PayeeID,
+			PayeeName:       row.PayeeName,
+			Amount:          row.Amount,
+			Currency:        row.Currency,
+			Type:            row.Type,
+			Remarks:         row.Remarks,
+			Tags:            row.Tags,
+			TransactionDate: filter.Calendar.FormatDateTime(row.TransactionDate),
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+		})
+	}
+	return transactions, nil
+}
+
+func (r *gormTransactionRepository) FindByID(ctx context.Context, userID, id uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error
+	return transaction, err
+}
+
+func (r *gormTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	return r.db.WithContext(ctx).Create(transaction).Error
+}
+
+func (r *gormTransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	return r.db.WithContext(ctx).Updates(transaction).Error
+}
+
+func (r *gormTransactionRepository) Delete(ctx context.Context, userID, id uint) error {
+	return deleteResult(r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Transaction{}, id))
+}
+
+type gormTransactionRuleRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTransactionRuleRepository) ListActive(ctx context.Context, userID uint) ([]models.TransactionRule, error) {
+	var rules []models.TransactionRule
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND active = ?", userID, true).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+type gormPayeeRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPayeeRepository) FindByID(ctx context.Context, userID, id uint) (models.Payee, error) {
+	var payee models.Payee
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&payee).Error
+	return payee, err
+}
+
+func (r *gormPayeeRepository) List(ctx context.Context, userID uint) ([]models.Payee, error) {
+	var payees []models.Payee
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&payees).Error
+	return payees, err
+}
+
+func (r *gormPayeeRepository) ListAliases(ctx context.Context, userID uint) ([]models.PayeeAlias, error) {
+	var aliases []models.PayeeAlias
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&aliases).Error
+	return aliases, err
+}
+
+// deleteResult mengubah delete yang tidak mengenai baris apa pun, karena ID
+// tidak ada, milik user lain atau sudah di trash, menjadi ErrNotFound
+func deleteResult(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
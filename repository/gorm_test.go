@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/migrations"
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database SQLite baru yang sudah dimigrasi
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{
+		Driver: database.DriverSQLite,
+		Name:   filepath.Join(t.TempDir(), "moniplan.db"),
+	}, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := migrations.Up(db, func(string) {}); err != nil {
+		t.Fatalf("migrations.Up: %v", err)
+	}
+	return db
+}
+
+func TestGormTransactionListPeriod(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repos := NewGorm(db)
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "secret123", PeriodStartDay: 25, TimeZone: "Asia/Jakarta"}
+	if err := repos.Users.Create(ctx, &user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	food := models.Category{UserID: user.ID, Name: "food"}
+	transport := models.Category{UserID: user.ID, Name: "transport"}
+	for _, category := range []*models.Category{&food, &transport} {
+		if err := repos.Categories.Upsert(ctx, category); err != nil {
+			t.Fatalf("Upsert category: %v", err)
+		}
+	}
+	payee := models.Payee{UserID: user.ID, Name: "Warung Bu Sri"}
+	if err := db.Create(&payee).Error; err != nil {
+		t.Fatalf("Create payee: %v", err)
+	}
+
+	// Periode Mei dimulai tanggal 25 April pukul 00:00 WIB
+	calendar := user.Calendar()
+	period := calendar.PeriodRange(2026, 5)
+	newTransaction := func(categoryID uint, at time.Time, remarks string) models.Transaction {
+		transaction := models.Transaction{
+			UserID:          user.ID,
+			CategoryID:      categoryID,
+			Amount:          10000,
+			Currency:        "IDR",
+			Type:            models.TransactionTypeExpense,
+			Remarks:         remarks,
+			TransactionDate: at,
+		}
+		if err := repos.Transactions.Create(ctx, &transaction); err != nil {
+			t.Fatalf("Create transaction %s: %v", remarks, err)
+		}
+		return transaction
+	}
+
+	newTransaction(food.ID, period.Start.Add(-time.Second), "before")
+	first := newTransaction(food.ID, period.Start, "first")
+	last := newTransaction(transport.ID, period.End.Add(-time.Second), "last")
+	newTransaction(food.ID, period.End, "after")
+	deleted := newTransaction(food.ID, period.Start.Add(time.Hour), "deleted")
+	if err := repos.Transactions.Delete(ctx, user.ID, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := db.Model(&first).Update("payee_id", payee.ID).Error; err != nil {
+		t.Fatalf("Update payee: %v", err)
+	}
+
+	list, err := repos.Transactions.List(ctx, TransactionFilter{UserID: user.ID, Year: 2026, Month: 5, Calendar: calendar})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != last.ID || list[1].ID != first.ID {
+		t.Fatalf("List = %+v, want last and first only, newest first", list)
+	}
+	if list[0].CategoryName != "transport" || list[0].PayeeName != nil {
+		t.Errorf("List[0] category/payee = %q/%v, want transport without payee", list[0].CategoryName, list[0].PayeeName)
+	}
+	if list[1].PayeeName == nil || *list[1].PayeeName != payee.Name {
+		t.Errorf("List[1].PayeeName = %v, want %q", list[1].PayeeName, payee.Name)
+	}
+	if want := calendar.FormatDateTime(period.Start); list[1].TransactionDate != want {
+		t.Errorf("List[1].TransactionDate = %q, want %q", list[1].TransactionDate, want)
+	}
+
+	filtered, err := repos.Transactions.List(ctx, TransactionFilter{UserID: user.ID, Year: 2026, Month: 5, Calendar: calendar, CategoryID: food.ID})
+	if err != nil {
+		t.Fatalf("List by category: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != first.ID {
+		t.Errorf("List by category = %+v, want first only", filtered)
+	}
+}
+
+func TestGormUpsertRestoresTrashedRows(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repos := NewGorm(db)
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "secret123"}
+	if err := repos.Users.Create(ctx, &user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+
+	category := models.Category{UserID: user.ID, Name: "food"}
+	if err := repos.Categories.Upsert(ctx, &category); err != nil {
+		t.Fatalf("Upsert category: %v", err)
+	}
+	budget := models.Budget{UserID: user.ID, CategoryID: category.ID, Year: 2026, Month: 5, Amount: 100000}
+	if err := repos.Budgets.Upsert(ctx, &budget); err != nil {
+		t.Fatalf("Upsert budget: %v", err)
+	}
+
+	if err := db.Delete(&category).Error; err != nil {
+		t.Fatalf("Delete category: %v", err)
+	}
+	if err := repos.Budgets.Delete(ctx, user.ID, budget.ID); err != nil {
+		t.Fatalf("Delete budget: %v", err)
+	}
+
+	restored := models.Category{UserID: user.ID, Name: "food"}
+	if err := repos.Categories.Upsert(ctx, &restored); err != nil {
+		t.Fatalf("Upsert trashed category: %v", err)
+	}
+	if restored.ID != category.ID || restored.DeletedAt.Valid {
+		t.Errorf("Upsert trashed category = %+v, want category %d restored", restored, category.ID)
+	}
+
+	updated := models.Budget{UserID: user.ID, CategoryID: category.ID, Year: 2026, Month: 5, Amount: 150000}
+	if err := repos.Budgets.Upsert(ctx, &updated); err != nil {
+		t.Fatalf("Upsert trashed budget: %v", err)
+	}
+	if updated.ID != budget.ID || updated.Amount != 150000 {
+		t.Errorf("Upsert trashed budget = %+v, want budget %d restored with 150000", updated, budget.ID)
+	}
+
+	categories, err := repos.Categories.ListWithLatestBudget(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListWithLatestBudget: %v", err)
+	}
+	if len(categories) != 1 || categories[0].ID != category.ID || categories[0].Amount != 150000 {
+		t.Errorf("ListWithLatestBudget = %+v, want food with 150000", categories)
+	}
+}
+
+// TestDeleteNotFound memastikan implementasi gorm dan memory sama-sama
+// mengembalikan ErrNotFound untuk ID yang tidak ada, milik user lain atau
+// sudah dihapus
+func TestDeleteNotFound(t *testing.T) {
+	implementations := map[string]func(t *testing.T) Repositories{
+		"gorm":   func(t *testing.T) Repositories { return NewGorm(openTestDB(t)) },
+		"memory": func(t *testing.T) Repositories { return NewMemory() },
+	}
+	for name, newRepos := range implementations {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := newRepos(t)
+
+			var users [2]models.User
+			for i, email := range []string{"budi@example.com", "sari@example.com"} {
+				users[i] = models.User{Name: "User", Email: email, Password: "secret123"}
+				if err := repos.Users.Create(ctx, &users[i]); err != nil {
+					t.Fatalf("Create user: %v", err)
+				}
+			}
+			owner, other := users[0].ID, users[1].ID
+
+			category := models.Category{UserID: owner, Name: "food"}
+			if err := repos.Categories.Upsert(ctx, &category); err != nil {
+				t.Fatalf("Upsert category: %v", err)
+			}
+			budget := models.Budget{UserID: owner, CategoryID: category.ID, Year: 2026, Month: 5, Amount: 100000}
+			if err := repos.Budgets.Upsert(ctx, &budget); err != nil {
+				t.Fatalf("Upsert budget: %v", err)
+			}
+			template := models.BudgetTemplate{UserID: owner, Name: "monthly"}
+			if err := repos.Templates.Save(ctx, &template); err != nil {
+				t.Fatalf("Save template: %v", err)
+			}
+			transaction := models.Transaction{
+				UserID:          owner,
+				CategoryID:      category.ID,
+				Amount:          25000,
+				Currency:        "IDR",
+				Type:            models.TransactionTypeExpense,
+				Remarks:         "lunch",
+				TransactionDate: time.Date(2026, 5, 10, 5, 0, 0, 0, time.UTC),
+			}
+			if err := repos.Transactions.Create(ctx, &transaction); err != nil {
+				t.Fatalf("Create transaction: %v", err)
+			}
+
+			deletes := map[string]struct {
+				delete func(userID, id uint) error
+				id     uint
+			}{
+				"budget":      {func(userID, id uint) error { return repos.Budgets.Delete(ctx, userID, id) }, budget.ID},
+				"template":    {func(userID, id uint) error { return repos.Templates.Delete(ctx, userID, id) }, template.ID},
+				"transaction": {func(userID, id uint) error { return repos.Transactions.Delete(ctx, userID, id) }, transaction.ID},
+			}
+			for entity, d := range deletes {
+				if err := d.delete(owner, d.id+1000); !errors.Is(err, ErrNotFound) {
+					t.Errorf("delete missing %s = %v, want ErrNotFound", entity, err)
+				}
+				if err := d.delete(other, d.id); !errors.Is(err, ErrNotFound) {
+					t.Errorf("delete other user's %s = %v, want ErrNotFound", entity, err)
+				}
+				if err := d.delete(owner, d.id); err != nil {
+					t.Errorf("delete %s: %v", entity, err)
+				}
+				if err := d.delete(owner, d.id); !errors.Is(err, ErrNotFound) {
+					t.Errorf("delete %s twice = %v, want ErrNotFound", entity, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,642 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// NewMemory membuat repository in-memory untuk test service dan handler
+// tanpa database. Semua repository berbagi satu store, sehingga List
+// transaction bisa mengisi nama category dan payee.
+func NewMemory() Repositories {
+	store := &memoryStore{
+		users:        map[uint]models.User{},
+		categories:   map[uint]models.Category{},
+		budgets:      map[uint]models.Budget{},
+		templates:    map[uint]models.BudgetTemplate{},
+		transactions: map[uint]models.Transaction{},
+		rules:        map[uint]models.TransactionRule{},
+		payees:       map[uint]models.Payee{},
+		aliases:      map[uint]models.PayeeAlias{},
+	}
+	return store.repositories()
+}
+
+type memoryStore struct {
+	mu           sync.Mutex
+	lastID       uint
+	users        map[uint]models.User
+	categories   map[uint]models.Category
+	budgets      map[uint]models.Budget
+	templates    map[uint]models.BudgetTemplate
+	transactions map[uint]models.Transaction
+	rules        map[uint]models.TransactionRule
+	payees       map[uint]models.Payee
+	aliases      map[uint]models.PayeeAlias
+}
+
+func (s *memoryStore) repositories() Repositories {
+	return Repositories{
+		Users:        &memoryUserRepository{s},
+		Categories:   &memoryCategoryRepository{s},
+		Budgets:      &memoryBudgetRepository{s},
+		Templates:    &memoryBudgetTemplateRepository{s},
+		Transactions: &memoryTransactionRepository{s},
+		Rules:        &memoryTransactionRuleRepository{s},
+		Payees:       &memoryPayeeRepository{s},
+		UnitOfWork:   &memoryUnitOfWork{s},
+	}
+}
+
+// nextID membuat ID baru, dipanggil saat mu sedang di-lock
+func (s *memoryStore) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// touch mengisi CreatedAt dan UpdatedAt seperti GORM
+func touch(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
+// sortedIDs mengembalikan key map secara berurutan
+func sortedIDs[T any](items map[uint]T) []uint {
+	ids := make([]uint, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id uint) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range sortedIDs(r.store.users) {
+		if user := r.store.users[id]; user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	// Jalankan hook yang sama dengan GORM (hash password)
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user.BaseCurrency == "" {
+		user.BaseCurrency = models.DefaultCurrency
+	}
+	if user.PeriodStartDay == 0 {
+		user.PeriodStartDay = models.DefaultPeriodStartDay
+	}
+	if user.TimeZone == "" {
+		user.TimeZone = models.DefaultTimeZone
+	}
+	if user.Locale == "" {
+		user.Locale = models.DefaultLocale
+	}
+	user.ID = r.store.nextID()
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePreferences(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.PeriodStartDay = user.PeriodStartDay
+	stored.TimeZone = user.TimeZone
+	stored.Locale = user.Locale
+	stored.UpdatedAt = time.Now()
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+// memoryUnitOfWork menyimpan salinan store sebelum fn dijalankan dan
+// mengembalikannya jika fn gagal. Tidak ada isolasi antar goroutine, cukup
+// untuk test yang berjalan berurutan.
+type memoryUnitOfWork struct {
+	store *memoryStore
+}
+
+func (u *memoryUnitOfWork) Do(ctx context.Context, fn func(repos Repositories) error) error {
+	u.store.mu.Lock()
+	snapshot := memoryStore{
+		lastID:       u.store.lastID,
+		users:        maps.Clone(u.store.users),
+		categories:   maps.Clone(u.store.categories),
+		budgets:      maps.Clone(u.store.budgets),
+		templates:    maps.Clone(u.store.templates),
+		transactions: maps.Clone(u.store.transactions),
+		rules:        maps.Clone(u.store.rules),
+		payees:       maps.Clone(u.store.payees),
+		aliases:      maps.Clone(u.store.aliases),
+	}
+	u.store.mu.Unlock()
+
+	if err := fn(u.store.repositories()); err != nil {
+		u.store.mu.Lock()
+		defer u.store.mu.Unlock()
+		u.store.lastID = snapshot.lastID
+		u.store.users = snapshot.users
+		u.store.categories = snapshot.categories
+		u.store.budgets = snapshot.budgets
+		u.store.templates = snapshot.templates
+		u.store.transactions = snapshot.transactions
+		u.store.rules = snapshot.rules
+		u.store.payees = snapshot.payees
+		u.store.aliases = snapshot.aliases
+		return err
+	}
+	return nil
+}
+
+type memoryCategoryRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryCategoryRepository) FindByID(ctx context.Context, userID, id uint) (models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	category, ok := r.store.categories[id]
+	if !ok || category.UserID != userID || category.DeletedAt.Valid {
+		return models.Category{}, ErrNotFound
+	}
+	return category, nil
+}
+
+func (r *memoryCategoryRepository) FindByName(ctx context.Context, userID uint, name string) (models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range sortedIDs(r.store.categories) {
+		category := r.store.categories[id]
+		if category.UserID == userID && category.Name == name && !category.DeletedAt.Valid {
+			return category, nil
+		}
+	}
+	return models.Category{}, ErrNotFound
+}
+
+func (r *memoryCategoryRepository) Upsert(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	touch(&category.CreatedAt, &category.UpdatedAt)
+	for id, existing := range r.store.categories {
+		if existing.UserID == category.UserID && existing.Name == category.Name {
+			existing.DeletedAt = gorm.DeletedAt{}
+			existing.UpdatedAt = category.UpdatedAt
+			r.store.categories[id] = existing
+			*category = existing
+			return nil
+		}
+	}
+
+	category.ID = r.store.nextID()
+	r.store.categories[category.ID] = *category
+	return nil
+}
+
+func (r *memoryCategoryRepository) ListWithLatestBudget(ctx context.Context, userID uint) ([]models.CategoryAndBudget, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	latest := map[uint]models.Budget{}
+	for _, id := range sortedIDs(r.store.budgets) {
+		if budget := r.store.budgets[id]; !budget.DeletedAt.Valid {
+			latest[budget.CategoryID] = budget
+		}
+	}
+
+	var result []models.CategoryAndBudget
+	for _, id := range sortedIDs(r.store.categories) {
+		category := r.store.categories[id]
+		budget, ok := latest[category.ID]
+		if category.UserID != userID || category.DeletedAt.Valid || !ok {
+			continue
+		}
+		result = append(result, models.CategoryAndBudget{
+			ID:         category.ID,
+			CategoryID: budget.CategoryID,
+			UserID:     category.UserID,
+			Name:       category.Name,
+			Month:      budget.Month,
+			Year:       budget.Year,
+			Amount:     budget.Amount,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+type memoryBudgetRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryBudgetRepository) FindForPeriod(ctx context.Context, userID, categoryID, year, month uint) (models.Budget, error) {
+	return r.findLast(func(budget models.Budget) bool {
+		return budget.UserID == userID && budget.CategoryID == categoryID && budget.Year == year && budget.Month == month
+	})
+}
+
+func (r *memoryBudgetRepository) FindLatest(ctx context.Context, userID, categoryID uint) (models.Budget, error) {
+	return r.findLast(func(budget models.Budget) bool {
+		return budget.UserID == userID && budget.CategoryID == categoryID
+	})
+}
+
+// findLast mengambil budget dengan ID terbesar yang cocok, seperti Last()
+func (r *memoryBudgetRepository) findLast(match func(models.Budget) bool) (models.Budget, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := sortedIDs(r.store.budgets)
+	for i := len(ids) - 1; i >= 0; i-- {
+		budget := r.store.budgets[ids[i]]
+		if !budget.DeletedAt.Valid && match(budget) {
+			return budget, nil
+		}
+	}
+	return models.Budget{}, ErrNotFound
+}
+
+func (r *memoryBudgetRepository) Upsert(ctx context.Context, budget *models.Budget) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	touch(&budget.CreatedAt, &budget.UpdatedAt)
+	for id, existing := range r.store.budgets {
+		if existing.UserID == budget.UserID && existing.CategoryID == budget.CategoryID &&
+			existing.Year == budget.Year && existing.Month == budget.Month {
+			existing.Amount = budget.Amount
+			existing.DeletedAt = gorm.DeletedAt{}
+			existing.UpdatedAt = budget.UpdatedAt
+			r.store.budgets[id] = existing
+			*budget = existing
+			return nil
+		}
+	}
+
+	budget.ID = r.store.nextID()
+	r.store.budgets[budget.ID] = *budget
+	return nil
+}
+
+func (r *memoryBudgetRepository) FindByID(ctx context.Context, userID, id uint) (models.Budget, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	budget, ok := r.store.budgets[id]
+	if !ok || budget.UserID != userID || budget.DeletedAt.Valid {
+		return models.Budget{}, ErrNotFound
+	}
+	return budget, nil
+}
+
+func (r *memoryBudgetRepository) ListForPeriod(ctx context.Context, userID, year, month uint) ([]models.BudgetCategory, error) {
+	return r.listWithCategory(func(budget models.Budget) bool {
+		return budget.UserID == userID && budget.Year == year && budget.Month == month
+	}), nil
+}
+
+func (r *memoryBudgetRepository) ListForYear(ctx context.Context, userID, year uint) ([]models.BudgetCategory, error) {
+	return r.listWithCategory(func(budget models.Budget) bool {
+		return budget.UserID == userID && budget.Year == year
+	}), nil
+}
+
+// listWithCategory mengembalikan budget yang cocok beserta nama category-nya,
+// diurutkan berdasarkan nama category lalu bulan
+func (r *memoryBudgetRepository) listWithCategory(match func(models.Budget) bool) []models.BudgetCategory {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.BudgetCategory{}
+	for _, id := range sortedIDs(r.store.budgets) {
+		budget := r.store.budgets[id]
+		category, ok := r.store.categories[budget.CategoryID]
+		if !match(budget) || budget.DeletedAt.Valid || !ok || category.DeletedAt.Valid {
+			continue
+		}
+		result = append(result, models.BudgetCategory{
+			ID:           budget.ID,
+			UserID:       budget.UserID,
+			CategoryID:   budget.CategoryID,
+			CategoryName: category.Name,
+			Month:        budget.Month,
+			Year:         budget.Year,
+			Amount:       budget.Amount,
+			CreatedAt:    budget.CreatedAt,
+			UpdatedAt:    budget.UpdatedAt,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].CategoryName != result[j].CategoryName {
+			return result[i].CategoryName < result[j].CategoryName
+		}
+		return result[i].Month < result[j].Month
+	})
+	return result
+}
+
+func (r *memoryBudgetRepository) History(ctx context.Context, userID, categoryID uint) ([]models.Budget, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.Budget{}
+	for _, id := range sortedIDs(r.store.budgets) {
+		budget := r.store.budgets[id]
+		if budget.UserID == userID && budget.CategoryID == categoryID && !budget.DeletedAt.Valid {
+			result = append(result, budget)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Year != result[j].Year {
+			return result[i].Year < result[j].Year
+		}
+		return result[i].Month < result[j].Month
+	})
+	return result, nil
+}
+
+func (r *memoryBudgetRepository) Delete(ctx context.Context, userID, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	budget, ok := r.store.budgets[id]
+	if !ok || budget.UserID != userID || budget.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	budget.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.budgets[id] = budget
+	return nil
+}
+
+type memoryBudgetTemplateRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryBudgetTemplateRepository) List(ctx context.Context, userID uint) ([]models.BudgetTemplate, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.BudgetTemplate{}
+	for _, id := range sortedIDs(r.store.templates) {
+		if template := r.store.templates[id]; template.UserID == userID {
+			result = append(result, template)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (r *memoryBudgetTemplateRepository) FindByID(ctx context.Context, userID, id uint) (models.BudgetTemplate, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	template, ok := r.store.templates[id]
+	if !ok || template.UserID != userID {
+		return models.BudgetTemplate{}, ErrNotFound
+	}
+	return template, nil
+}
+
+func (r *memoryBudgetTemplateRepository) Save(ctx context.Context, template *models.BudgetTemplate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if template.ID == 0 {
+		template.ID = r.store.nextID()
+	} else if existing, ok := r.store.templates[template.ID]; !ok || existing.UserID != template.UserID {
+		return ErrNotFound
+	} else {
+		template.CreatedAt = existing.CreatedAt
+	}
+	touch(&template.CreatedAt, &template.UpdatedAt)
+
+	items := make([]models.BudgetTemplateItem, len(template.Items))
+	for i, item := range template.Items {
+		item.ID = r.store.nextID()
+		item.TemplateID = template.ID
+		items[i] = item
+	}
+	template.Items = items
+
+	r.store.templates[template.ID] = *template
+	return nil
+}
+
+func (r *memoryBudgetTemplateRepository) Delete(ctx context.Context, userID, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	template, ok := r.store.templates[id]
+	if !ok || template.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.store.templates, id)
+	return nil
+}
+
+type memoryTransactionRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTransactionRepository) List(ctx context.Context, filter TransactionFilter) ([]models.TransactionCategoryBudget, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	start, end := periodRange(filter)
+
+	ids := sortedIDs(r.store.transactions)
+	result := []models.TransactionCategoryBudget{}
+	for i := len(ids) - 1; i >= 0; i-- {
+		t := r.store.transactions[ids[i]]
+		if t.UserID != filter.UserID || t.DeletedAt.Valid {
+			continue
+		}
+		if t.TransactionDate.Before(start) || !t.TransactionDate.Before(end) {
+			continue
+		}
+		if filter.CategoryID != 0 && t.CategoryID != filter.CategoryID {
+			continue
+		}
+		category, ok := r.store.categories[t.CategoryID]
+		if !ok {
+			continue
+		}
+		var payeeName *string
+		if t.PayeeID != nil {
+			if payee, ok := r.store.payees[*t.PayeeID]; ok {
+				payeeName = &payee.Name
+			}
+		}
+		result = append(result, models.TransactionCategoryBudget{
+			ID:              t.ID,
+			UserID:          t.UserID,
+			CategoryID:      t.CategoryID,
+			CategoryName:    category.Name,
+			PayeeID:         t.PayeeID,
+			PayeeName:       payeeName,
+			Amount:          t.Amount,
+			Currency:        t.Currency,
+			Type:            t.Type,
+			Remarks:         t.Remarks,
+			Tags:            t.Tags,
+			TransactionDate: filter.Calendar.FormatDateTime(t.TransactionDate),
+			CreatedAt:       t.CreatedAt,
+			UpdatedAt:       t.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+func (r *memoryTransactionRepository) FindByID(ctx context.Context, userID, id uint) (models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transaction, ok := r.store.transactions[id]
+	if !ok || transaction.UserID != userID || transaction.DeletedAt.Valid {
+		return models.Transaction{}, ErrNotFound
+	}
+	return transaction, nil
+}
+
+func (r *memoryTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if transaction.Currency == "" {
+		transaction.Currency = models.DefaultCurrency
+	}
+	transaction.ID = r.store.nextID()
+	touch(&transaction.CreatedAt, &transaction.UpdatedAt)
+	r.store.transactions[transaction.ID] = *transaction
+	return nil
+}
+
+func (r *memoryTransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.transactions[transaction.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return nil
+	}
+	transaction.UpdatedAt = time.Now()
+	r.store.transactions[transaction.ID] = *transaction
+	return nil
+}
+
+func (r *memoryTransactionRepository) Delete(ctx context.Context, userID, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transaction, ok := r.store.transactions[id]
+	if !ok || transaction.UserID != userID || transaction.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	transaction.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.transactions[id] = transaction
+	return nil
+}
+
+type memoryTransactionRuleRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTransactionRuleRepository) ListActive(ctx context.Context, userID uint) ([]models.TransactionRule, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.TransactionRule{}
+	for _, id := range sortedIDs(r.store.rules) {
+		if rule := r.store.rules[id]; rule.UserID == userID && rule.Active {
+			result = append(result, rule)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+	return result, nil
+}
+
+type memoryPayeeRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryPayeeRepository) FindByID(ctx context.Context, userID, id uint) (models.Payee, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payee, ok := r.store.payees[id]
+	if !ok || payee.UserID != userID {
+		return models.Payee{}, ErrNotFound
+	}
+	return payee, nil
+}
+
+func (r *memoryPayeeRepository) List(ctx context.Context, userID uint) ([]models.Payee, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.Payee{}
+	for _, id := range sortedIDs(r.store.payees) {
+		if payee := r.store.payees[id]; payee.UserID == userID {
+			result = append(result, payee)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPayeeRepository) ListAliases(ctx context.Context, userID uint) ([]models.PayeeAlias, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := []models.PayeeAlias{}
+	for _, id := range sortedIDs(r.store.aliases) {
+		if alias := r.store.aliases[id]; alias.UserID == userID {
+			result = append(result, alias)
+		}
+	}
+	return result, nil
+}
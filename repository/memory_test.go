@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ashborn.id/moniplan/models"
+)
+
+func TestMemoryUnitOfWorkRollback(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	errFailed := errors.New("failed")
+
+	err := repos.UnitOfWork.Do(ctx, func(tx Repositories) error {
+		category := models.Category{UserID: 1, Name: "food"}
+		if err := tx.Categories.Upsert(ctx, &category); err != nil {
+			return err
+		}
+		budget := models.Budget{UserID: 1, CategoryID: category.ID, Month: 5, Year: 2026, Amount: 100000}
+		if err := tx.Budgets.Upsert(ctx, &budget); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Do error = %v, want %v", err, errFailed)
+	}
+
+	if _, err := repos.Categories.FindByName(ctx, 1, "food"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByName after rollback error = %v, want ErrNotFound", err)
+	}
+	if budgets, _ := repos.Budgets.ListForPeriod(ctx, 1, 2026, 5); len(budgets) != 0 {
+		t.Errorf("ListForPeriod after rollback = %+v, want none", budgets)
+	}
+
+	// ID yang sempat dipakai juga dikembalikan
+	category := models.Category{UserID: 1, Name: "food"}
+	if err := repos.Categories.Upsert(ctx, &category); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if category.ID != 1 {
+		t.Errorf("Upsert after rollback ID = %d, want 1", category.ID)
+	}
+}
+
+func TestMemoryUnitOfWorkCommit(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+
+	err := repos.UnitOfWork.Do(ctx, func(tx Repositories) error {
+		category := models.Category{UserID: 1, Name: "food"}
+		return tx.Categories.Upsert(ctx, &category)
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if _, err := repos.Categories.FindByName(ctx, 1, "food"); err != nil {
+		t.Errorf("FindByName after commit: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound dikembalikan jika data tidak ditemukan. Nilainya sama dengan
+// gorm.ErrRecordNotFound, sehingga pengecekan lama tetap berlaku.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// UserRepository mengakses data user
+type UserRepository interface {
+	FindByID(ctx context.Context, id uint) (models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	UpdatePreferences(ctx context.Context, user *models.User) error
+}
+
+// CategoryRepository mengakses category milik user. Category di trash
+// dianggap tidak ada.
+type CategoryRepository interface {
+	FindByID(ctx context.Context, userID, id uint) (models.Category, error)
+	FindByName(ctx context.Context, userID uint, name string) (models.Category, error)
+	// Upsert menyimpan category berdasarkan unique key (user_id, name). Jika
+	// sudah ada, termasuk yang di trash, baris lama dipakai dan dikeluarkan
+	// dari trash. category diisi dengan baris yang tersimpan.
+	Upsert(ctx context.Context, category *models.Category) error
+	// ListWithLatestBudget mengembalikan category user beserta budget
+	// terakhirnya, diurutkan berdasarkan nama
+	ListWithLatestBudget(ctx context.Context, userID uint) ([]models.CategoryAndBudget, error)
+}
+
+// BudgetRepository mengakses budget per category
+type BudgetRepository interface {
+	// FindForPeriod mengambil budget terakhir category pada bulan tertentu
+	FindForPeriod(ctx context.Context, userID, categoryID, year, month uint) (models.Budget, error)
+	// FindLatest mengambil budget terakhir category di periode manapun
+	FindLatest(ctx context.Context, userID, categoryID uint) (models.Budget, error)
+	// Upsert menyimpan budget berdasarkan unique key (user_id, category_id,
+	// year, month). Jika sudah ada, nominalnya diganti dan budget dikeluarkan
+	// dari trash. budget diisi dengan baris yang tersimpan.
+	Upsert(ctx context.Context, budget *models.Budget) error
+	FindByID(ctx context.Context, userID, id uint) (models.Budget, error)
+	// ListForPeriod mengembalikan budget user pada satu bulan beserta nama
+	// category-nya, diurutkan berdasarkan nama category
+	ListForPeriod(ctx context.Context, userID, year, month uint) ([]models.BudgetCategory, error)
+	// ListForYear mengembalikan budget user selama satu tahun beserta nama
+	// category-nya, diurutkan berdasarkan nama category lalu bulan
+	ListForYear(ctx context.Context, userID, year uint) ([]models.BudgetCategory, error)
+	// History mengembalikan semua budget category, dari bulan terlama
+	History(ctx context.Context, userID, categoryID uint) ([]models.Budget, error)
+	// Delete memindahkan budget ke trash, ErrNotFound jika tidak ada
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+// BudgetTemplateRepository mengakses template budget milik user. Template
+// selalu dimuat beserta item-nya.
+type BudgetTemplateRepository interface {
+	// List mengembalikan template user, diurutkan berdasarkan nama
+	List(ctx context.Context, userID uint) ([]models.BudgetTemplate, error)
+	FindByID(ctx context.Context, userID, id uint) (models.BudgetTemplate, error)
+	// Save membuat template baru jika ID 0, atau mengganti nama dan semua
+	// item template yang sudah ada
+	Save(ctx context.Context, template *models.BudgetTemplate) error
+	// Delete menghapus template beserta item-nya, ErrNotFound jika tidak ada
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+// TransactionFilter adalah filter untuk TransactionRepository.List.
+// Year/Month adalah periode budget, rentang tanggal dan format
+// transaction_date mengikuti Calendar user. CategoryID 0 berarti semua
+// category.
+type TransactionFilter struct {
+	UserID     uint
+	Year       int
+	Month      int
+	Calendar   models.UserCalendar
+	CategoryID uint
+}
+
+// TransactionRepository mengakses transaction milik user
+type TransactionRepository interface {
+	// List mengembalikan transaction pada periode filter beserta nama
+	// category dan payee, diurutkan dari yang terbaru
+	List(ctx context.Context, filter TransactionFilter) ([]models.TransactionCategoryBudget, error)
+	FindByID(ctx context.Context, userID, id uint) (models.Transaction, error)
+	Create(ctx context.Context, transaction *models.Transaction) error
+	Update(ctx context.Context, transaction *models.Transaction) error
+	// Delete memindahkan transaction ke trash, ErrNotFound jika tidak ada
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+// TransactionRuleRepository mengakses auto-categorization rule milik user
+type TransactionRuleRepository interface {
+	// ListActive mengembalikan rule aktif sesuai urutan evaluasi
+	ListActive(ctx context.Context, userID uint) ([]models.TransactionRule, error)
+}
+
+// PayeeRepository mengakses payee dan alias milik user
+type PayeeRepository interface {
+	FindByID(ctx context.Context, userID, id uint) (models.Payee, error)
+	List(ctx context.Context, userID uint) ([]models.Payee, error)
+	ListAliases(ctx context.Context, userID uint) ([]models.PayeeAlias, error)
+}
+
+// UnitOfWork menjalankan beberapa operasi repository sebagai satu kesatuan:
+// semua perubahan di fn disimpan jika fn berhasil, atau dibatalkan semua
+// jika fn mengembalikan error. fn bisa dijalankan ulang (misalnya saat
+// deadlock), sehingga tidak boleh punya efek samping di luar repos.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// Repositories mengumpulkan semua repository yang dipakai services
+type Repositories struct {
+	Users        UserRepository
+	Categories   CategoryRepository
+	Budgets      BudgetRepository
+	Templates    BudgetTemplateRepository
+	Transactions TransactionRepository
+	Rules        TransactionRuleRepository
+	Payees       PayeeRepository
+	UnitOfWork   UnitOfWork
+}
+
+// periodRange mengembalikan awal periode budget filter dan awal periode
+// berikutnya dalam UTC. Filter memakai rentang tanggal, bukan
+// YEAR()/MONTH(), agar query sama di semua database, bisa memakai index dan
+// mengikuti tanggal awal periode serta zona waktu user.
+func periodRange(filter TransactionFilter) (time.Time, time.Time) {
+	period := filter.Calendar.PeriodRange(uint(filter.Year), uint(filter.Month))
+	return period.Start.UTC(), period.End.UTC()
+}
@@ -21,6 +21,9 @@ func SetupRoutes(router *gin.Engine) {
 			auth.POST("/login", controllers.Login)
 		}
 
+		// Download attachment dengan signed URL (expires & signature)
+		v1.GET("/files/:id", controllers.ServeSignedAttachment)
+
 		// Protected routes (perlu authentication)
 		protected := v1.Group("/")
 		protected.Use(middlewares.AuthMiddleware()) // Apply auth middleware
@@ -28,6 +31,9 @@ func SetupRoutes(router *gin.Engine) {
 			// User routes
 			protected.GET("/profile", controllers.GetProfile)
 			protected.POST("/auth/refresh", controllers.RefreshToken)
+			protected.POST("/profile/currency", controllers.UpdateBaseCurrency)
+			protected.POST("/profile/period", controllers.UpdatePeriodStartDay)
+			protected.POST("/profile/timezone", controllers.UpdateTimeZone)
 
 			// Category routes
 			protected.GET("/category", controllers.IndexCategory)
@@ -35,13 +41,129 @@ func SetupRoutes(router *gin.Engine) {
 			protected.GET("/category/:id", controllers.GetCategoryByID)
 			protected.POST("/category/update/:id", controllers.UpdateCategory)
 			protected.GET("/category/delete/:id", controllers.DeleteCategoryByID)
+			protected.POST("/category/restore/:id", controllers.RestoreCategory)
+
+			// Budget routes
+			protected.GET("/budget", controllers.IndexBudget)
+			protected.POST("/budget/set", controllers.SetBudget)
+			protected.POST("/budget/copy", controllers.CopyBudget)
+			protected.POST("/budget/year", controllers.UpdateBudgetYear)
+			protected.GET("/budget/history/:id", controllers.GetBudgetHistory)
+			protected.GET("/budget/delete/:id", controllers.DeleteBudgetByID)
+			protected.GET("/budget/plan", controllers.GetBudgetPlan)
+			protected.GET("/budget/template", controllers.IndexBudgetTemplate)
+			protected.POST("/budget/template/create", controllers.CreateBudgetTemplate)
+			protected.GET("/budget/template/:id", controllers.GetBudgetTemplateByID)
+			protected.POST("/budget/template/update/:id", controllers.UpdateBudgetTemplate)
+			protected.GET("/budget/template/delete/:id", controllers.DeleteBudgetTemplateByID)
+			protected.POST("/budget/template/apply/:id", controllers.ApplyBudgetTemplate)
 
 			// Transaction routes
 			protected.GET("/transaction", controllers.IndexTransaction)
 			protected.POST("/transaction/create", controllers.CreateTransaction)
+			protected.GET("/transaction/suggest", controllers.SuggestTransactionCategory)
 			protected.GET("/transaction/:id", controllers.GetTransactionByID)
 			protected.POST("/transaction/update/:id", controllers.UpdateTransaction)
 			protected.GET("/transaction/delete/:id", controllers.DeleteTransactionByID)
+			protected.POST("/transaction/restore/:id", controllers.RestoreTransaction)
+
+			// Trash (transaction dan category yang dihapus)
+			protected.GET("/trash", controllers.IndexTrash)
+
+			// Attachment routes
+			protected.GET("/attachment/transaction/:id", controllers.IndexTransactionAttachment)
+			protected.POST("/attachment/upload/:id", controllers.UploadAttachment)
+			protected.GET("/attachment/:id", controllers.GetAttachmentByID)
+			protected.GET("/attachment/download/:id", controllers.DownloadAttachment)
+			protected.GET("/attachment/delete/:id", controllers.DeleteAttachmentByID)
+
+			// Goal routes
+			protected.GET("/goal", controllers.IndexGoal)
+			protected.POST("/goal/create", controllers.CreateGoal)
+			protected.GET("/goal/:id", controllers.GetGoalByID)
+			protected.POST("/goal/update/:id", controllers.UpdateGoal)
+			protected.GET("/goal/delete/:id", controllers.DeleteGoalByID)
+			protected.POST("/goal/contribute/:id", controllers.ContributeGoal)
+			protected.GET("/goal/progress/:id", controllers.GetGoalProgress)
+
+			// Loan routes
+			protected.GET("/loan", controllers.IndexLoan)
+			protected.POST("/loan/create", controllers.CreateLoan)
+			protected.GET("/loan/:id", controllers.GetLoanByID)
+			protected.POST("/loan/update/:id", controllers.UpdateLoan)
+			protected.GET("/loan/delete/:id", controllers.DeleteLoanByID)
+			protected.GET("/loan/schedule/:id", controllers.GetLoanSchedule)
+			protected.POST("/loan/pay/:id", controllers.PayLoan)
+			protected.GET("/loan/summary/:id", controllers.GetLoanSummary)
+
+			// Bill routes
+			protected.GET("/bill", controllers.IndexBill)
+			protected.POST("/bill/create", controllers.CreateBill)
+			protected.GET("/bill/calendar", controllers.GetBillCalendar)
+			protected.GET("/bill/:id", controllers.GetBillByID)
+			protected.POST("/bill/update/:id", controllers.UpdateBill)
+			protected.GET("/bill/delete/:id", controllers.DeleteBillByID)
+			protected.POST("/bill/pay/:id", controllers.PayBill)
+
+			// Budget alert routes
+			protected.GET("/alert", controllers.IndexAlertRule)
+			protected.POST("/alert/create", controllers.CreateAlertRule)
+			protected.POST("/alert/update/:id", controllers.UpdateAlertRule)
+			protected.GET("/alert/delete/:id", controllers.DeleteAlertRuleByID)
+
+			// Notification inbox routes
+			protected.GET("/notification", controllers.IndexNotification)
+			protected.POST("/notification/read-all", controllers.ReadAllNotification)
+			protected.POST("/notification/read/:id", controllers.ReadNotification)
+
+			// Webhook routes
+			protected.GET("/webhook", controllers.IndexWebhook)
+			protected.POST("/webhook/create", controllers.CreateWebhook)
+			protected.POST("/webhook/update/:id", controllers.UpdateWebhook)
+			protected.GET("/webhook/delete/:id", controllers.DeleteWebhookByID)
+			protected.GET("/webhook/deliveries/:id", controllers.IndexWebhookDelivery)
+			protected.POST("/webhook/redeliver/:id", controllers.RedeliverWebhook)
+
+			// Auto-categorization rules
+			protected.GET("/rule", controllers.IndexTransactionRule)
+			protected.POST("/rule/create", controllers.CreateTransactionRule)
+			protected.POST("/rule/dry-run", controllers.DryRunTransactionRule)
+			protected.POST("/rule/apply", controllers.ApplyTransactionRule)
+			protected.GET("/rule/:id", controllers.GetTransactionRuleByID)
+			protected.POST("/rule/update/:id", controllers.UpdateTransactionRule)
+			protected.GET("/rule/delete/:id", controllers.DeleteTransactionRuleByID)
+
+			// Payee routes
+			protected.GET("/payee", controllers.IndexPayee)
+			protected.POST("/payee/create", controllers.CreatePayee)
+			protected.GET("/payee/report", controllers.GetPayeeReport)
+			protected.POST("/payee/normalize", controllers.NormalizePayee)
+			protected.GET("/payee/:id", controllers.GetPayeeByID)
+			protected.POST("/payee/update/:id", controllers.UpdatePayee)
+			protected.GET("/payee/delete/:id", controllers.DeletePayeeByID)
+			protected.POST("/payee/alias/:id", controllers.CreatePayeeAlias)
+			protected.GET("/payee/alias/delete/:id", controllers.DeletePayeeAliasByID)
+			protected.POST("/payee/merge/:id", controllers.MergePayee)
+			protected.POST("/payee/split/:id", controllers.SplitPayee)
+
+			// Report routes (data chart, dihitung per periode budget user)
+			protected.GET("/report/cashflow", controllers.GetCashFlowReport)
+			protected.GET("/report/trend", controllers.GetCategoryTrendReport)
+			protected.GET("/report/heatmap", controllers.GetSpendingHeatmapReport)
+			protected.GET("/report/top", controllers.GetTopReport)
+
+			// Exchange rate routes
+			protected.GET("/currency/rate", controllers.IndexExchangeRate)
+			protected.POST("/currency/rate/create", controllers.CreateExchangeRate)
+			protected.POST("/currency/rate/import", controllers.ImportExchangeRate)
+			protected.GET("/currency/rate/delete/:id", controllers.DeleteExchangeRateByID)
+
+			// Audit log routes
+			protected.GET("/audit", controllers.IndexAuditLog)
+			protected.GET("/audit/:entity/:id", controllers.GetEntityAuditLog)
+
+			// Realtime event stream (SSE)
+			protected.GET("/stream", controllers.StreamEvents)
 		}
 	}
 
@@ -62,6 +184,9 @@ func SetupMiddlewares(router *gin.Engine) {
 	// Logger middleware untuk log setiap request
 	router.Use(gin.Logger())
 
+	// Audit middleware untuk menyimpan IP dan user agent di context request
+	router.Use(middlewares.AuditContext())
+
 	// CORS middleware untuk allow cross-origin requests
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -0,0 +1,88 @@
+// Package safehttp memvalidasi URL tujuan yang diisi user dan menyediakan
+// http.Client yang hanya boleh terhubung ke alamat publik, untuk webhook dan
+// notifikasi alert agar tidak bisa dipakai untuk SSRF.
+package safehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInvalidURL dikembalikan ValidateURL untuk URL yang bukan http/https
+	ErrInvalidURL = errors.New("url must be an absolute http or https URL")
+
+	// ErrPrivateAddress dikembalikan untuk tujuan loopback, jaringan privat,
+	// link-local dan sejenisnya agar webhook tidak bisa dipakai untuk SSRF
+	ErrPrivateAddress = errors.New("url must not point to a loopback or private network address")
+)
+
+// allowPrivateHosts diisi dari env WEBHOOK_ALLOW_PRIVATE_HOSTS (default
+// false), untuk instalasi self-hosted yang memang mengirim webhook atau alert
+// ke jaringan lokal
+var allowPrivateHosts = func() bool {
+	allow, _ := strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE_HOSTS"))
+	return allow
+}()
+
+// ValidateURL mengecek URL tujuan: hanya http/https, tanpa user info, dan
+// host-nya bukan localhost atau IP privat. Nama host lain dicek lagi saat
+// koneksi dibuat (lihat dialControl).
+func ValidateURL(raw string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User != nil {
+		return ErrInvalidURL
+	}
+	if allowPrivateHosts {
+		return nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// isPrivateIP mengecek alamat yang tidak boleh dituju
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// dialControl menolak koneksi ke IP privat setelah DNS di-resolve, termasuk
+// saat redirect, sehingga nama host yang mengarah ke jaringan internal tetap
+// diblokir
+func dialControl(network, address string, _ syscall.RawConn) error {
+	if allowPrivateHosts {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// NewClient membuat http.Client yang menolak koneksi ke IP privat
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+)
+
+// BudgetInput adalah nominal budget category untuk satu bulan
+type BudgetInput struct {
+	CategoryID uint
+	Month      uint
+	Year       uint
+	Amount     money.Amount
+}
+
+// BudgetYearItem adalah nominal budget category untuk beberapa bulan dalam
+// satu tahun. Months kosong berarti semua bulan.
+type BudgetYearItem struct {
+	CategoryID uint
+	Amount     money.Amount
+	Months     []uint
+}
+
+// BudgetSaveResult adalah hasil menyimpan budget. Created bernilai true jika
+// bulan tersebut sebelumnya belum punya budget.
+type BudgetSaveResult struct {
+	Budget  models.Budget
+	Created bool
+}
+
+// BudgetService menangani budget bulanan per category
+type BudgetService struct {
+	budgets    repository.BudgetRepository
+	categories repository.CategoryRepository
+	uow        repository.UnitOfWork
+}
+
+// List mengembalikan budget user pada satu bulan
+func (s *BudgetService) List(ctx context.Context, userID, year, month uint) ([]models.BudgetCategory, error) {
+	if err := validatePeriod(year, month); err != nil {
+		return nil, err
+	}
+	return s.budgets.ListForPeriod(ctx, userID, year, month)
+}
+
+// History mengembalikan riwayat budget category dari bulan terlama
+func (s *BudgetService) History(ctx context.Context, userID, categoryID uint) ([]models.Budget, error) {
+	if _, err := s.categories.FindByID(ctx, userID, categoryID); err != nil {
+		return nil, err
+	}
+	return s.budgets.History(ctx, userID, categoryID)
+}
+
+// Set menyimpan nominal budget category pada satu bulan
+func (s *BudgetService) Set(ctx context.Context, userID uint, input BudgetInput) (BudgetSaveResult, error) {
+	if err := validatePeriod(input.Year, input.Month); err != nil {
+		return BudgetSaveResult{}, err
+	}
+	if err := s.checkCategory(ctx, userID, input.CategoryID); err != nil {
+		return BudgetSaveResult{}, err
+	}
+
+	var result BudgetSaveResult
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		result, err = setBudget(ctx, repos.Budgets, userID, input)
+		return err
+	})
+	return result, err
+}
+
+// CopyPreviousMonth menyalin budget bulan sebelumnya ke bulan year/month.
+// Budget yang sudah ada di bulan tujuan hanya diganti jika overwrite.
+func (s *BudgetService) CopyPreviousMonth(ctx context.Context, userID, year, month uint, overwrite bool) ([]BudgetSaveResult, error) {
+	if err := validatePeriod(year, month); err != nil {
+		return nil, err
+	}
+
+	previous := time.Date(int(year), time.Month(month), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+
+	var results []BudgetSaveResult
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		results = nil
+
+		sources, err := repos.Budgets.ListForPeriod(ctx, userID, uint(previous.Year()), uint(previous.Month()))
+		if err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			if !overwrite {
+				_, err := repos.Budgets.FindForPeriod(ctx, userID, source.CategoryID, year, month)
+				if err == nil {
+					continue
+				}
+				if !errors.Is(err, repository.ErrNotFound) {
+					return err
+				}
+			}
+
+			result, err := setBudget(ctx, repos.Budgets, userID, BudgetInput{
+				CategoryID: source.CategoryID,
+				Month:      month,
+				Year:       year,
+				Amount:     source.Amount,
+			})
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// SetYear menyimpan nominal budget beberapa category untuk bulan-bulan dalam
+// satu tahun. Semua item divalidasi dulu lalu disimpan sekaligus.
+func (s *BudgetService) SetYear(ctx context.Context, userID, year uint, items []BudgetYearItem) ([]BudgetSaveResult, error) {
+	var inputs []BudgetInput
+	for _, item := range items {
+		if item.Amount <= 0 {
+			return nil, &ValidationError{Message: "amount must be greater than zero"}
+		}
+		if err := s.checkCategory(ctx, userID, item.CategoryID); err != nil {
+			return nil, err
+		}
+
+		months := item.Months
+		if len(months) == 0 {
+			months = []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		}
+		for _, month := range months {
+			if err := validatePeriod(year, month); err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, BudgetInput{CategoryID: item.CategoryID, Month: month, Year: year, Amount: item.Amount})
+		}
+	}
+
+	var results []BudgetSaveResult
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		results = make([]BudgetSaveResult, 0, len(inputs))
+		for _, input := range inputs {
+			result, err := setBudget(ctx, repos.Budgets, userID, input)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// Delete memindahkan budget milik user ke trash
+func (s *BudgetService) Delete(ctx context.Context, userID, id uint) (models.Budget, error) {
+	budget, err := s.budgets.FindByID(ctx, userID, id)
+	if err != nil {
+		return models.Budget{}, err
+	}
+	return budget, s.budgets.Delete(ctx, userID, id)
+}
+
+// checkCategory memastikan category ada dan milik user
+func (s *BudgetService) checkCategory(ctx context.Context, userID, categoryID uint) error {
+	_, err := s.categories.FindByID(ctx, userID, categoryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &ValidationError{Message: "category not found"}
+	}
+	return err
+}
+
+func setBudget(ctx context.Context, budgets repository.BudgetRepository, userID uint, input BudgetInput) (BudgetSaveResult, error) {
+	_, err := budgets.FindForPeriod(ctx, userID, input.CategoryID, input.Year, input.Month)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return BudgetSaveResult{}, err
+	}
+	created := err != nil
+
+	now := time.Now()
+	budget := models.Budget{
+		UserID:     userID,
+		CategoryID: input.CategoryID,
+		Month:      input.Month,
+		Year:       input.Year,
+		Amount:     input.Amount,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := budgets.Upsert(ctx, &budget); err != nil {
+		return BudgetSaveResult{}, err
+	}
+	return BudgetSaveResult{Budget: budget, Created: created}, nil
+}
+
+func validatePeriod(year, month uint) error {
+	if month < 1 || month > 12 {
+		return &ValidationError{Message: "month must be between 1 and 12"}
+	}
+	if year < 1 || year > 9999 {
+		return &ValidationError{Message: "year is not valid"}
+	}
+	return nil
+}
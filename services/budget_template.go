@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+)
+
+// MaxTemplateApplyMonths adalah rentang bulan terpanjang untuk Apply
+const MaxTemplateApplyMonths = 36
+
+// BudgetTemplateItemInput adalah nominal dasar category di template beserta
+// override musimannya
+type BudgetTemplateItemInput struct {
+	CategoryID uint
+	Amount     money.Amount
+	Overrides  []models.BudgetOverride
+}
+
+// BudgetTemplateInput adalah data untuk membuat atau mengganti template
+type BudgetTemplateInput struct {
+	Name  string
+	Items []BudgetTemplateItemInput
+}
+
+// BudgetTemplateService menangani template budget dan rencana tahunan
+type BudgetTemplateService struct {
+	templates  repository.BudgetTemplateRepository
+	budgets    repository.BudgetRepository
+	categories repository.CategoryRepository
+	uow        repository.UnitOfWork
+}
+
+// List mengembalikan template milik user
+func (s *BudgetTemplateService) List(ctx context.Context, userID uint) ([]models.BudgetTemplate, error) {
+	return s.templates.List(ctx, userID)
+}
+
+// Get mengambil template milik user
+func (s *BudgetTemplateService) Get(ctx context.Context, userID, id uint) (models.BudgetTemplate, error) {
+	return s.templates.FindByID(ctx, userID, id)
+}
+
+// Create membuat template baru
+func (s *BudgetTemplateService) Create(ctx context.Context, userID uint, input BudgetTemplateInput) (models.BudgetTemplate, error) {
+	template := models.BudgetTemplate{UserID: userID}
+	if err := s.save(ctx, &template, input); err != nil {
+		return models.BudgetTemplate{}, err
+	}
+	return template, nil
+}
+
+// Update mengganti nama dan semua item template
+func (s *BudgetTemplateService) Update(ctx context.Context, userID, id uint, input BudgetTemplateInput) (models.BudgetTemplate, error) {
+	template, err := s.templates.FindByID(ctx, userID, id)
+	if err != nil {
+		return models.BudgetTemplate{}, err
+	}
+	if err := s.save(ctx, &template, input); err != nil {
+		return models.BudgetTemplate{}, err
+	}
+	return template, nil
+}
+
+// Delete menghapus template milik user
+func (s *BudgetTemplateService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.templates.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.templates.Delete(ctx, userID, id)
+}
+
+// Apply mengisi budget bulan start sampai end (termasuk) dari template.
+// Budget yang sudah ada hanya diganti jika overwrite.
+func (s *BudgetTemplateService) Apply(ctx context.Context, userID, id uint, start, end time.Time, overwrite bool) ([]BudgetSaveResult, error) {
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return nil, &ValidationError{Message: "end must not be before start"}
+	}
+
+	var months []time.Time
+	for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	if len(months) > MaxTemplateApplyMonths {
+		return nil, &ValidationError{Message: "template can be applied to at most 36 months at once"}
+	}
+
+	template, err := s.templates.FindByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, categoryID := range template.CategoryIDs() {
+		if err := s.checkCategory(ctx, userID, categoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []BudgetSaveResult
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		results = nil
+		for _, month := range months {
+			year, monthOfYear := uint(month.Year()), uint(month.Month())
+			for _, categoryID := range template.CategoryIDs() {
+				amount, _, ok := template.AmountFor(categoryID, monthOfYear)
+				if !ok || amount <= 0 {
+					continue
+				}
+
+				if !overwrite {
+					_, err := repos.Budgets.FindForPeriod(ctx, userID, categoryID, year, monthOfYear)
+					if err == nil {
+						continue
+					}
+					if !errors.Is(err, repository.ErrNotFound) {
+						return err
+					}
+				}
+
+				result, err := setBudget(ctx, repos.Budgets, userID, BudgetInput{
+					CategoryID: categoryID,
+					Month:      monthOfYear,
+					Year:       year,
+					Amount:     amount,
+				})
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+// Plan menyusun rencana budget satu tahun per category dan bulan. Jika
+// templateID diisi, rencana dihitung dari template tanpa menyimpan apa pun;
+// jika tidak, dari budget yang sudah tersimpan.
+func (s *BudgetTemplateService) Plan(ctx context.Context, userID, year, templateID uint) (models.AnnualPlan, error) {
+	if err := validatePeriod(year, 1); err != nil {
+		return models.AnnualPlan{}, err
+	}
+
+	plan := models.AnnualPlan{Year: year, Categories: []models.AnnualPlanCategory{}}
+	if templateID == 0 {
+		rows, err := s.budgets.ListForYear(ctx, userID, year)
+		if err != nil {
+			return models.AnnualPlan{}, err
+		}
+
+		index := map[uint]int{}
+		for _, row := range rows {
+			i, ok := index[row.CategoryID]
+			if !ok {
+				i = len(plan.Categories)
+				index[row.CategoryID] = i
+				plan.Categories = append(plan.Categories, models.AnnualPlanCategory{
+					CategoryID:   row.CategoryID,
+					CategoryName: row.CategoryName,
+				})
+			}
+			plan.Categories[i].Months[row.Month-1] = row.Amount
+		}
+		for i := range plan.Categories {
+			plan.Categories[i].SeasonalMonths = seasonalMonths(plan.Categories[i].Months)
+		}
+	} else {
+		template, err := s.templates.FindByID(ctx, userID, templateID)
+		if err != nil {
+			return models.AnnualPlan{}, err
+		}
+		plan.TemplateID = &template.ID
+
+		for _, categoryID := range template.CategoryIDs() {
+			category, err := s.categories.FindByID(ctx, userID, categoryID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return models.AnnualPlan{}, err
+			}
+
+			row := models.AnnualPlanCategory{
+				CategoryID:     category.ID,
+				CategoryName:   category.Name,
+				SeasonalMonths: []uint{},
+			}
+			for month := uint(1); month <= 12; month++ {
+				amount, seasonal, _ := template.AmountFor(categoryID, month)
+				row.Months[month-1] = amount
+				if seasonal {
+					row.SeasonalMonths = append(row.SeasonalMonths, month)
+				}
+			}
+			plan.Categories = append(plan.Categories, row)
+		}
+		sort.SliceStable(plan.Categories, func(i, j int) bool {
+			return plan.Categories[i].CategoryName < plan.Categories[j].CategoryName
+		})
+	}
+
+	for i := range plan.Categories {
+		category := &plan.Categories[i]
+		for month, amount := range category.Months {
+			category.Total += amount
+			plan.MonthTotals[month] += amount
+		}
+		plan.Total += category.Total
+	}
+	return plan, nil
+}
+
+// save memvalidasi input lalu menyimpan template beserta item-nya
+func (s *BudgetTemplateService) save(ctx context.Context, template *models.BudgetTemplate, input BudgetTemplateInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return &ValidationError{Message: "name is required"}
+	}
+
+	existing, err := s.templates.List(ctx, template.UserID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != template.ID && strings.EqualFold(other.Name, name) {
+			return &ValidationError{Message: "template name already exists"}
+		}
+	}
+
+	items, err := s.buildItems(ctx, template.UserID, input.Items)
+	if err != nil {
+		return err
+	}
+
+	template.Name = name
+	template.Items = items
+	template.UpdatedAt = time.Now()
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		return repos.Templates.Save(ctx, template)
+	})
+}
+
+func (s *BudgetTemplateService) buildItems(ctx context.Context, userID uint, inputs []BudgetTemplateItemInput) ([]models.BudgetTemplateItem, error) {
+	if len(inputs) == 0 {
+		return nil, &ValidationError{Message: "template must have at least one item"}
+	}
+
+	seen := map[uint]bool{}
+	var items []models.BudgetTemplateItem
+	for _, input := range inputs {
+		if seen[input.CategoryID] {
+			return nil, &ValidationError{Message: "each category can only appear once in a template"}
+		}
+		seen[input.CategoryID] = true
+
+		if err := s.checkCategory(ctx, userID, input.CategoryID); err != nil {
+			return nil, err
+		}
+		if input.Amount <= 0 {
+			return nil, &ValidationError{Message: "amount must be greater than zero"}
+		}
+		items = append(items, models.BudgetTemplateItem{CategoryID: input.CategoryID, Amount: input.Amount})
+
+		months := map[uint]bool{}
+		for _, override := range input.Overrides {
+			if override.Month < 1 || override.Month > 12 {
+				return nil, &ValidationError{Message: "override month must be between 1 and 12"}
+			}
+			if months[override.Month] {
+				return nil, &ValidationError{Message: "each override month can only appear once per category"}
+			}
+			if override.Amount <= 0 {
+				return nil, &ValidationError{Message: "override amount must be greater than zero"}
+			}
+			months[override.Month] = true
+			items = append(items, models.BudgetTemplateItem{
+				CategoryID: input.CategoryID,
+				Month:      override.Month,
+				Amount:     override.Amount,
+			})
+		}
+	}
+	return items, nil
+}
+
+// checkCategory memastikan category ada dan milik user
+func (s *BudgetTemplateService) checkCategory(ctx context.Context, userID, categoryID uint) error {
+	_, err := s.categories.FindByID(ctx, userID, categoryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &ValidationError{Message: "category not found"}
+	}
+	return err
+}
+
+// seasonalMonths mengembalikan bulan yang nominalnya berbeda dari nominal
+// yang paling sering dipakai category tersebut. Bulan tanpa budget dilewati.
+func seasonalMonths(months [12]money.Amount) []uint {
+	counts := map[money.Amount]int{}
+	for _, amount := range months {
+		if amount > 0 {
+			counts[amount]++
+		}
+	}
+
+	var usual money.Amount
+	for amount, count := range counts {
+		if count > counts[usual] || (count == counts[usual] && amount < usual) {
+			usual = amount
+		}
+	}
+
+	result := []uint{}
+	for i, amount := range months {
+		if amount > 0 && amount != usual {
+			result = append(result, uint(i+1))
+		}
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+)
+
+// ErrNoBudget dikembalikan Get jika category belum punya budget sama sekali
+var ErrNoBudget = errors.New("category has no budget")
+
+// CategoryInput adalah data category beserta budget untuk satu bulan
+type CategoryInput struct {
+	Name   string
+	Month  uint
+	Year   uint
+	Amount money.Amount
+}
+
+// CategorySaveResult adalah hasil Create dan Update. Created bernilai true
+// jika category baru dibuat, bukan memakai category yang sudah ada.
+type CategorySaveResult struct {
+	Category models.Category
+	Budget   models.Budget
+	Created  bool
+}
+
+// CategoryService menangani category dan budget-nya
+type CategoryService struct {
+	users      repository.UserRepository
+	categories repository.CategoryRepository
+	budgets    repository.BudgetRepository
+	uow        repository.UnitOfWork
+}
+
+// List mengembalikan category user beserta budget terakhirnya
+func (s *CategoryService) List(ctx context.Context, userID uint) ([]models.CategoryAndBudget, error) {
+	return s.categories.ListWithLatestBudget(ctx, userID)
+}
+
+// Get mengambil category beserta budget periode yang memuat now (mengikuti
+// tanggal awal periode dan zona waktu user), atau budget terakhir jika periode tersebut
+// belum punya budget
+func (s *CategoryService) Get(ctx context.Context, userID, categoryID uint, now time.Time) (models.Category, models.Budget, error) {
+	category, err := s.categories.FindByID(ctx, userID, categoryID)
+	if err != nil {
+		return models.Category{}, models.Budget{}, err
+	}
+
+	calendar, err := userCalendar(ctx, s.users, userID)
+	if err != nil {
+		return category, models.Budget{}, err
+	}
+
+	period := calendar.PeriodOf(now)
+	budget, err := s.budgets.FindForPeriod(ctx, userID, categoryID, period.Year, period.Month)
+	if errors.Is(err, repository.ErrNotFound) {
+		budget, err = s.budgets.FindLatest(ctx, userID, categoryID)
+		if errors.Is(err, repository.ErrNotFound) {
+			err = ErrNoBudget
+		}
+	}
+	if err != nil {
+		return category, models.Budget{}, err
+	}
+	return category, budget, nil
+}
+
+// Create menyimpan budget bulan input untuk category dengan nama input.
+// Category dibuat (atau dikeluarkan dari trash) jika belum ada, dan budget
+// yang sudah ada untuk bulan tersebut diganti nominalnya. Category dan
+// budget disimpan dalam satu unit of work.
+func (s *CategoryService) Create(ctx context.Context, userID uint, input CategoryInput) (CategorySaveResult, error) {
+	var result CategorySaveResult
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		category, created, err := upsertCategory(ctx, repos.Categories, userID, input.Name)
+		if err != nil {
+			return err
+		}
+
+		result, err = saveBudget(ctx, repos.Budgets, category, created, input)
+		return err
+	})
+	if err != nil {
+		return CategorySaveResult{}, err
+	}
+	return result, nil
+}
+
+// Update menyimpan budget bulan input untuk category categoryID. Jika
+// category tidak ditemukan, category dengan nama input yang dipakai.
+func (s *CategoryService) Update(ctx context.Context, userID, categoryID uint, input CategoryInput) (CategorySaveResult, error) {
+	var result CategorySaveResult
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		category, err := repos.Categories.FindByID(ctx, userID, categoryID)
+		created := false
+		if errors.Is(err, repository.ErrNotFound) {
+			category, created, err = upsertCategory(ctx, repos.Categories, userID, input.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err = saveBudget(ctx, repos.Budgets, category, created, input)
+		return err
+	})
+	if err != nil {
+		return CategorySaveResult{}, err
+	}
+	return result, nil
+}
+
+// upsertCategory menyimpan category berdasarkan nama. created bernilai true
+// jika sebelumnya user tidak punya category aktif dengan nama tersebut.
+func upsertCategory(ctx context.Context, categories repository.CategoryRepository, userID uint, name string) (models.Category, bool, error) {
+	name = strings.ToLower(name)
+
+	_, err := categories.FindByName(ctx, userID, name)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return models.Category{}, false, err
+	}
+	created := err != nil
+
+	now := time.Now()
+	category := models.Category{
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := categories.Upsert(ctx, &category); err != nil {
+		return models.Category{}, false, err
+	}
+	return category, created, nil
+}
+
+func saveBudget(ctx context.Context, budgets repository.BudgetRepository, category models.Category, created bool, input CategoryInput) (CategorySaveResult, error) {
+	result, err := setBudget(ctx, budgets, category.UserID, BudgetInput{
+		CategoryID: category.ID,
+		Month:      input.Month,
+		Year:       input.Year,
+		Amount:     input.Amount,
+	})
+	if err != nil {
+		return CategorySaveResult{}, err
+	}
+
+	return CategorySaveResult{Category: category, Budget: result.Budget, Created: created}, nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCategoryCreate(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestServices(t, nil)
+
+	first, err := s.Categories.Create(ctx, user.ID, CategoryInput{Name: "Food", Month: 5, Year: 2026, Amount: 100000})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !first.Created || first.Category.Name != "food" {
+		t.Errorf("Create = %+v, want a new category named food", first.Category)
+	}
+	if first.Budget.CategoryID != first.Category.ID || first.Budget.Amount != 100000 {
+		t.Errorf("Create budget = %+v, want 100000 for category %d", first.Budget, first.Category.ID)
+	}
+
+	// Nama yang sama memakai category dan budget yang sudah ada
+	second, err := s.Categories.Create(ctx, user.ID, CategoryInput{Name: "FOOD", Month: 5, Year: 2026, Amount: 150000})
+	if err != nil {
+		t.Fatalf("Create existing: %v", err)
+	}
+	if second.Created || second.Category.ID != first.Category.ID {
+		t.Errorf("Create existing = %+v (created %v), want category %d reused", second.Category, second.Created, first.Category.ID)
+	}
+	if second.Budget.ID != first.Budget.ID || second.Budget.Amount != 150000 {
+		t.Errorf("Create existing budget = %+v, want budget %d updated to 150000", second.Budget, first.Budget.ID)
+	}
+
+	categories, err := s.Categories.List(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(categories) != 1 || categories[0].Amount != 150000 {
+		t.Errorf("List = %+v, want one category with budget 150000", categories)
+	}
+}
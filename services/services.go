@@ -0,0 +1,43 @@
+package services
+
+import (
+	"ashborn.id/moniplan/repository"
+)
+
+// Services mengumpulkan service yang dipakai handler. Data diakses lewat
+// repository, sehingga service bisa dites dengan repository.NewMemory().
+type Services struct {
+	Users        *UserService
+	Categories   *CategoryService
+	Budgets      *BudgetService
+	Templates    *BudgetTemplateService
+	Transactions *TransactionService
+}
+
+// Categorizer menyarankan category untuk transaction baru berdasarkan
+// history user, mengembalikan 0 jika tidak ada saran yang cukup yakin
+type Categorizer interface {
+	AutoAssign(userID uint, remarks, transactionType string) (uint, error)
+}
+
+// New membuat semua service dari repository yang diberikan. categorizer
+// boleh nil, transaction tanpa category langsung masuk ke "uncategorized".
+func New(repos repository.Repositories, categorizer Categorizer) *Services {
+	return &Services{
+		Users:        &UserService{users: repos.Users},
+		Categories:   &CategoryService{users: repos.Users, categories: repos.Categories, budgets: repos.Budgets, uow: repos.UnitOfWork},
+		Budgets:      &BudgetService{budgets: repos.Budgets, categories: repos.Categories, uow: repos.UnitOfWork},
+		Templates:    &BudgetTemplateService{templates: repos.Templates, budgets: repos.Budgets, categories: repos.Categories, uow: repos.UnitOfWork},
+		Transactions: &TransactionService{users: repos.Users, transactions: repos.Transactions, categories: repos.Categories, rules: repos.Rules, payees: repos.Payees, categorizer: categorizer, uow: repos.UnitOfWork},
+	}
+}
+
+// ValidationError menandai input yang tidak valid, handler membalas dengan
+// status 400 dan pesan error ini
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
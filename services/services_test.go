@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/repository"
+)
+
+// stubCategorizer selalu menyarankan categoryID yang sama
+type stubCategorizer struct {
+	categoryID uint
+}
+
+func (c stubCategorizer) AutoAssign(userID uint, remarks, transactionType string) (uint, error) {
+	return c.categoryID, nil
+}
+
+// newTestServices membuat service di atas repository in-memory beserta satu user
+func newTestServices(t *testing.T, categorizer Categorizer) (*Services, models.User) {
+	t.Helper()
+
+	s := New(repository.NewMemory(), categorizer)
+	user, err := s.Users.Register(context.Background(), "Budi", "budi@example.com", "secret123")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return s, user
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/money"
+	"ashborn.id/moniplan/repository"
+)
+
+// TransactionChanges adalah perubahan pada Update. Field kosong (zero value
+// atau nil) berarti tidak diubah.
+type TransactionChanges struct {
+	CategoryID      uint
+	PayeeID         *uint
+	Amount          money.Amount
+	Currency        string
+	Type            string
+	Remarks         string
+	Tags            string
+	TransactionDate *time.Time
+}
+
+// TransactionService menangani transaction user
+type TransactionService struct {
+	users        repository.UserRepository
+	transactions repository.TransactionRepository
+	categories   repository.CategoryRepository
+	rules        repository.TransactionRuleRepository
+	payees       repository.PayeeRepository
+	categorizer  Categorizer
+	uow          repository.UnitOfWork
+}
+
+// List mengembalikan transaction sesuai filter. Year/Month adalah periode
+// budget, rentang tanggal dan format tanggalnya mengikuti pengaturan user.
+func (s *TransactionService) List(ctx context.Context, filter repository.TransactionFilter) ([]models.TransactionCategoryBudget, error) {
+	calendar, err := userCalendar(ctx, s.users, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter.Calendar = calendar
+	return s.transactions.List(ctx, filter)
+}
+
+// Get mengambil transaction milik user
+func (s *TransactionService) Get(ctx context.Context, userID, id uint) (models.Transaction, error) {
+	return s.transactions.FindByID(ctx, userID, id)
+}
+
+// Create melengkapi, memvalidasi lalu menyimpan transaction baru:
+//   - currency kosong diisi base currency user
+//   - auto-categorization rule aktif diterapkan, category pilihan user tetap dipakai
+//   - PayeeID harus milik user, jika kosong payee dicocokkan dari remarks
+//   - tanpa category, dipakai saran categorizer jika cukup yakin
+//
+// Transaction yang masih tanpa category dimasukkan ke category
+// "uncategorized" milik user, yang dibuat dalam unit of work yang sama
+// dengan transaction-nya.
+func (s *TransactionService) Create(ctx context.Context, transaction *models.Transaction) error {
+	user, err := s.users.FindByID(ctx, transaction.UserID)
+	if err != nil {
+		return err
+	}
+	transaction.Currency = models.NormalizeCurrency(transaction.Currency, models.NormalizeCurrency(user.BaseCurrency, models.DefaultCurrency))
+
+	rules, err := s.rules.ListActive(ctx, transaction.UserID)
+	if err != nil {
+		return err
+	}
+	for i := range rules {
+		rules[i].Compile()
+	}
+	models.ApplyRules(rules, transaction, true).Apply(transaction)
+
+	if transaction.PayeeID != nil {
+		if err := s.checkPayee(ctx, transaction.UserID, *transaction.PayeeID); err != nil {
+			return err
+		}
+	} else {
+		resolver, err := s.payeeResolver(ctx, transaction.UserID)
+		if err != nil {
+			return err
+		}
+		transaction.PayeeID = resolver.Resolve(transaction.Remarks)
+	}
+
+	// Saran categorizer hanya pelengkap, error-nya tidak menggagalkan create
+	if transaction.CategoryID == 0 && s.categorizer != nil {
+		if categoryID, err := s.categorizer.AutoAssign(transaction.UserID, transaction.Remarks, transaction.Type); err == nil {
+			transaction.CategoryID = categoryID
+		}
+	}
+
+	if err := validateTransaction(transaction); err != nil {
+		return err
+	}
+
+	if transaction.CategoryID != 0 {
+		if err := s.checkCategory(ctx, transaction.UserID, transaction.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	transaction.Tags = models.MergeTags(transaction.Tags)
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+
+	categoryID := transaction.CategoryID
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		// fn bisa dijalankan ulang saat deadlock, jadi mulai dari nilai awal
+		transaction.ID = 0
+		transaction.CategoryID = categoryID
+		if categoryID == 0 {
+			category, _, err := upsertCategory(ctx, repos.Categories, transaction.UserID, models.UncategorizedCategoryName)
+			if err != nil {
+				return err
+			}
+			transaction.CategoryID = category.ID
+		}
+		return repos.Transactions.Create(ctx, transaction)
+	})
+}
+
+// Update menerapkan changes ke transaction milik user dan menyimpannya
+func (s *TransactionService) Update(ctx context.Context, userID, id uint, changes TransactionChanges) (models.Transaction, error) {
+	transaction, err := s.transactions.FindByID(ctx, userID, id)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	if changes.CategoryID > 0 && changes.CategoryID != transaction.CategoryID {
+		if err := s.checkCategory(ctx, userID, changes.CategoryID); err != nil {
+			return models.Transaction{}, err
+		}
+		transaction.CategoryID = changes.CategoryID
+	}
+	if changes.PayeeID != nil {
+		if err := s.checkPayee(ctx, userID, *changes.PayeeID); err != nil {
+			return models.Transaction{}, err
+		}
+		transaction.PayeeID = changes.PayeeID
+	}
+	if changes.Amount != 0 {
+		transaction.Amount = changes.Amount
+	}
+	if changes.Currency != "" {
+		transaction.Currency = models.NormalizeCurrency(changes.Currency, "")
+	}
+	if changes.Type != "" {
+		transaction.Type = changes.Type
+	}
+	if changes.Remarks != "" {
+		transaction.Remarks = changes.Remarks
+	}
+	if changes.Tags != "" {
+		transaction.Tags = models.MergeTags(changes.Tags)
+	}
+	if changes.TransactionDate != nil {
+		transaction.TransactionDate = *changes.TransactionDate
+	}
+
+	if err := validateTransaction(&transaction); err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction.UpdatedAt = time.Now()
+	if err := s.transactions.Update(ctx, &transaction); err != nil {
+		return models.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+// Delete memindahkan transaction milik user ke trash
+func (s *TransactionService) Delete(ctx context.Context, userID, id uint) error {
+	return s.transactions.Delete(ctx, userID, id)
+}
+
+// checkCategory memastikan category ada dan milik user, agar tidak gagal
+// di foreign key transactions.category_id
+func (s *TransactionService) checkCategory(ctx context.Context, userID, categoryID uint) error {
+	_, err := s.categories.FindByID(ctx, userID, categoryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &ValidationError{Message: "category not found"}
+	}
+	return err
+}
+
+// checkPayee memastikan payee yang di-link memang milik user
+func (s *TransactionService) checkPayee(ctx context.Context, userID, payeeID uint) error {
+	_, err := s.payees.FindByID(ctx, userID, payeeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &ValidationError{Message: "payee not found"}
+	}
+	return err
+}
+
+// payeeResolver memuat payee dan alias user untuk mencocokkan remarks
+func (s *TransactionService) payeeResolver(ctx context.Context, userID uint) (*models.PayeeResolver, error) {
+	payees, err := s.payees.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.payees.ListAliases(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPayeeResolverFrom(payees, aliases), nil
+}
+
+func validateTransaction(transaction *models.Transaction) error {
+	if err := models.ValidateTransactionAmount(transaction.Type, transaction.Amount); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	if !models.IsValidCurrency(transaction.Currency) {
+		return &ValidationError{Message: "currency must be a 3-letter currency code"}
+	}
+	return nil
+}
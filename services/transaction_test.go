@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/repository"
+)
+
+func TestTransactionCreateAndList(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestServices(t, nil)
+
+	food, err := s.Categories.Create(ctx, user.ID, CategoryInput{Name: "food", Month: 5, Year: 2026, Amount: 100000})
+	if err != nil {
+		t.Fatalf("Create category: %v", err)
+	}
+
+	may := time.Date(2026, 5, 10, 5, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{UserID: user.ID, CategoryID: food.Category.ID, Amount: 25000, Currency: "idr", Type: models.TransactionTypeExpense, Remarks: "lunch", Tags: "Work, work", TransactionDate: may},
+		{UserID: user.ID, Amount: 5000, Type: models.TransactionTypeExpense, Remarks: "parking", TransactionDate: may.Add(time.Hour)},
+		{UserID: user.ID, CategoryID: food.Category.ID, Amount: 40000, Type: models.TransactionTypeExpense, Remarks: "dinner", TransactionDate: may.AddDate(0, 1, 0)},
+	}
+	for i := range transactions {
+		if err := s.Transactions.Create(ctx, &transactions[i]); err != nil {
+			t.Fatalf("Create transaction %d: %v", i, err)
+		}
+	}
+
+	lunch := transactions[0]
+	if lunch.Currency != "IDR" || lunch.Tags != "work" {
+		t.Errorf("Create lunch currency/tags = %q/%q, want IDR/work", lunch.Currency, lunch.Tags)
+	}
+
+	// Tanpa category dan currency, dipakai category "uncategorized" dan base
+	// currency user
+	parking := transactions[1]
+	if parking.Currency != user.BaseCurrency {
+		t.Errorf("Create parking currency = %q, want base currency %q", parking.Currency, user.BaseCurrency)
+	}
+	uncategorized, err := s.Categories.categories.FindByName(ctx, user.ID, models.UncategorizedCategoryName)
+	if err != nil {
+		t.Fatalf("FindByName uncategorized: %v", err)
+	}
+	if parking.CategoryID != uncategorized.ID {
+		t.Errorf("Create parking category = %d, want uncategorized %d", parking.CategoryID, uncategorized.ID)
+	}
+
+	list, err := s.Transactions.List(ctx, repository.TransactionFilter{UserID: user.ID, Year: 2026, Month: 5})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List returned %d transactions, want 2 in May", len(list))
+	}
+	if list[0].ID != parking.ID || list[0].CategoryName != models.UncategorizedCategoryName {
+		t.Errorf("List[0] = %+v, want parking in uncategorized first", list[0])
+	}
+	if list[1].ID != lunch.ID || list[1].CategoryName != "food" {
+		t.Errorf("List[1] = %+v, want lunch in food", list[1])
+	}
+	// Tanggal ditampilkan di zona waktu dan locale user (Asia/Jakarta, id-ID)
+	if want := "Minggu, 10 Mei 2026 12.00"; list[1].TransactionDate != want {
+		t.Errorf("List[1].TransactionDate = %q, want %q", list[1].TransactionDate, want)
+	}
+
+	filtered, err := s.Transactions.List(ctx, repository.TransactionFilter{UserID: user.ID, Year: 2026, Month: 5, CategoryID: food.Category.ID})
+	if err != nil {
+		t.Fatalf("List by category: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != lunch.ID {
+		t.Errorf("List by category = %+v, want only lunch", filtered)
+	}
+}
+
+func TestTransactionCreateUsesCategorizer(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestServices(t, nil)
+
+	food, err := s.Categories.Create(ctx, user.ID, CategoryInput{Name: "food", Month: 5, Year: 2026, Amount: 100000})
+	if err != nil {
+		t.Fatalf("Create category: %v", err)
+	}
+	s.Transactions.categorizer = stubCategorizer{categoryID: food.Category.ID}
+
+	transaction := models.Transaction{UserID: user.ID, Amount: 25000, Type: models.TransactionTypeExpense, Remarks: "lunch", TransactionDate: time.Now()}
+	if err := s.Transactions.Create(ctx, &transaction); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if transaction.CategoryID != food.Category.ID {
+		t.Errorf("Create category = %d, want suggested %d", transaction.CategoryID, food.Category.ID)
+	}
+}
+
+func TestTransactionCreateValidation(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestServices(t, nil)
+	unknown := uint(999)
+
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+	}{
+		{"zero amount", models.Transaction{Amount: 0, Type: models.TransactionTypeExpense}},
+		{"invalid currency", models.Transaction{Amount: 1000, Currency: "RUPIAH", Type: models.TransactionTypeExpense}},
+		{"unknown category", models.Transaction{CategoryID: unknown, Amount: 1000, Type: models.TransactionTypeExpense}},
+		{"unknown payee", models.Transaction{PayeeID: &unknown, Amount: 1000, Type: models.TransactionTypeExpense}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.transaction.UserID = user.ID
+			tt.transaction.TransactionDate = time.Now()
+
+			err := s.Transactions.Create(ctx, &tt.transaction)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("Create error = %v, want ValidationError", err)
+			}
+		})
+	}
+
+	// Transaction yang gagal validasi tidak membuat category "uncategorized"
+	if _, err := s.Categories.categories.FindByName(ctx, user.ID, models.UncategorizedCategoryName); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("FindByName uncategorized error = %v, want ErrNotFound", err)
+	}
+}
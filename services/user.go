@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/repository"
+)
+
+var (
+	// ErrEmailTaken dikembalikan Register jika email sudah terdaftar
+	ErrEmailTaken = errors.New("an account with this email already exists")
+
+	// ErrInvalidCredentials dikembalikan Authenticate jika email atau
+	// password salah
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// UserService menangani register, login dan profil user
+type UserService struct {
+	users repository.UserRepository
+}
+
+// NormalizeEmail mengubah email ke lowercase tanpa spasi
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Register membuat user baru, password di-hash oleh hook model
+func (s *UserService) Register(ctx context.Context, name, email, password string) (models.User, error) {
+	email = NormalizeEmail(email)
+
+	if _, err := s.users.FindByEmail(ctx, email); err == nil {
+		return models.User{}, ErrEmailTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return models.User{}, err
+	}
+
+	user := models.User{
+		Name:     strings.TrimSpace(name),
+		Email:    email,
+		Password: password,
+	}
+	if err := s.users.Create(ctx, &user); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// Authenticate mencari user berdasarkan email dan mencocokkan password
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	user, err := s.users.FindByEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.User{}, ErrInvalidCredentials
+		}
+		return models.User{}, err
+	}
+
+	if err := user.CheckPassword(password); err != nil {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Get mengambil user berdasarkan ID
+func (s *UserService) Get(ctx context.Context, id uint) (models.User, error) {
+	return s.users.FindByID(ctx, id)
+}
+
+// SetPeriodStartDay mengganti tanggal awal periode budget user, misalnya 25
+// jika gajian setiap tanggal 25
+func (s *UserService) SetPeriodStartDay(ctx context.Context, id uint, day int) (models.User, error) {
+	if day < 1 || day > models.MaxPeriodStartDay {
+		return models.User{}, &ValidationError{Message: "period_start_day must be between 1 and 28"}
+	}
+
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.PeriodStartDay = day
+	if err := s.users.UpdatePreferences(ctx, &user); err != nil {
+		return models.User{}, err
+	}
+	return s.users.FindByID(ctx, id)
+}
+
+// SetTimeZone mengganti zona waktu (nama IANA, misalnya Asia/Makassar) dan
+// locale tampilan tanggal user. Locale kosong berarti tidak diubah.
+func (s *UserService) SetTimeZone(ctx context.Context, id uint, timeZone, locale string) (models.User, error) {
+	location, err := models.LoadTimeZone(timeZone)
+	if err != nil {
+		return models.User{}, &ValidationError{Message: err.Error()}
+	}
+
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.TimeZone = location.String()
+
+	if locale != "" {
+		user.Locale = models.NormalizeLocale(locale)
+		if user.Locale == "" {
+			return models.User{}, &ValidationError{Message: "locale is not supported, use one of id-ID, en-US, en-GB, en-AU, en-SG or ms-MY"}
+		}
+	}
+
+	if err := s.users.UpdatePreferences(ctx, &user); err != nil {
+		return models.User{}, err
+	}
+	return s.users.FindByID(ctx, id)
+}
+
+// Calendar mengembalikan pengaturan tanggal user (zona waktu, locale dan
+// awal periode budget)
+func (s *UserService) Calendar(ctx context.Context, id uint) (models.UserCalendar, error) {
+	return userCalendar(ctx, s.users, id)
+}
+
+// Period mengembalikan rentang periode budget year/month milik user
+func (s *UserService) Period(ctx context.Context, id, year, month uint) (models.BudgetPeriod, error) {
+	if err := validatePeriod(year, month); err != nil {
+		return models.BudgetPeriod{}, err
+	}
+	calendar, err := userCalendar(ctx, s.users, id)
+	if err != nil {
+		return models.BudgetPeriod{}, err
+	}
+	return calendar.PeriodRange(year, month), nil
+}
+
+// CurrentPeriod mengembalikan periode budget user yang memuat t
+func (s *UserService) CurrentPeriod(ctx context.Context, id uint, t time.Time) (models.BudgetPeriod, error) {
+	calendar, err := userCalendar(ctx, s.users, id)
+	if err != nil {
+		return models.BudgetPeriod{}, err
+	}
+	return calendar.PeriodOf(t), nil
+}
+
+// userCalendar mengambil pengaturan tanggal user
+func userCalendar(ctx context.Context, users repository.UserRepository, id uint) (models.UserCalendar, error) {
+	user, err := users.FindByID(ctx, id)
+	if err != nil {
+		return models.UserCalendar{}, err
+	}
+	return user.Calendar(), nil
+}
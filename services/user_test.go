@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/repository"
+)
+
+func TestUserRegister(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), nil)
+
+	user, err := s.Users.Register(ctx, " Budi ", " Budi@Example.COM ", "secret123")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if user.ID == 0 || user.Name != "Budi" || user.Email != "budi@example.com" {
+		t.Errorf("Register = %+v, want trimmed name and normalized email", user)
+	}
+	if user.Password == "secret123" {
+		t.Error("Register stored the password in plain text")
+	}
+	if user.BaseCurrency != models.DefaultCurrency || user.TimeZone != models.DefaultTimeZone {
+		t.Errorf("Register defaults = %s/%s, want %s/%s", user.BaseCurrency, user.TimeZone, models.DefaultCurrency, models.DefaultTimeZone)
+	}
+
+	if _, err := s.Users.Register(ctx, "Other", "BUDI@example.com", "secret123"); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Register duplicate email error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func TestUserAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestServices(t, nil)
+
+	got, err := s.Users.Authenticate(ctx, "BUDI@example.com", "secret123")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("Authenticate returned user %d, want %d", got.ID, user.ID)
+	}
+
+	if _, err := s.Users.Authenticate(ctx, user.Email, "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := s.Users.Authenticate(ctx, "nobody@example.com", "secret123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate unknown email error = %v, want ErrInvalidCredentials", err)
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound dikembalikan jika object dengan key tersebut tidak ada
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidKey dikembalikan jika key kosong atau keluar dari root storage
+var ErrInvalidKey = errors.New("invalid object key")
+
+// Storage menyimpan file (attachment, thumbnail, ...) berdasarkan key
+// berbentuk path relatif, misalnya "attachments/1/abc.pdf"
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner adalah Storage yang bisa membuat URL download sementara sendiri
+// (misalnya presigned URL S3), sehingga file tidak perlu di-proxy oleh server
+type Presigner interface {
+	PresignGet(key string, expires time.Duration) (string, error)
+}
+
+// FromEnv membuat Storage berdasarkan env STORAGE_DRIVER (local, s3).
+// Default ke LocalStorage di STORAGE_LOCAL_PATH (./uploads).
+func FromEnv() Storage {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "", "local":
+		return NewLocalStorageFromEnv()
+	case "s3":
+		return NewS3StorageFromEnv()
+	default:
+		log.Printf("Warning: unknown STORAGE_DRIVER %q, falling back to local", os.Getenv("STORAGE_DRIVER"))
+		return NewLocalStorageFromEnv()
+	}
+}
+
+// cleanKey memvalidasi key agar tidak berisi path absolut atau ".."
+func cleanKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,364 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ashborn.id/moniplan/database"
+	"ashborn.id/moniplan/events"
+	"ashborn.id/moniplan/models"
+	"ashborn.id/moniplan/safehttp"
+)
+
+// Event yang bisa di-subscribe oleh webhook
+const (
+	EventTransactionCreated  = events.TransactionCreated
+	EventTransactionUpdated  = events.TransactionUpdated
+	EventTransactionDeleted  = events.TransactionDeleted
+	EventTransactionRestored = events.TransactionRestored
+	EventCategoryCreated     = events.CategoryCreated
+	EventCategoryUpdated     = events.CategoryUpdated
+	EventCategoryDeleted     = events.CategoryDeleted
+	EventCategoryRestored    = events.CategoryRestored
+	EventBudgetExceeded      = events.BudgetExceeded
+)
+
+// Events adalah daftar semua event yang valid
+var Events = []string{
+	EventTransactionCreated,
+	EventTransactionUpdated,
+	EventTransactionDeleted,
+	EventTransactionRestored,
+	EventCategoryCreated,
+	EventCategoryUpdated,
+	EventCategoryDeleted,
+	EventCategoryRestored,
+	EventBudgetExceeded,
+}
+
+const (
+	// MaxAttempts adalah jumlah percobaan pengiriman sebelum delivery dianggap failed
+	MaxAttempts = 6
+
+	// retryBaseDelay adalah jeda retry pertama, dikali dua setiap percobaan
+	retryBaseDelay = 30 * time.Second
+
+	// attemptLease adalah jeda sebelum delivery yang sedang dikirim boleh
+	// diambil lagi oleh RetryPending, lebih lama dari timeout client
+	attemptLease = time.Minute
+
+	queueSize = 1000
+	workers   = 4
+)
+
+// Header yang dikirim bersama payload
+const (
+	HeaderEvent     = "X-Moniplan-Event"
+	HeaderDelivery  = "X-Moniplan-Delivery"
+	HeaderTimestamp = "X-Moniplan-Timestamp"
+	HeaderSignature = "X-Moniplan-Signature"
+)
+
+type dispatchJob struct {
+	userID uint
+	event  string
+	data   interface{}
+	at     time.Time
+}
+
+var (
+	queue  = make(chan dispatchJob, queueSize)
+	retry  = make(chan uint, queueSize)
+	client = safehttp.NewClient(10 * time.Second)
+)
+
+// IsValidEvent mengecek apakah nama event dikenal
+func IsValidEvent(event string) bool {
+	if event == "*" {
+		return true
+	}
+	for _, known := range Events {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateSecret membuat secret acak untuk signing payload
+func GenerateSecret() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}
+
+// Sign menghitung HMAC-SHA256 dari "<timestamp>.<payload>" dengan secret
+// webhook. Receiver memverifikasi header X-Moniplan-Signature dengan cara yang sama.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Listen meneruskan setiap event dari bus ke Dispatch
+func Listen(bus *events.Bus) {
+	bus.AddListener(func(event events.Event) {
+		Dispatch(event.UserID, event.Type, event.Data)
+	})
+}
+
+// Dispatch mengantrikan event untuk dikirim ke semua webhook user yang
+// berlangganan. Tidak pernah blocking agar aman dipanggil dari handler.
+func Dispatch(userID uint, event string, data interface{}) {
+	select {
+	case queue <- dispatchJob{userID: userID, event: event, data: data, at: time.Now()}:
+	default:
+		log.Printf("Webhook queue is full, dropping %s for user %d", event, userID)
+	}
+}
+
+// Redeliver mengantrikan ulang delivery yang sudah ada untuk dikirim segera
+func Redeliver(delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = &now
+	if err := database.DB.Save(delivery).Error; err != nil {
+		return err
+	}
+
+	select {
+	case retry <- delivery.ID:
+	default:
+		// Queue penuh, next_attempt_at sudah diisi jadi retry job berikutnya
+		// yang akan mengirimnya
+	}
+	return nil
+}
+
+// Start menjalankan worker pengiriman webhook sampai ctx di-cancel
+func Start(ctx context.Context) {
+	for i := 0; i < workers; i++ {
+		go worker(ctx)
+	}
+}
+
+func worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-queue:
+			fanOut(ctx, job)
+		case deliveryID := <-retry:
+			var delivery models.WebhookDelivery
+			if err := database.DB.First(&delivery, deliveryID).Error; err == nil {
+				attempt(ctx, &delivery)
+			}
+		}
+	}
+}
+
+// fanOut membuat delivery untuk setiap webhook yang subscribe ke event.
+// Delivery disimpan sekali lengkap dengan payload dan next_attempt_at
+// sekarang, jadi jika server berhenti sebelum percobaan pertama selesai,
+// RetryPending tetap akan mengirimnya.
+func fanOut(ctx context.Context, job dispatchJob) {
+	var hooks []models.Webhook
+	if err := database.DB.Where("user_id = ? AND active = ?", job.userID, true).Find(&hooks).Error; err != nil {
+		log.Printf("Failed to load webhooks for user %d: %v", job.userID, err)
+		return
+	}
+
+	var payload []byte
+	for _, hook := range hooks {
+		if !hook.Subscribes(job.event) {
+			continue
+		}
+
+		// Payload sama untuk semua webhook, id-nya adalah id event (id
+		// delivery ada di header X-Moniplan-Delivery)
+		if payload == nil {
+			eventID, err := newEventID()
+			if err != nil {
+				log.Printf("Failed to generate webhook event id: %v", err)
+				return
+			}
+			payload, err = json.Marshal(map[string]interface{}{
+				"id":         eventID,
+				"event":      job.event,
+				"created_at": job.at,
+				"data":       job.data,
+			})
+			if err != nil {
+				log.Printf("Failed to encode webhook payload: %v", err)
+				return
+			}
+		}
+
+		now := time.Now()
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			UserID:        job.userID,
+			Event:         job.event,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := database.DB.Create(&delivery).Error; err != nil {
+			log.Printf("Failed to create webhook delivery: %v", err)
+			continue
+		}
+
+		attempt(ctx, &delivery)
+	}
+}
+
+// newEventID membuat id acak untuk payload event
+func newEventID() (string, error) {
+	bytes := make([]byte, 12)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(bytes), nil
+}
+
+// attempt mengirim satu delivery dan menjadwalkan retry dengan exponential
+// backoff jika gagal
+func attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	var hook models.Webhook
+	if err := database.DB.First(&hook, delivery.WebhookID).Error; err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "webhook no longer exists"
+		delivery.NextAttemptAt = nil
+		if err := database.DB.Save(delivery).Error; err != nil {
+			log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	claimed, err := claim(delivery)
+	if err != nil {
+		log.Printf("Failed to claim webhook delivery %d: %v", delivery.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	statusCode, err := send(ctx, &hook, delivery)
+	delivery.ResponseCode = statusCode
+	now := time.Now()
+
+	if err == nil {
+		delivery.Status = models.WebhookDeliverySuccess
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.LastError = truncate(err.Error(), 500)
+		if delivery.Attempts >= MaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			delivery.Status = models.WebhookDeliveryPending
+			next := now.Add(retryBaseDelay << (delivery.Attempts - 1))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := database.DB.Save(delivery).Error; err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// claim menaikkan attempts dan menunda next_attempt_at selama attemptLease
+// sebelum delivery dikirim. Update bersyarat pada attempts memastikan worker
+// dan RetryPending tidak mengirim delivery yang sama dua kali; jika server
+// berhenti di tengah pengiriman, delivery dicoba lagi setelah lease habis.
+func claim(delivery *models.WebhookDelivery) (bool, error) {
+	lease := time.Now().UTC().Add(attemptLease)
+	result := database.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.WebhookDeliveryPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"next_attempt_at": lease,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	delivery.Attempts++
+	delivery.NextAttemptAt = &lease
+	return true, nil
+}
+
+func send(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Moniplan-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// RetryPending mengirim ulang delivery pending yang jadwal retry-nya sudah
+// lewat. Dipanggil periodik oleh background job.
+func RetryPending(ctx context.Context, now time.Time) error {
+	var deliveries []models.WebhookDelivery
+	if err := database.DB.
+		Where("status = ? AND next_attempt_at IS NOT NULL AND next_attempt_at <= ?", models.WebhookDeliveryPending, now.UTC()).
+		Order("next_attempt_at ASC").
+		Limit(100).
+		Find(&deliveries).Error; err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		attempt(ctx, &deliveries[i])
+	}
+	return nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}